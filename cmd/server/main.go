@@ -1,16 +1,24 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
 	"time"
+	_ "time/tzdata" // quiet hours need zone data even on slim images
 
 	"github.com/alexedwards/scs/postgresstore"
 	"github.com/alexedwards/scs/v2"
 	"github.com/joho/godotenv"
 	_ "github.com/lib/pq"
+	"github.com/navyaalva/sbf-os/internal/ai"
+	"github.com/navyaalva/sbf-os/internal/jobs"
+	"github.com/navyaalva/sbf-os/internal/notify"
 	"github.com/navyaalva/sbf-os/internal/server"
 )
 
@@ -31,11 +39,79 @@ func main() {
 	sessionManager.Cookie.HttpOnly = true
 	// ----------------------------------
 
+	// AI_PROVIDER picks the model backend; a bare GEMINI_API_KEY keeps
+	// working as before.
+	aiCfg := ai.Config{
+		Provider:   os.Getenv("AI_PROVIDER"),
+		APIKey:     os.Getenv("AI_API_KEY"),
+		Model:      os.Getenv("AI_MODEL"),
+		BaseURL:    os.Getenv("AI_BASE_URL"),
+		FixtureDir: os.Getenv("AI_FIXTURE_DIR"),
+		RecordDir:  os.Getenv("AI_RECORD_DIR"),
+		Timeout:    time.Duration(envInt("AI_TIMEOUT_SECONDS", 30)) * time.Second,
+	}
+	if key := os.Getenv("GEMINI_API_KEY"); key != "" && aiCfg.Provider == "" {
+		aiCfg.Provider, aiCfg.APIKey = ai.ProviderGemini, key
+	}
+	provider, err := ai.New(aiCfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if provider != nil {
+		log.Printf("🤖 AI provider: %s", provider.Name())
+	}
+
+	cfg := server.Config{
+		TrashRetentionDays: envInt("TRASH_RETENTION_DAYS", 30),
+		Notify: notify.Config{
+			SMTP: notify.SMTPConfig{
+				Host:     os.Getenv("SMTP_HOST"),
+				Port:     envInt("SMTP_PORT", 587),
+				Username: os.Getenv("SMTP_USERNAME"),
+				Password: os.Getenv("SMTP_PASSWORD"),
+				From:     os.Getenv("SMTP_FROM"),
+			},
+			WebhookSecret: os.Getenv("WEBHOOK_SECRET"),
+			// Lets webhooks reach localhost, e.g. cmd/webhook-sink. Never in production.
+			WebhookAllowPrivate: os.Getenv("WEBHOOK_ALLOW_PRIVATE") == "true",
+			MaxAttempts:         envInt("NOTIFY_MAX_ATTEMPTS", 5),
+			BaseURL:             envString("APP_URL", "http://localhost:8080"),
+		},
+		AI: provider,
+	}
+
 	// Pass sessionManager to the server
-	srv := server.NewServer(dbConn, sessionManager)
+	srv := server.NewServer(dbConn, sessionManager, cfg)
+
+	// --- Background Jobs ---
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go jobs.Every(ctx, "trash-purge", time.Hour, jobs.PurgeTrash(srv.Q, cfg.TrashRetentionDays))
+	go jobs.Every(ctx, "risk-snapshot", 6*time.Hour, jobs.SnapshotRisk(srv.Q))
+	go jobs.Every(ctx, "alert-scan", time.Hour, jobs.ScanAlerts(srv.Q))
+	go jobs.Every(ctx, "follow-ups", time.Hour, jobs.QueueFollowUps(srv.Q, srv.Notifier))
+	go jobs.Every(ctx, "digest", 15*time.Minute, jobs.QueueDigests(srv.Q, srv.Notifier))
+	go jobs.Every(ctx, "notify-deliver", time.Minute, srv.Notifier.Deliver)
+	// -----------------------
 
 	log.Println("🚀 SBF-OS running on :8080")
 	if err := http.ListenAndServe(":8080", sessionManager.LoadAndSave(srv.Router)); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// envInt reads a positive integer setting, falling back to def.
+func envInt(key string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(key)); err == nil && n > 0 {
+		return n
+	}
+	return def
+}
+
+// envString reads a setting, falling back to def when unset.
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,47 @@
+// Command webhook-sink is a local receiver for trying out notification
+// webhooks: it checks each request's signature with WEBHOOK_SECRET and logs
+// the payload. Point a person's webhook URL at http://localhost:9090/.
+package main
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+
+	"github.com/navyaalva/sbf-os/internal/notify"
+)
+
+func main() {
+	_ = godotenv.Load()
+	secret := []byte(os.Getenv("WEBHOOK_SECRET"))
+	if len(secret) == 0 {
+		log.Fatal("WEBHOOK_SECRET is not set")
+	}
+
+	addr := os.Getenv("SINK_ADDR")
+	if addr == "" {
+		addr = ":9090"
+	}
+
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, "read failed", http.StatusBadRequest)
+			return
+		}
+		if err := notify.Verify(secret, r, body, 5*time.Minute); err != nil {
+			log.Printf("❌ rejected %s: %v", r.Header.Get(notify.HeaderDelivery), err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		log.Printf("✅ %s %s", r.Header.Get(notify.HeaderDelivery), body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("🪝 webhook sink listening on %s", addr)
+	log.Fatal(http.ListenAndServe(addr, nil))
+}
@@ -3,15 +3,12 @@ module github.com/navyaalva/sbf-os
 go 1.25.0
 
 require (
+	github.com/alexedwards/scs/postgresstore v0.0.0-20251002162104-209de6e426de
+	github.com/alexedwards/scs/v2 v2.9.0
 	github.com/go-chi/chi/v5 v5.2.3
 	github.com/google/uuid v1.6.0
 	github.com/joho/godotenv v1.5.1
 	github.com/lib/pq v1.10.9
-)
-
-require (
-	github.com/alexedwards/scs/postgresstore v0.0.0-20251002162104-209de6e426de // indirect
-	github.com/alexedwards/scs/v2 v2.9.0 // indirect
-	github.com/sqlc-dev/pqtype v0.3.0 // indirect
-	golang.org/x/crypto v0.46.0 // indirect
+	github.com/sqlc-dev/pqtype v0.3.0
+	golang.org/x/crypto v0.46.0
 )
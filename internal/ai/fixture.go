@@ -0,0 +1,106 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Fixture is one recorded exchange. Files are named after FixtureKey(prompt).
+type Fixture struct {
+	Prompt   string `json:"prompt"`
+	Response string `json:"response"`
+}
+
+// FixtureKey is the file stem for a prompt: a short hash of it with
+// surrounding whitespace ignored, so reindented prompts still match.
+func FixtureKey(prompt string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(prompt)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// FixtureProvider replays recorded replies and never touches the network.
+// An unrecorded prompt is an error, so tests fail loudly instead of drifting.
+type FixtureProvider struct {
+	replies map[string]string
+}
+
+// NewFixtureProvider serves the given prompt → reply pairs.
+func NewFixtureProvider(replies map[string]string) *FixtureProvider {
+	fp := &FixtureProvider{replies: map[string]string{}}
+	for prompt, reply := range replies {
+		fp.replies[FixtureKey(prompt)] = reply
+	}
+	return fp
+}
+
+// LoadFixtures reads every *.json fixture in dir.
+func LoadFixtures(dir string) (*FixtureProvider, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("fixture provider needs a directory")
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	fp := &FixtureProvider{replies: map[string]string{}}
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var f Fixture
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		fp.replies[FixtureKey(f.Prompt)] = f.Response
+	}
+	return fp, nil
+}
+
+func (f *FixtureProvider) Name() string { return ProviderFixture }
+
+func (f *FixtureProvider) Complete(_ context.Context, prompt string) (string, error) {
+	reply, ok := f.replies[FixtureKey(prompt)]
+	if !ok {
+		return "", fmt.Errorf("fixture: no recording for prompt %s", FixtureKey(prompt))
+	}
+	return reply, nil
+}
+
+// Recorder wraps a live provider and writes each successful exchange to Dir
+// as a fixture, ready for FixtureProvider.
+type Recorder struct {
+	Inner LLMProvider
+	Dir   string
+	mu    sync.Mutex
+}
+
+func (r *Recorder) Name() string { return r.Inner.Name() + "+record" }
+
+func (r *Recorder) Complete(ctx context.Context, prompt string) (string, error) {
+	reply, err := r.Inner.Complete(ctx, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := json.MarshalIndent(Fixture{Prompt: prompt, Response: reply}, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(r.Dir, FixtureKey(prompt)+".json"), b, 0o644); err != nil {
+		return "", err
+	}
+	return reply, nil
+}
@@ -0,0 +1,70 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// stubProvider answers every prompt the same way, or fails.
+type stubProvider struct {
+	reply string
+	err   error
+}
+
+func (s stubProvider) Name() string { return "stub" }
+
+func (s stubProvider) Complete(context.Context, string) (string, error) {
+	return s.reply, s.err
+}
+
+func TestRecorderRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	rec := &Recorder{Inner: stubProvider{reply: `[{"title": "Book venue"}]`}, Dir: dir}
+	const prompt = "Break down: Book venue\n"
+
+	if reply, err := rec.Complete(context.Background(), prompt); err != nil || reply != `[{"title": "Book venue"}]` {
+		t.Fatalf("Complete() = %q, %v", reply, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FixtureKey(prompt)+".json")); err != nil {
+		t.Fatalf("fixture not written: %v", err)
+	}
+
+	// A failed call records nothing.
+	failing := &Recorder{Inner: stubProvider{err: errors.New("quota exceeded")}, Dir: dir}
+	if _, err := failing.Complete(context.Background(), "Another prompt"); err == nil {
+		t.Fatal("want the inner error")
+	}
+	if paths, _ := filepath.Glob(filepath.Join(dir, "*.json")); len(paths) != 1 {
+		t.Errorf("fixtures = %v, want only the successful exchange", paths)
+	}
+
+	fp, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Surrounding whitespace doesn't change the key.
+	reply, err := fp.Complete(context.Background(), "  Break down: Book venue")
+	if err != nil || reply != `[{"title": "Book venue"}]` {
+		t.Errorf("replayed %q, %v", reply, err)
+	}
+	if _, err := fp.Complete(context.Background(), "Break down: Send invites"); err == nil || !strings.Contains(err.Error(), "no recording") {
+		t.Errorf("unrecorded prompt error = %v", err)
+	}
+}
+
+func TestLoadFixturesErrors(t *testing.T) {
+	if _, err := LoadFixtures(""); err == nil {
+		t.Error("empty dir: want an error")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"prompt":`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFixtures(dir); err == nil || !strings.Contains(err.Error(), "broken.json") {
+		t.Errorf("broken fixture error = %v, want it to name the file", err)
+	}
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const geminiDefaultModel = "gemini-2.5-flash"
+
+// Gemini calls Google's generateContent endpoint.
+type Gemini struct {
+	apiKey  string
+	model   string
+	client  *http.Client
+	baseURL string
+}
+
+func NewGemini(apiKey, model string, client *http.Client) *Gemini {
+	if model == "" {
+		model = geminiDefaultModel
+	}
+	return &Gemini{
+		apiKey:  apiKey,
+		model:   model,
+		client:  client,
+		baseURL: "https://generativelanguage.googleapis.com/v1beta",
+	}
+}
+
+func (g *Gemini) Name() string { return ProviderGemini + ":" + g.model }
+
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	Contents []geminiContent `json:"contents"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (g *Gemini) Complete(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(geminiRequest{
+		Contents: []geminiContent{{Parts: []geminiPart{{Text: prompt}}}},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	url := g.baseURL + "/models/" + g.model + ":generateContent"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Header rather than ?key= so the key never ends up in logged URLs.
+	req.Header.Set("x-goog-api-key", g.apiKey)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	var out geminiResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", fmt.Errorf("gemini: %s: bad JSON: %w", resp.Status, err)
+	}
+	if out.Error != nil {
+		return "", fmt.Errorf("gemini: %s", out.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("gemini: %s", resp.Status)
+	}
+	if len(out.Candidates) == 0 || len(out.Candidates[0].Content.Parts) == 0 {
+		return "", errors.New("gemini: no candidates returned")
+	}
+	return out.Candidates[0].Content.Parts[0].Text, nil
+}
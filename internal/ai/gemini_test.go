@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGeminiRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/models/gemini-test:generateContent" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if r.URL.RawQuery != "" {
+			t.Errorf("query = %q, want the key kept out of the URL", r.URL.RawQuery)
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "secret" {
+			t.Errorf("x-goog-api-key = %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		var req geminiRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if len(req.Contents) != 1 || len(req.Contents[0].Parts) != 1 || req.Contents[0].Parts[0].Text != "Plan the fair" {
+			t.Errorf("body = %+v", req)
+		}
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "Book a venue."}]}}]}`))
+	}))
+	defer srv.Close()
+
+	g := NewGemini("secret", "gemini-test", srv.Client())
+	g.baseURL = srv.URL
+	reply, err := g.Complete(context.Background(), "Plan the fair")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply != "Book a venue." {
+		t.Errorf("reply = %q", reply)
+	}
+	if g.Name() != "gemini:gemini-test" {
+		t.Errorf("Name() = %q", g.Name())
+	}
+}
+
+func TestGeminiErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "error message", status: http.StatusBadRequest, body: `{"error": {"message": "API key not valid"}}`, wantErr: "gemini: API key not valid"},
+		{name: "non-200 without a message", status: http.StatusServiceUnavailable, body: `{}`, wantErr: "gemini: 503 Service Unavailable"},
+		{name: "not JSON", status: http.StatusBadGateway, body: `<html>bad gateway</html>`, wantErr: "502 Bad Gateway: bad JSON"},
+		{name: "no candidates", status: http.StatusOK, body: `{"candidates": []}`, wantErr: "no candidates"},
+		{name: "candidate without parts", status: http.StatusOK, body: `{"candidates": [{"content": {"parts": []}}]}`, wantErr: "no candidates"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			g := NewGemini("secret", "", srv.Client())
+			g.baseURL = srv.URL
+			_, err := g.Complete(context.Background(), "Plan the fair")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Complete() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// OpenAI speaks the /chat/completions API, which OpenAI and most local
+// servers (llama.cpp, Ollama, vLLM, LM Studio) implement.
+type OpenAI struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+}
+
+// NewOpenAI targets baseURL (the part before /chat/completions). apiKey may be
+// empty for local servers that don't check it.
+func NewOpenAI(baseURL, apiKey, model string, client *http.Client) *OpenAI {
+	return &OpenAI{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		client:  client,
+	}
+}
+
+func (o *OpenAI) Name() string { return ProviderOpenAI + ":" + o.model }
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model       string          `json:"model"`
+	Messages    []openAIMessage `json:"messages"`
+	Temperature float64         `json:"temperature"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (o *OpenAI) Complete(ctx context.Context, prompt string) (string, error) {
+	body, err := json.Marshal(openAIRequest{
+		Model:    o.model,
+		Messages: []openAIMessage{{Role: "user", Content: prompt}},
+		// Planning output should be repeatable, not creative.
+		Temperature: 0,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+
+	var out openAIResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", fmt.Errorf("openai: %s: bad JSON: %w", resp.Status, err)
+	}
+	if out.Error != nil {
+		return "", fmt.Errorf("openai: %s", out.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("openai: %s", resp.Status)
+	}
+	if len(out.Choices) == 0 {
+		return "", errors.New("openai: no choices returned")
+	}
+	return out.Choices[0].Message.Content, nil
+}
@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAIRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		apiKey   string
+		wantAuth string
+	}{
+		{name: "hosted", apiKey: "sk-test", wantAuth: "Bearer sk-test"},
+		{name: "local server without a key", apiKey: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+					t.Errorf("request = %s %s", r.Method, r.URL.Path)
+				}
+				if got := r.Header.Get("Authorization"); got != tt.wantAuth {
+					t.Errorf("Authorization = %q, want %q", got, tt.wantAuth)
+				}
+				// Decode loosely so a missing temperature isn't read as 0.
+				var req map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Fatal(err)
+				}
+				if req["model"] != "llama-test" {
+					t.Errorf("model = %v", req["model"])
+				}
+				if temp, ok := req["temperature"]; !ok || temp != 0.0 {
+					t.Errorf("temperature = %v (sent: %v), want 0", temp, ok)
+				}
+				msgs, _ := req["messages"].([]interface{})
+				if len(msgs) != 1 {
+					t.Fatalf("messages = %v", req["messages"])
+				}
+				if msg := msgs[0].(map[string]interface{}); msg["role"] != "user" || msg["content"] != "Plan the fair" {
+					t.Errorf("message = %v", msg)
+				}
+				w.Write([]byte(`{"choices": [{"message": {"role": "assistant", "content": "Book a venue."}}]}`))
+			}))
+			defer srv.Close()
+
+			o := NewOpenAI(srv.URL+"/v1/", tt.apiKey, "llama-test", srv.Client())
+			reply, err := o.Complete(context.Background(), "Plan the fair")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reply != "Book a venue." {
+				t.Errorf("reply = %q", reply)
+			}
+		})
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{name: "error message", status: http.StatusUnauthorized, body: `{"error": {"message": "Incorrect API key"}}`, wantErr: "openai: Incorrect API key"},
+		{name: "non-200 without a message", status: http.StatusTooManyRequests, body: `{}`, wantErr: "openai: 429 Too Many Requests"},
+		{name: "not JSON", status: http.StatusInternalServerError, body: `upstream crashed`, wantErr: "500 Internal Server Error: bad JSON"},
+		{name: "no choices", status: http.StatusOK, body: `{"choices": []}`, wantErr: "no choices"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			_, err := NewOpenAI(srv.URL, "sk-test", "llama-test", srv.Client()).Complete(context.Background(), "Plan the fair")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Complete() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
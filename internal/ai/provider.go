@@ -0,0 +1,80 @@
+// Package ai hides which language model backs the app's AI features behind
+// LLMProvider. Providers are picked by configuration at startup and handed
+// to the server; nothing else reads API keys or builds model URLs.
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// LLMProvider turns a prompt into the model's raw text reply. Providers don't
+// interpret the reply; callers parse and validate it.
+type LLMProvider interface {
+	Name() string
+	Complete(ctx context.Context, prompt string) (string, error)
+}
+
+// Provider names accepted in Config.Provider (AI_PROVIDER).
+const (
+	ProviderNone    = "none"
+	ProviderGemini  = "gemini"
+	ProviderOpenAI  = "openai"
+	ProviderFixture = "fixture"
+)
+
+// Config selects and configures a provider.
+type Config struct {
+	Provider string
+	APIKey   string
+	Model    string
+	// BaseURL is the OpenAI-compatible endpoint root, e.g.
+	// http://localhost:11434/v1 for Ollama or http://localhost:8080/v1 for llama.cpp.
+	BaseURL string
+	// FixtureDir holds recorded prompt/response pairs for the fixture provider.
+	FixtureDir string
+	// RecordDir, when set, saves every live reply there as a fixture.
+	RecordDir string
+	Timeout   time.Duration
+}
+
+// New builds the configured provider. ProviderNone (or an empty name) returns
+// nil, which callers treat as "AI off".
+func New(cfg Config) (LLMProvider, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 30 * time.Second
+	}
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	var p LLMProvider
+	switch cfg.Provider {
+	case "", ProviderNone:
+		return nil, nil
+	case ProviderGemini:
+		if cfg.APIKey == "" {
+			return nil, errors.New("gemini provider needs an API key")
+		}
+		p = NewGemini(cfg.APIKey, cfg.Model, client)
+	case ProviderOpenAI:
+		if cfg.BaseURL == "" || cfg.Model == "" {
+			return nil, errors.New("openai provider needs a base URL and a model")
+		}
+		p = NewOpenAI(cfg.BaseURL, cfg.APIKey, cfg.Model, client)
+	case ProviderFixture:
+		fp, err := LoadFixtures(cfg.FixtureDir)
+		if err != nil {
+			return nil, err
+		}
+		p = fp
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.Provider)
+	}
+
+	if cfg.RecordDir != "" && cfg.Provider != ProviderFixture {
+		p = &Recorder{Inner: p, Dir: cfg.RecordDir}
+	}
+	return p, nil
+}
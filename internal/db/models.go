@@ -13,13 +13,37 @@ import (
 	"github.com/sqlc-dev/pqtype"
 )
 
+type ApiToken struct {
+	ID         uuid.UUID
+	PersonID   uuid.UUID
+	TokenHash  string
+	CreatedAt  time.Time
+	ExpiresAt  sql.NullTime
+	Name       string
+	Scope      string
+	EventIds   []uuid.UUID
+	LastUsedAt sql.NullTime
+	RevokedAt  sql.NullTime
+}
+
 type Event struct {
+	ID            uuid.UUID
+	Name          string
+	EventDate     time.Time
+	CreatedAt     time.Time
+	Location      sql.NullString
+	Summary       sql.NullString
+	RiskProfileID uuid.NullUUID
+}
+
+type EventBriefing struct {
 	ID        uuid.UUID
-	Name      string
-	EventDate time.Time
+	EventID   uuid.UUID
+	CreatedBy uuid.NullUUID
+	Source    string
+	Days      int32
+	Report    json.RawMessage
 	CreatedAt time.Time
-	Location  sql.NullString
-	Summary   sql.NullString
 }
 
 type EventMember struct {
@@ -29,6 +53,33 @@ type EventMember struct {
 	CreatedAt time.Time
 }
 
+type NotificationDelivery struct {
+	ID            uuid.UUID
+	PersonID      uuid.UUID
+	Channel       string
+	Kind          string
+	DedupeKey     string
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int32
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	SentAt        sql.NullTime
+	CreatedAt     time.Time
+}
+
+type NotificationPref struct {
+	PersonID      uuid.UUID
+	EmailEnabled  bool
+	WebhookUrl    sql.NullString
+	Timezone      string
+	QuietStart    sql.NullInt32
+	QuietEnd      sql.NullInt32
+	UpdatedAt     time.Time
+	DigestHour    sql.NullInt32
+	DigestChannel sql.NullString
+}
+
 type Person struct {
 	ID           uuid.UUID
 	Name         string
@@ -38,6 +89,40 @@ type Person struct {
 	PasswordHash sql.NullString
 }
 
+type RiskProfile struct {
+	ID                    uuid.UUID
+	OverduePoints         int32
+	DueSoonPoints         int32
+	DueSoonDays           int32
+	StaleShortDays        int32
+	StaleShortPoints      int32
+	StaleLongDays         int32
+	StaleLongPoints       int32
+	BlockedPoints         int32
+	PriorityWeight        int32
+	BlocksWeight          int32
+	BlocksCap             int32
+	OverdueUpstreamPoints int32
+	CriticalStepPoints    int32
+	BehindSchedulePoints  int32
+	HighThreshold         int32
+	MedThreshold          int32
+	UpdatedAt             time.Time
+	Name                  string
+	Builtin               bool
+	CreatedBy             uuid.NullUUID
+}
+
+type RiskSnapshot struct {
+	TaskID       uuid.UUID
+	EventID      uuid.UUID
+	SnapshotDate time.Time
+	Score        int32
+	Level        string
+	Breakdown    json.RawMessage
+	CreatedAt    time.Time
+}
+
 type Session struct {
 	Token  string
 	Data   []byte
@@ -64,6 +149,17 @@ type Task struct {
 	Subtasks     pqtype.NullRawMessage
 }
 
+type TaskAlert struct {
+	ID         uuid.UUID
+	TaskID     uuid.UUID
+	EventID    uuid.UUID
+	Rule       string
+	Message    string
+	CreatedAt  time.Time
+	ResolvedAt sql.NullTime
+	Resolution sql.NullString
+}
+
 type TaskDependency struct {
 	TaskID       uuid.UUID
 	DependencyID uuid.UUID
@@ -77,6 +173,13 @@ type TaskEvent struct {
 	Changes   json.RawMessage
 	CreatedAt time.Time
 	ActorID   uuid.NullUUID
+	Seq       int64
+}
+
+type TaskSearch struct {
+	TaskID   uuid.UUID
+	Document interface{}
+	Body     string
 }
 
 type TaskUpdate struct {
@@ -32,19 +32,115 @@ func (q *Queries) AddEventMember(ctx context.Context, arg AddEventMemberParams)
 	return err
 }
 
-const batchSoftDeleteTasks = `-- name: BatchSoftDeleteTasks :exec
+const addTaskDependency = `-- name: AddTaskDependency :execrows
+INSERT INTO task_dependencies (task_id, dependency_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING
+`
+
+type AddTaskDependencyParams struct {
+	TaskID       uuid.UUID
+	DependencyID uuid.UUID
+}
+
+func (q *Queries) AddTaskDependency(ctx context.Context, arg AddTaskDependencyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addTaskDependency, arg.TaskID, arg.DependencyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const batchSoftDeleteTasks = `-- name: BatchSoftDeleteTasks :many
 UPDATE tasks 
 SET deleted_at = NOW() 
-WHERE id = ANY($1::uuid[])
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+RETURNING id, title, description, owner_id, status, priority, due_date, tags, last_update_at, created_at, event_id, category, completed_at, is_archived, deleted_at, assignee_text, subtasks
 `
 
-func (q *Queries) BatchSoftDeleteTasks(ctx context.Context, dollar_1 []uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, batchSoftDeleteTasks, pq.Array(dollar_1))
-	return err
+func (q *Queries) BatchSoftDeleteTasks(ctx context.Context, dollar_1 []uuid.UUID) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, batchSoftDeleteTasks, pq.Array(dollar_1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.OwnerID,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			pq.Array(&i.Tags),
+			&i.LastUpdateAt,
+			&i.CreatedAt,
+			&i.EventID,
+			&i.Category,
+			&i.CompletedAt,
+			&i.IsArchived,
+			&i.DeletedAt,
+			&i.AssigneeText,
+			&i.Subtasks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createApiToken = `-- name: CreateApiToken :one
+INSERT INTO api_tokens (person_id, token_hash, name, scope, event_ids, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id, person_id, token_hash, created_at, expires_at, name, scope, event_ids, last_used_at, revoked_at
+`
+
+type CreateApiTokenParams struct {
+	PersonID  uuid.UUID
+	TokenHash string
+	Name      string
+	Scope     string
+	EventIds  []uuid.UUID
+	ExpiresAt sql.NullTime
+}
+
+func (q *Queries) CreateApiToken(ctx context.Context, arg CreateApiTokenParams) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, createApiToken,
+		arg.PersonID,
+		arg.TokenHash,
+		arg.Name,
+		arg.Scope,
+		pq.Array(arg.EventIds),
+		arg.ExpiresAt,
+	)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.PersonID,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Name,
+		&i.Scope,
+		pq.Array(&i.EventIds),
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
 }
 
 const createEvent = `-- name: CreateEvent :one
-INSERT INTO events (name, event_date) VALUES ($1, $2) RETURNING id, name, event_date, created_at, location, summary
+INSERT INTO events (name, event_date) VALUES ($1, $2) RETURNING id, name, event_date, created_at, location, summary, risk_profile_id
 `
 
 type CreateEventParams struct {
@@ -62,6 +158,42 @@ func (q *Queries) CreateEvent(ctx context.Context, arg CreateEventParams) (Event
 		&i.CreatedAt,
 		&i.Location,
 		&i.Summary,
+		&i.RiskProfileID,
+	)
+	return i, err
+}
+
+const createEventBriefing = `-- name: CreateEventBriefing :one
+INSERT INTO event_briefings (event_id, created_by, source, days, report)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, event_id, created_by, source, days, report, created_at
+`
+
+type CreateEventBriefingParams struct {
+	EventID   uuid.UUID
+	CreatedBy uuid.NullUUID
+	Source    string
+	Days      int32
+	Report    json.RawMessage
+}
+
+func (q *Queries) CreateEventBriefing(ctx context.Context, arg CreateEventBriefingParams) (EventBriefing, error) {
+	row := q.db.QueryRowContext(ctx, createEventBriefing,
+		arg.EventID,
+		arg.CreatedBy,
+		arg.Source,
+		arg.Days,
+		arg.Report,
+	)
+	var i EventBriefing
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.CreatedBy,
+		&i.Source,
+		&i.Days,
+		&i.Report,
+		&i.CreatedAt,
 	)
 	return i, err
 }
@@ -92,6 +224,88 @@ func (q *Queries) CreatePerson(ctx context.Context, arg CreatePersonParams) (Per
 	return i, err
 }
 
+const createRiskProfile = `-- name: CreateRiskProfile :one
+INSERT INTO risk_profiles (
+    name, created_by,
+    overdue_points, due_soon_points, due_soon_days,
+    stale_short_days, stale_short_points, stale_long_days, stale_long_points,
+    blocked_points, priority_weight,
+    blocks_weight, blocks_cap, overdue_upstream_points, critical_step_points, behind_schedule_points,
+    high_threshold, med_threshold
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING id, overdue_points, due_soon_points, due_soon_days, stale_short_days, stale_short_points, stale_long_days, stale_long_points, blocked_points, priority_weight, blocks_weight, blocks_cap, overdue_upstream_points, critical_step_points, behind_schedule_points, high_threshold, med_threshold, updated_at, name, builtin, created_by
+`
+
+type CreateRiskProfileParams struct {
+	Name                  string
+	CreatedBy             uuid.NullUUID
+	OverduePoints         int32
+	DueSoonPoints         int32
+	DueSoonDays           int32
+	StaleShortDays        int32
+	StaleShortPoints      int32
+	StaleLongDays         int32
+	StaleLongPoints       int32
+	BlockedPoints         int32
+	PriorityWeight        int32
+	BlocksWeight          int32
+	BlocksCap             int32
+	OverdueUpstreamPoints int32
+	CriticalStepPoints    int32
+	BehindSchedulePoints  int32
+	HighThreshold         int32
+	MedThreshold          int32
+}
+
+func (q *Queries) CreateRiskProfile(ctx context.Context, arg CreateRiskProfileParams) (RiskProfile, error) {
+	row := q.db.QueryRowContext(ctx, createRiskProfile,
+		arg.Name,
+		arg.CreatedBy,
+		arg.OverduePoints,
+		arg.DueSoonPoints,
+		arg.DueSoonDays,
+		arg.StaleShortDays,
+		arg.StaleShortPoints,
+		arg.StaleLongDays,
+		arg.StaleLongPoints,
+		arg.BlockedPoints,
+		arg.PriorityWeight,
+		arg.BlocksWeight,
+		arg.BlocksCap,
+		arg.OverdueUpstreamPoints,
+		arg.CriticalStepPoints,
+		arg.BehindSchedulePoints,
+		arg.HighThreshold,
+		arg.MedThreshold,
+	)
+	var i RiskProfile
+	err := row.Scan(
+		&i.ID,
+		&i.OverduePoints,
+		&i.DueSoonPoints,
+		&i.DueSoonDays,
+		&i.StaleShortDays,
+		&i.StaleShortPoints,
+		&i.StaleLongDays,
+		&i.StaleLongPoints,
+		&i.BlockedPoints,
+		&i.PriorityWeight,
+		&i.BlocksWeight,
+		&i.BlocksCap,
+		&i.OverdueUpstreamPoints,
+		&i.CriticalStepPoints,
+		&i.BehindSchedulePoints,
+		&i.HighThreshold,
+		&i.MedThreshold,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Builtin,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
 const createTask = `-- name: CreateTask :one
 INSERT INTO tasks (
     title, description, owner_id, priority, due_date, tags, event_id, category,
@@ -151,23 +365,98 @@ func (q *Queries) CreateTask(ctx context.Context, arg CreateTaskParams) (Task, e
 }
 
 const createTaskEvent = `-- name: CreateTaskEvent :exec
-INSERT INTO task_events (task_id, event_type, changes)
-VALUES ($1, $2, $3)
+INSERT INTO task_events (task_id, event_type, changes, actor_id)
+VALUES ($1, $2, $3, $4)
 `
 
 type CreateTaskEventParams struct {
 	TaskID    uuid.UUID
 	EventType string
 	Changes   json.RawMessage
+	ActorID   uuid.NullUUID
 }
 
 func (q *Queries) CreateTaskEvent(ctx context.Context, arg CreateTaskEventParams) error {
-	_, err := q.db.ExecContext(ctx, createTaskEvent, arg.TaskID, arg.EventType, arg.Changes)
+	_, err := q.db.ExecContext(ctx, createTaskEvent,
+		arg.TaskID,
+		arg.EventType,
+		arg.Changes,
+		arg.ActorID,
+	)
+	return err
+}
+
+const deferDelivery = `-- name: DeferDelivery :exec
+UPDATE notification_deliveries
+SET next_attempt_at = $2
+WHERE id = $1
+`
+
+type DeferDeliveryParams struct {
+	ID            uuid.UUID
+	NextAttemptAt time.Time
+}
+
+func (q *Queries) DeferDelivery(ctx context.Context, arg DeferDeliveryParams) error {
+	_, err := q.db.ExecContext(ctx, deferDelivery, arg.ID, arg.NextAttemptAt)
 	return err
 }
 
+const enqueueDelivery = `-- name: EnqueueDelivery :execrows
+INSERT INTO notification_deliveries (person_id, channel, kind, dedupe_key, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (person_id, channel, dedupe_key) DO NOTHING
+`
+
+type EnqueueDeliveryParams struct {
+	PersonID  uuid.UUID
+	Channel   string
+	Kind      string
+	DedupeKey string
+	Payload   json.RawMessage
+}
+
+func (q *Queries) EnqueueDelivery(ctx context.Context, arg EnqueueDeliveryParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, enqueueDelivery,
+		arg.PersonID,
+		arg.Channel,
+		arg.Kind,
+		arg.DedupeKey,
+		arg.Payload,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getApiTokenByHash = `-- name: GetApiTokenByHash :one
+SELECT id, person_id, token_hash, created_at, expires_at, name, scope, event_ids, last_used_at, revoked_at FROM api_tokens
+WHERE token_hash = $1
+AND revoked_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW())
+`
+
+func (q *Queries) GetApiTokenByHash(ctx context.Context, tokenHash string) (ApiToken, error) {
+	row := q.db.QueryRowContext(ctx, getApiTokenByHash, tokenHash)
+	var i ApiToken
+	err := row.Scan(
+		&i.ID,
+		&i.PersonID,
+		&i.TokenHash,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+		&i.Name,
+		&i.Scope,
+		pq.Array(&i.EventIds),
+		&i.LastUsedAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
 const getEvent = `-- name: GetEvent :one
-SELECT id, name, event_date, created_at, location, summary FROM events WHERE id = $1
+SELECT id, name, event_date, created_at, location, summary, risk_profile_id FROM events WHERE id = $1
 `
 
 func (q *Queries) GetEvent(ctx context.Context, id uuid.UUID) (Event, error) {
@@ -180,6 +469,43 @@ func (q *Queries) GetEvent(ctx context.Context, id uuid.UUID) (Event, error) {
 		&i.CreatedAt,
 		&i.Location,
 		&i.Summary,
+		&i.RiskProfileID,
+	)
+	return i, err
+}
+
+const getEventBriefing = `-- name: GetEventBriefing :one
+SELECT 
+    b.id, b.event_id, b.created_by, b.source, b.days, b.report, b.created_at,
+    p.name as created_by_name
+FROM event_briefings b
+LEFT JOIN people p ON b.created_by = p.id
+WHERE b.id = $1
+`
+
+type GetEventBriefingRow struct {
+	ID            uuid.UUID
+	EventID       uuid.UUID
+	CreatedBy     uuid.NullUUID
+	Source        string
+	Days          int32
+	Report        json.RawMessage
+	CreatedAt     time.Time
+	CreatedByName sql.NullString
+}
+
+func (q *Queries) GetEventBriefing(ctx context.Context, id uuid.UUID) (GetEventBriefingRow, error) {
+	row := q.db.QueryRowContext(ctx, getEventBriefing, id)
+	var i GetEventBriefingRow
+	err := row.Scan(
+		&i.ID,
+		&i.EventID,
+		&i.CreatedBy,
+		&i.Source,
+		&i.Days,
+		&i.Report,
+		&i.CreatedAt,
+		&i.CreatedByName,
 	)
 	return i, err
 }
@@ -201,74 +527,45 @@ func (q *Queries) GetEventMembership(ctx context.Context, arg GetEventMembership
 	return role, err
 }
 
-const getEventTasks = `-- name: GetEventTasks :many
+const getEventRiskHistory = `-- name: GetEventRiskHistory :many
 SELECT 
-    t.id, t.title, t.description, t.owner_id, t.status, t.priority, t.due_date, t.tags, t.last_update_at, t.created_at, t.event_id, t.category, t.completed_at, t.is_archived, t.deleted_at, t.assignee_text, t.subtasks, 
-    p.name as owner_name 
-FROM tasks t
-LEFT JOIN people p ON t.owner_id = p.id
-WHERE t.event_id = $1 
-AND t.deleted_at IS NULL
-AND ($2::boolean = TRUE OR t.status != 'done')
-ORDER BY 
-    CASE WHEN t.status = 'done' THEN 1 ELSE 0 END,
-    t.due_date ASC NULLS LAST
+    snapshot_date,
+    SUM(score)::int as total_score,
+    COUNT(*) FILTER (WHERE level = 'high') as high_tasks,
+    COUNT(*) as tasks
+FROM risk_snapshots
+WHERE event_id = $1 
+AND snapshot_date >= $2
+GROUP BY snapshot_date
+ORDER BY snapshot_date ASC
 `
 
-type GetEventTasksParams struct {
-	EventID uuid.UUID
-	Column2 bool
+type GetEventRiskHistoryParams struct {
+	EventID      uuid.UUID
+	SnapshotDate time.Time
 }
 
-type GetEventTasksRow struct {
-	ID           uuid.UUID
-	Title        string
-	Description  sql.NullString
-	OwnerID      uuid.NullUUID
-	Status       string
-	Priority     int32
-	DueDate      sql.NullTime
-	Tags         []string
-	LastUpdateAt sql.NullTime
-	CreatedAt    time.Time
-	EventID      uuid.UUID
-	Category     string
-	CompletedAt  sql.NullTime
-	IsArchived   bool
-	DeletedAt    sql.NullTime
-	AssigneeText sql.NullString
-	Subtasks     pqtype.NullRawMessage
-	OwnerName    sql.NullString
+type GetEventRiskHistoryRow struct {
+	SnapshotDate time.Time
+	TotalScore   int32
+	HighTasks    int64
+	Tasks        int64
 }
 
-func (q *Queries) GetEventTasks(ctx context.Context, arg GetEventTasksParams) ([]GetEventTasksRow, error) {
-	rows, err := q.db.QueryContext(ctx, getEventTasks, arg.EventID, arg.Column2)
+func (q *Queries) GetEventRiskHistory(ctx context.Context, arg GetEventRiskHistoryParams) ([]GetEventRiskHistoryRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEventRiskHistory, arg.EventID, arg.SnapshotDate)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetEventTasksRow
+	var items []GetEventRiskHistoryRow
 	for rows.Next() {
-		var i GetEventTasksRow
+		var i GetEventRiskHistoryRow
 		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
-			&i.Description,
-			&i.OwnerID,
-			&i.Status,
-			&i.Priority,
-			&i.DueDate,
-			pq.Array(&i.Tags),
-			&i.LastUpdateAt,
-			&i.CreatedAt,
-			&i.EventID,
-			&i.Category,
-			&i.CompletedAt,
-			&i.IsArchived,
-			&i.DeletedAt,
-			&i.AssigneeText,
-			&i.Subtasks,
-			&i.OwnerName,
+			&i.SnapshotDate,
+			&i.TotalScore,
+			&i.HighTasks,
+			&i.Tasks,
 		); err != nil {
 			return nil, err
 		}
@@ -283,20 +580,61 @@ func (q *Queries) GetEventTasks(ctx context.Context, arg GetEventTasksParams) ([
 	return items, nil
 }
 
-const getGlobalActiveTasks = `-- name: GetGlobalActiveTasks :many
+const getEventRiskProfile = `-- name: GetEventRiskProfile :one
+SELECT rp.id, rp.overdue_points, rp.due_soon_points, rp.due_soon_days, rp.stale_short_days, rp.stale_short_points, rp.stale_long_days, rp.stale_long_points, rp.blocked_points, rp.priority_weight, rp.blocks_weight, rp.blocks_cap, rp.overdue_upstream_points, rp.critical_step_points, rp.behind_schedule_points, rp.high_threshold, rp.med_threshold, rp.updated_at, rp.name, rp.builtin, rp.created_by FROM risk_profiles rp
+JOIN events e ON e.risk_profile_id = rp.id
+WHERE e.id = $1
+`
+
+func (q *Queries) GetEventRiskProfile(ctx context.Context, id uuid.UUID) (RiskProfile, error) {
+	row := q.db.QueryRowContext(ctx, getEventRiskProfile, id)
+	var i RiskProfile
+	err := row.Scan(
+		&i.ID,
+		&i.OverduePoints,
+		&i.DueSoonPoints,
+		&i.DueSoonDays,
+		&i.StaleShortDays,
+		&i.StaleShortPoints,
+		&i.StaleLongDays,
+		&i.StaleLongPoints,
+		&i.BlockedPoints,
+		&i.PriorityWeight,
+		&i.BlocksWeight,
+		&i.BlocksCap,
+		&i.OverdueUpstreamPoints,
+		&i.CriticalStepPoints,
+		&i.BehindSchedulePoints,
+		&i.HighThreshold,
+		&i.MedThreshold,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Builtin,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
+const getEventTasks = `-- name: GetEventTasks :many
 SELECT 
     t.id, t.title, t.description, t.owner_id, t.status, t.priority, t.due_date, t.tags, t.last_update_at, t.created_at, t.event_id, t.category, t.completed_at, t.is_archived, t.deleted_at, t.assignee_text, t.subtasks, 
-    p.name as owner_name,
-    e.name as event_name
+    p.name as owner_name 
 FROM tasks t
 LEFT JOIN people p ON t.owner_id = p.id
-JOIN events e ON t.event_id = e.id
-WHERE t.status != 'done' 
+WHERE t.event_id = $1 
 AND t.deleted_at IS NULL
-ORDER BY t.priority DESC, t.due_date ASC
+AND ($2::boolean = TRUE OR t.status != 'done')
+ORDER BY 
+    CASE WHEN t.status = 'done' THEN 1 ELSE 0 END,
+    t.due_date ASC NULLS LAST
 `
 
-type GetGlobalActiveTasksRow struct {
+type GetEventTasksParams struct {
+	EventID uuid.UUID
+	Column2 bool
+}
+
+type GetEventTasksRow struct {
 	ID           uuid.UUID
 	Title        string
 	Description  sql.NullString
@@ -315,18 +653,17 @@ type GetGlobalActiveTasksRow struct {
 	AssigneeText sql.NullString
 	Subtasks     pqtype.NullRawMessage
 	OwnerName    sql.NullString
-	EventName    string
 }
 
-func (q *Queries) GetGlobalActiveTasks(ctx context.Context) ([]GetGlobalActiveTasksRow, error) {
-	rows, err := q.db.QueryContext(ctx, getGlobalActiveTasks)
+func (q *Queries) GetEventTasks(ctx context.Context, arg GetEventTasksParams) ([]GetEventTasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, getEventTasks, arg.EventID, arg.Column2)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetGlobalActiveTasksRow
+	var items []GetEventTasksRow
 	for rows.Next() {
-		var i GetGlobalActiveTasksRow
+		var i GetEventTasksRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.Title,
@@ -346,7 +683,6 @@ func (q *Queries) GetGlobalActiveTasks(ctx context.Context) ([]GetGlobalActiveTa
 			&i.AssigneeText,
 			&i.Subtasks,
 			&i.OwnerName,
-			&i.EventName,
 		); err != nil {
 			return nil, err
 		}
@@ -361,6 +697,28 @@ func (q *Queries) GetGlobalActiveTasks(ctx context.Context) ([]GetGlobalActiveTa
 	return items, nil
 }
 
+const getNotificationPrefs = `-- name: GetNotificationPrefs :one
+SELECT person_id, email_enabled, webhook_url, timezone, quiet_start, quiet_end, updated_at, digest_hour, digest_channel FROM notification_prefs
+WHERE person_id = $1
+`
+
+func (q *Queries) GetNotificationPrefs(ctx context.Context, personID uuid.UUID) (NotificationPref, error) {
+	row := q.db.QueryRowContext(ctx, getNotificationPrefs, personID)
+	var i NotificationPref
+	err := row.Scan(
+		&i.PersonID,
+		&i.EmailEnabled,
+		&i.WebhookUrl,
+		&i.Timezone,
+		&i.QuietStart,
+		&i.QuietEnd,
+		&i.UpdatedAt,
+		&i.DigestHour,
+		&i.DigestChannel,
+	)
+	return i, err
+}
+
 const getPerson = `-- name: GetPerson :one
 SELECT id, name, role, created_at, email, password_hash FROM people WHERE id = $1
 `
@@ -397,6 +755,39 @@ func (q *Queries) GetPersonByEmail(ctx context.Context, email sql.NullString) (P
 	return i, err
 }
 
+const getRiskProfile = `-- name: GetRiskProfile :one
+SELECT id, overdue_points, due_soon_points, due_soon_days, stale_short_days, stale_short_points, stale_long_days, stale_long_points, blocked_points, priority_weight, blocks_weight, blocks_cap, overdue_upstream_points, critical_step_points, behind_schedule_points, high_threshold, med_threshold, updated_at, name, builtin, created_by FROM risk_profiles WHERE id = $1
+`
+
+func (q *Queries) GetRiskProfile(ctx context.Context, id uuid.UUID) (RiskProfile, error) {
+	row := q.db.QueryRowContext(ctx, getRiskProfile, id)
+	var i RiskProfile
+	err := row.Scan(
+		&i.ID,
+		&i.OverduePoints,
+		&i.DueSoonPoints,
+		&i.DueSoonDays,
+		&i.StaleShortDays,
+		&i.StaleShortPoints,
+		&i.StaleLongDays,
+		&i.StaleLongPoints,
+		&i.BlockedPoints,
+		&i.PriorityWeight,
+		&i.BlocksWeight,
+		&i.BlocksCap,
+		&i.OverdueUpstreamPoints,
+		&i.CriticalStepPoints,
+		&i.BehindSchedulePoints,
+		&i.HighThreshold,
+		&i.MedThreshold,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Builtin,
+		&i.CreatedBy,
+	)
+	return i, err
+}
+
 const getTask = `-- name: GetTask :one
 SELECT id, title, description, owner_id, status, priority, due_date, tags, last_update_at, created_at, event_id, category, completed_at, is_archived, deleted_at, assignee_text, subtasks FROM tasks WHERE id = $1 AND deleted_at IS NULL
 `
@@ -427,18 +818,35 @@ func (q *Queries) GetTask(ctx context.Context, id uuid.UUID) (Task, error) {
 }
 
 const getTaskEvents = `-- name: GetTaskEvents :many
-SELECT id, task_id, event_type, changes, created_at, actor_id FROM task_events WHERE task_id = $1 ORDER BY created_at DESC
+SELECT 
+    te.id, te.task_id, te.event_type, te.changes, te.created_at, te.actor_id, te.seq, 
+    p.name as actor_name 
+FROM task_events te
+LEFT JOIN people p ON te.actor_id = p.id
+WHERE te.task_id = $1 
+ORDER BY te.created_at DESC, te.seq DESC
 `
 
-func (q *Queries) GetTaskEvents(ctx context.Context, taskID uuid.UUID) ([]TaskEvent, error) {
+type GetTaskEventsRow struct {
+	ID        uuid.UUID
+	TaskID    uuid.UUID
+	EventType string
+	Changes   json.RawMessage
+	CreatedAt time.Time
+	ActorID   uuid.NullUUID
+	Seq       int64
+	ActorName sql.NullString
+}
+
+func (q *Queries) GetTaskEvents(ctx context.Context, taskID uuid.UUID) ([]GetTaskEventsRow, error) {
 	rows, err := q.db.QueryContext(ctx, getTaskEvents, taskID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []TaskEvent
+	var items []GetTaskEventsRow
 	for rows.Next() {
-		var i TaskEvent
+		var i GetTaskEventsRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.TaskID,
@@ -446,6 +854,8 @@ func (q *Queries) GetTaskEvents(ctx context.Context, taskID uuid.UUID) ([]TaskEv
 			&i.Changes,
 			&i.CreatedAt,
 			&i.ActorID,
+			&i.Seq,
+			&i.ActorName,
 		); err != nil {
 			return nil, err
 		}
@@ -460,27 +870,87 @@ func (q *Queries) GetTaskEvents(ctx context.Context, taskID uuid.UUID) ([]TaskEv
 	return items, nil
 }
 
-const getTasksForFollowUp = `-- name: GetTasksForFollowUp :many
-SELECT id, title, description, owner_id, status, priority, due_date, tags, last_update_at, created_at, event_id, category, completed_at, is_archived, deleted_at, assignee_text, subtasks FROM tasks 
-WHERE status != 'done' 
-AND deleted_at IS NULL
-AND due_date IS NOT NULL 
-AND assignee_text IS NOT NULL 
-AND assignee_text != ''
+const getTaskForUpdate = `-- name: GetTaskForUpdate :one
+SELECT id, title, description, owner_id, status, priority, due_date, tags, last_update_at, created_at, event_id, category, completed_at, is_archived, deleted_at, assignee_text, subtasks FROM tasks WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE
 `
 
-func (q *Queries) GetTasksForFollowUp(ctx context.Context) ([]Task, error) {
-	rows, err := q.db.QueryContext(ctx, getTasksForFollowUp)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []Task
-	for rows.Next() {
-		var i Task
-		if err := rows.Scan(
-			&i.ID,
-			&i.Title,
+func (q *Queries) GetTaskForUpdate(ctx context.Context, id uuid.UUID) (Task, error) {
+	row := q.db.QueryRowContext(ctx, getTaskForUpdate, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.OwnerID,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		pq.Array(&i.Tags),
+		&i.LastUpdateAt,
+		&i.CreatedAt,
+		&i.EventID,
+		&i.Category,
+		&i.CompletedAt,
+		&i.IsArchived,
+		&i.DeletedAt,
+		&i.AssigneeText,
+		&i.Subtasks,
+	)
+	return i, err
+}
+
+const getTaskIncludingDeleted = `-- name: GetTaskIncludingDeleted :one
+SELECT id, title, description, owner_id, status, priority, due_date, tags, last_update_at, created_at, event_id, category, completed_at, is_archived, deleted_at, assignee_text, subtasks FROM tasks WHERE id = $1
+`
+
+func (q *Queries) GetTaskIncludingDeleted(ctx context.Context, id uuid.UUID) (Task, error) {
+	row := q.db.QueryRowContext(ctx, getTaskIncludingDeleted, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.OwnerID,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		pq.Array(&i.Tags),
+		&i.LastUpdateAt,
+		&i.CreatedAt,
+		&i.EventID,
+		&i.Category,
+		&i.CompletedAt,
+		&i.IsArchived,
+		&i.DeletedAt,
+		&i.AssigneeText,
+		&i.Subtasks,
+	)
+	return i, err
+}
+
+const getTasksForFollowUp = `-- name: GetTasksForFollowUp :many
+SELECT t.id, t.title, t.description, t.owner_id, t.status, t.priority, t.due_date, t.tags, t.last_update_at, t.created_at, t.event_id, t.category, t.completed_at, t.is_archived, t.deleted_at, t.assignee_text, t.subtasks FROM tasks t
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+WHERE t.status != 'done' 
+AND t.deleted_at IS NULL
+AND t.due_date IS NOT NULL 
+AND t.assignee_text IS NOT NULL 
+AND t.assignee_text != ''
+`
+
+func (q *Queries) GetTasksForFollowUp(ctx context.Context, personID uuid.UUID) ([]Task, error) {
+	rows, err := q.db.QueryContext(ctx, getTasksForFollowUp, personID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Task
+	for rows.Next() {
+		var i Task
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
 			&i.Description,
 			&i.OwnerID,
 			&i.Status,
@@ -545,48 +1015,91 @@ func (q *Queries) GetTemplateTasks(ctx context.Context, templateID uuid.UUID) ([
 	return items, nil
 }
 
-const listEvents = `-- name: ListEvents :many
+const hasDelivery = `-- name: HasDelivery :one
+SELECT EXISTS (
+    SELECT 1 FROM notification_deliveries
+    WHERE person_id = $1 AND dedupe_key = $2
+)
+`
+
+type HasDeliveryParams struct {
+	PersonID  uuid.UUID
+	DedupeKey string
+}
+
+func (q *Queries) HasDelivery(ctx context.Context, arg HasDeliveryParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasDelivery, arg.PersonID, arg.DedupeKey)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listDeletedEventTasks = `-- name: ListDeletedEventTasks :many
 SELECT 
-    e.id, 
-    e.name, 
-    e.event_date,
-    e.location,
-    e.summary,
-    COUNT(t.id) as total_tasks, 
-    COUNT(t.id) FILTER (WHERE t.status = 'done' AND t.deleted_at IS NULL) as completed_tasks
-FROM events e
-LEFT JOIN tasks t ON e.id = t.event_id AND t.deleted_at IS NULL
-GROUP BY e.id
-ORDER BY e.event_date ASC
+    t.id, t.title, t.description, t.owner_id, t.status, t.priority, t.due_date, t.tags, t.last_update_at, t.created_at, t.event_id, t.category, t.completed_at, t.is_archived, t.deleted_at, t.assignee_text, t.subtasks, 
+    p.name as deleted_by_name
+FROM tasks t
+LEFT JOIN LATERAL (
+    SELECT te.actor_id FROM task_events te
+    WHERE te.task_id = t.id AND te.event_type IN ('DELETED', 'BATCH_DELETED')
+    ORDER BY te.created_at DESC
+    LIMIT 1
+) del ON TRUE
+LEFT JOIN people p ON del.actor_id = p.id
+WHERE t.event_id = $1 
+AND t.deleted_at IS NOT NULL
+ORDER BY t.deleted_at DESC
 `
 
-type ListEventsRow struct {
-	ID             uuid.UUID
-	Name           string
-	EventDate      time.Time
-	Location       sql.NullString
-	Summary        sql.NullString
-	TotalTasks     int64
-	CompletedTasks int64
+type ListDeletedEventTasksRow struct {
+	ID            uuid.UUID
+	Title         string
+	Description   sql.NullString
+	OwnerID       uuid.NullUUID
+	Status        string
+	Priority      int32
+	DueDate       sql.NullTime
+	Tags          []string
+	LastUpdateAt  sql.NullTime
+	CreatedAt     time.Time
+	EventID       uuid.UUID
+	Category      string
+	CompletedAt   sql.NullTime
+	IsArchived    bool
+	DeletedAt     sql.NullTime
+	AssigneeText  sql.NullString
+	Subtasks      pqtype.NullRawMessage
+	DeletedByName sql.NullString
 }
 
-func (q *Queries) ListEvents(ctx context.Context) ([]ListEventsRow, error) {
-	rows, err := q.db.QueryContext(ctx, listEvents)
+func (q *Queries) ListDeletedEventTasks(ctx context.Context, eventID uuid.UUID) ([]ListDeletedEventTasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDeletedEventTasks, eventID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []ListEventsRow
+	var items []ListDeletedEventTasksRow
 	for rows.Next() {
-		var i ListEventsRow
+		var i ListDeletedEventTasksRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
-			&i.EventDate,
-			&i.Location,
-			&i.Summary,
-			&i.TotalTasks,
-			&i.CompletedTasks,
+			&i.Title,
+			&i.Description,
+			&i.OwnerID,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			pq.Array(&i.Tags),
+			&i.LastUpdateAt,
+			&i.CreatedAt,
+			&i.EventID,
+			&i.Category,
+			&i.CompletedAt,
+			&i.IsArchived,
+			&i.DeletedAt,
+			&i.AssigneeText,
+			&i.Subtasks,
+			&i.DeletedByName,
 		); err != nil {
 			return nil, err
 		}
@@ -601,26 +1114,1252 @@ func (q *Queries) ListEvents(ctx context.Context) ([]ListEventsRow, error) {
 	return items, nil
 }
 
-const listPeople = `-- name: ListPeople :many
-SELECT id, name, role, created_at, email, password_hash FROM people ORDER BY name ASC
+const listDueDeliveries = `-- name: ListDueDeliveries :many
+SELECT 
+    d.id, d.person_id, d.channel, d.kind, d.dedupe_key, d.payload, d.status, d.attempts, d.last_error, d.next_attempt_at, d.sent_at, d.created_at,
+    p.name as person_name,
+    p.email as person_email,
+    np.webhook_url,
+    np.timezone,
+    np.quiet_start,
+    np.quiet_end
+FROM notification_deliveries d
+JOIN people p ON d.person_id = p.id
+LEFT JOIN notification_prefs np ON d.person_id = np.person_id
+WHERE d.status = 'pending'
+AND d.next_attempt_at <= NOW()
+ORDER BY d.next_attempt_at ASC
+LIMIT $1
 `
 
-func (q *Queries) ListPeople(ctx context.Context) ([]Person, error) {
-	rows, err := q.db.QueryContext(ctx, listPeople)
+type ListDueDeliveriesRow struct {
+	ID            uuid.UUID
+	PersonID      uuid.UUID
+	Channel       string
+	Kind          string
+	DedupeKey     string
+	Payload       json.RawMessage
+	Status        string
+	Attempts      int32
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+	SentAt        sql.NullTime
+	CreatedAt     time.Time
+	PersonName    string
+	PersonEmail   sql.NullString
+	WebhookUrl    sql.NullString
+	Timezone      sql.NullString
+	QuietStart    sql.NullInt32
+	QuietEnd      sql.NullInt32
+}
+
+func (q *Queries) ListDueDeliveries(ctx context.Context, limit int32) ([]ListDueDeliveriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listDueDeliveries, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Person
+	var items []ListDueDeliveriesRow
 	for rows.Next() {
-		var i Person
+		var i ListDueDeliveriesRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
+			&i.PersonID,
+			&i.Channel,
+			&i.Kind,
+			&i.DedupeKey,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.SentAt,
+			&i.CreatedAt,
+			&i.PersonName,
+			&i.PersonEmail,
+			&i.WebhookUrl,
+			&i.Timezone,
+			&i.QuietStart,
+			&i.QuietEnd,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventBriefings = `-- name: ListEventBriefings :many
+SELECT 
+    b.id, b.source, b.days, b.created_at,
+    p.name as created_by_name
+FROM event_briefings b
+LEFT JOIN people p ON b.created_by = p.id
+WHERE b.event_id = $1
+ORDER BY b.created_at DESC
+LIMIT 20
+`
+
+type ListEventBriefingsRow struct {
+	ID            uuid.UUID
+	Source        string
+	Days          int32
+	CreatedAt     time.Time
+	CreatedByName sql.NullString
+}
+
+func (q *Queries) ListEventBriefings(ctx context.Context, eventID uuid.UUID) ([]ListEventBriefingsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventBriefings, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventBriefingsRow
+	for rows.Next() {
+		var i ListEventBriefingsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Source,
+			&i.Days,
+			&i.CreatedAt,
+			&i.CreatedByName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventDependencies = `-- name: ListEventDependencies :many
+SELECT 
+    d.task_id, 
+    d.dependency_id,
+    dep.title as dependency_title,
+    dep.status as dependency_status
+FROM task_dependencies d
+JOIN tasks t ON d.task_id = t.id
+JOIN tasks dep ON d.dependency_id = dep.id
+WHERE t.event_id = $1 
+AND t.deleted_at IS NULL
+AND dep.deleted_at IS NULL
+`
+
+type ListEventDependenciesRow struct {
+	TaskID           uuid.UUID
+	DependencyID     uuid.UUID
+	DependencyTitle  string
+	DependencyStatus string
+}
+
+func (q *Queries) ListEventDependencies(ctx context.Context, eventID uuid.UUID) ([]ListEventDependenciesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventDependencies, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventDependenciesRow
+	for rows.Next() {
+		var i ListEventDependenciesRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.DependencyID,
+			&i.DependencyTitle,
+			&i.DependencyStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventDependencyEdges = `-- name: ListEventDependencyEdges :many
+SELECT 
+    d.task_id, 
+    d.dependency_id,
+    dep.title as dependency_title,
+    dep.status as dependency_status
+FROM task_dependencies d
+JOIN tasks t ON d.task_id = t.id
+JOIN tasks dep ON d.dependency_id = dep.id
+WHERE t.event_id = $1
+`
+
+type ListEventDependencyEdgesRow struct {
+	TaskID           uuid.UUID
+	DependencyID     uuid.UUID
+	DependencyTitle  string
+	DependencyStatus string
+}
+
+func (q *Queries) ListEventDependencyEdges(ctx context.Context, eventID uuid.UUID) ([]ListEventDependencyEdgesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventDependencyEdges, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventDependencyEdgesRow
+	for rows.Next() {
+		var i ListEventDependencyEdgesRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.DependencyID,
+			&i.DependencyTitle,
+			&i.DependencyStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventMembers = `-- name: ListEventMembers :many
+SELECT 
+    em.event_id, em.person_id, em.role, em.created_at,
+    p.name, p.email
+FROM event_members em
+JOIN people p ON em.person_id = p.id
+WHERE em.event_id = $1
+ORDER BY 
+    CASE em.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END,
+    p.name ASC
+`
+
+type ListEventMembersRow struct {
+	EventID   uuid.UUID
+	PersonID  uuid.UUID
+	Role      string
+	CreatedAt time.Time
+	Name      string
+	Email     sql.NullString
+}
+
+func (q *Queries) ListEventMembers(ctx context.Context, eventID uuid.UUID) ([]ListEventMembersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventMembers, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventMembersRow
+	for rows.Next() {
+		var i ListEventMembersRow
+		if err := rows.Scan(
+			&i.EventID,
+			&i.PersonID,
 			&i.Role,
 			&i.CreatedAt,
+			&i.Name,
 			&i.Email,
-			&i.PasswordHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventRiskSnapshots = `-- name: ListEventRiskSnapshots :many
+SELECT task_id, score, level FROM risk_snapshots 
+WHERE event_id = $1 AND snapshot_date = $2
+`
+
+type ListEventRiskSnapshotsParams struct {
+	EventID      uuid.UUID
+	SnapshotDate time.Time
+}
+
+type ListEventRiskSnapshotsRow struct {
+	TaskID uuid.UUID
+	Score  int32
+	Level  string
+}
+
+func (q *Queries) ListEventRiskSnapshots(ctx context.Context, arg ListEventRiskSnapshotsParams) ([]ListEventRiskSnapshotsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventRiskSnapshots, arg.EventID, arg.SnapshotDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventRiskSnapshotsRow
+	for rows.Next() {
+		var i ListEventRiskSnapshotsRow
+		if err := rows.Scan(&i.TaskID, &i.Score, &i.Level); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEventTaskChanges = `-- name: ListEventTaskChanges :many
+SELECT 
+    te.task_id, te.event_type, te.changes, te.created_at,
+    t.title as task_title,
+    p.name as actor_name
+FROM task_events te
+JOIN tasks t ON te.task_id = t.id
+LEFT JOIN people p ON te.actor_id = p.id
+WHERE t.event_id = $1
+AND te.created_at >= $2
+ORDER BY te.created_at ASC, te.seq ASC
+`
+
+type ListEventTaskChangesParams struct {
+	EventID   uuid.UUID
+	CreatedAt time.Time
+}
+
+type ListEventTaskChangesRow struct {
+	TaskID    uuid.UUID
+	EventType string
+	Changes   json.RawMessage
+	CreatedAt time.Time
+	TaskTitle string
+	ActorName sql.NullString
+}
+
+func (q *Queries) ListEventTaskChanges(ctx context.Context, arg ListEventTaskChangesParams) ([]ListEventTaskChangesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEventTaskChanges, arg.EventID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventTaskChangesRow
+	for rows.Next() {
+		var i ListEventTaskChangesRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.EventType,
+			&i.Changes,
+			&i.CreatedAt,
+			&i.TaskTitle,
+			&i.ActorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEvents = `-- name: ListEvents :many
+SELECT 
+    e.id, 
+    e.name, 
+    e.event_date,
+    e.location,
+    e.summary,
+    COUNT(t.id) as total_tasks, 
+    COUNT(t.id) FILTER (WHERE t.status = 'done' AND t.deleted_at IS NULL) as completed_tasks
+FROM events e
+LEFT JOIN tasks t ON e.id = t.event_id AND t.deleted_at IS NULL
+GROUP BY e.id
+ORDER BY e.event_date ASC
+`
+
+type ListEventsRow struct {
+	ID             uuid.UUID
+	Name           string
+	EventDate      time.Time
+	Location       sql.NullString
+	Summary        sql.NullString
+	TotalTasks     int64
+	CompletedTasks int64
+}
+
+func (q *Queries) ListEvents(ctx context.Context) ([]ListEventsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listEvents)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListEventsRow
+	for rows.Next() {
+		var i ListEventsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.EventDate,
+			&i.Location,
+			&i.Summary,
+			&i.TotalTasks,
+			&i.CompletedTasks,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMyTasks = `-- name: ListMyTasks :many
+SELECT 
+    t.id, t.title, t.description, t.owner_id, t.status, t.priority, t.due_date, t.tags, t.last_update_at, t.created_at, t.event_id, t.category, t.completed_at, t.is_archived, t.deleted_at, t.assignee_text, t.subtasks, 
+    e.name as event_name,
+    em.role as user_role
+FROM tasks t
+JOIN events e ON t.event_id = e.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+WHERE t.deleted_at IS NULL
+AND t.status != 'done'
+AND (t.owner_id = $1 OR ($2::text != '' AND LOWER(t.assignee_text) = LOWER($2::text)))
+ORDER BY t.due_date ASC NULLS LAST, t.priority DESC
+`
+
+type ListMyTasksParams struct {
+	PersonID uuid.UUID
+	Column2  string
+}
+
+type ListMyTasksRow struct {
+	ID           uuid.UUID
+	Title        string
+	Description  sql.NullString
+	OwnerID      uuid.NullUUID
+	Status       string
+	Priority     int32
+	DueDate      sql.NullTime
+	Tags         []string
+	LastUpdateAt sql.NullTime
+	CreatedAt    time.Time
+	EventID      uuid.UUID
+	Category     string
+	CompletedAt  sql.NullTime
+	IsArchived   bool
+	DeletedAt    sql.NullTime
+	AssigneeText sql.NullString
+	Subtasks     pqtype.NullRawMessage
+	EventName    string
+	UserRole     string
+}
+
+func (q *Queries) ListMyTasks(ctx context.Context, arg ListMyTasksParams) ([]ListMyTasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listMyTasks, arg.PersonID, arg.Column2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListMyTasksRow
+	for rows.Next() {
+		var i ListMyTasksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.OwnerID,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			pq.Array(&i.Tags),
+			&i.LastUpdateAt,
+			&i.CreatedAt,
+			&i.EventID,
+			&i.Category,
+			&i.CompletedAt,
+			&i.IsArchived,
+			&i.DeletedAt,
+			&i.AssigneeText,
+			&i.Subtasks,
+			&i.EventName,
+			&i.UserRole,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOpenTaskAlerts = `-- name: ListOpenTaskAlerts :many
+SELECT id, task_id, rule FROM task_alerts
+WHERE resolved_at IS NULL
+`
+
+type ListOpenTaskAlertsRow struct {
+	ID     uuid.UUID
+	TaskID uuid.UUID
+	Rule   string
+}
+
+func (q *Queries) ListOpenTaskAlerts(ctx context.Context) ([]ListOpenTaskAlertsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOpenTaskAlerts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOpenTaskAlertsRow
+	for rows.Next() {
+		var i ListOpenTaskAlertsRow
+		if err := rows.Scan(&i.ID, &i.TaskID, &i.Rule); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnedTaskChanges = `-- name: ListOwnedTaskChanges :many
+SELECT 
+    te.task_id, te.event_type, te.changes, te.created_at,
+    t.title as task_title,
+    e.name as event_name,
+    p.name as actor_name
+FROM task_events te
+JOIN tasks t ON te.task_id = t.id
+JOIN events e ON t.event_id = e.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+LEFT JOIN people p ON te.actor_id = p.id
+WHERE t.owner_id = $1
+AND t.deleted_at IS NULL
+AND te.created_at >= $2
+AND (te.actor_id IS NULL OR te.actor_id != $1)
+ORDER BY te.created_at ASC, te.seq ASC
+`
+
+type ListOwnedTaskChangesParams struct {
+	PersonID  uuid.UUID
+	CreatedAt time.Time
+}
+
+type ListOwnedTaskChangesRow struct {
+	TaskID    uuid.UUID
+	EventType string
+	Changes   json.RawMessage
+	CreatedAt time.Time
+	TaskTitle string
+	EventName string
+	ActorName sql.NullString
+}
+
+func (q *Queries) ListOwnedTaskChanges(ctx context.Context, arg ListOwnedTaskChangesParams) ([]ListOwnedTaskChangesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listOwnedTaskChanges, arg.PersonID, arg.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListOwnedTaskChangesRow
+	for rows.Next() {
+		var i ListOwnedTaskChangesRow
+		if err := rows.Scan(
+			&i.TaskID,
+			&i.EventType,
+			&i.Changes,
+			&i.CreatedAt,
+			&i.TaskTitle,
+			&i.EventName,
+			&i.ActorName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPeople = `-- name: ListPeople :many
+SELECT id, name, role, created_at, email, password_hash FROM people ORDER BY name ASC
+`
+
+func (q *Queries) ListPeople(ctx context.Context) ([]Person, error) {
+	rows, err := q.db.QueryContext(ctx, listPeople)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Person
+	for rows.Next() {
+		var i Person
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Role,
+			&i.CreatedAt,
+			&i.Email,
+			&i.PasswordHash,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPersonAlerts = `-- name: ListPersonAlerts :many
+SELECT 
+    a.id, a.task_id, a.event_id, a.rule, a.message, a.created_at, a.resolved_at, a.resolution,
+    t.title as task_title,
+    e.name as event_name
+FROM task_alerts a
+JOIN tasks t ON a.task_id = t.id
+JOIN events e ON a.event_id = e.id
+JOIN event_members em ON a.event_id = em.event_id AND em.person_id = $1
+WHERE (t.owner_id = $1 OR em.role = 'owner')
+AND (a.resolved_at IS NULL OR a.resolved_at > NOW() - INTERVAL '7 days')
+ORDER BY (a.resolved_at IS NULL) DESC, a.created_at DESC
+LIMIT 100
+`
+
+type ListPersonAlertsRow struct {
+	ID         uuid.UUID
+	TaskID     uuid.UUID
+	EventID    uuid.UUID
+	Rule       string
+	Message    string
+	CreatedAt  time.Time
+	ResolvedAt sql.NullTime
+	Resolution sql.NullString
+	TaskTitle  string
+	EventName  string
+}
+
+func (q *Queries) ListPersonAlerts(ctx context.Context, personID uuid.UUID) ([]ListPersonAlertsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPersonAlerts, personID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPersonAlertsRow
+	for rows.Next() {
+		var i ListPersonAlertsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.TaskID,
+			&i.EventID,
+			&i.Rule,
+			&i.Message,
+			&i.CreatedAt,
+			&i.ResolvedAt,
+			&i.Resolution,
+			&i.TaskTitle,
+			&i.EventName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPersonApiTokens = `-- name: ListPersonApiTokens :many
+SELECT id, person_id, token_hash, created_at, expires_at, name, scope, event_ids, last_used_at, revoked_at FROM api_tokens
+WHERE person_id = $1
+ORDER BY revoked_at IS NOT NULL, created_at DESC
+`
+
+func (q *Queries) ListPersonApiTokens(ctx context.Context, personID uuid.UUID) ([]ApiToken, error) {
+	rows, err := q.db.QueryContext(ctx, listPersonApiTokens, personID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiToken
+	for rows.Next() {
+		var i ApiToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.PersonID,
+			&i.TokenHash,
+			&i.CreatedAt,
+			&i.ExpiresAt,
+			&i.Name,
+			&i.Scope,
+			pq.Array(&i.EventIds),
+			&i.LastUsedAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPersonDeliveries = `-- name: ListPersonDeliveries :many
+SELECT id, person_id, channel, kind, dedupe_key, payload, status, attempts, last_error, next_attempt_at, sent_at, created_at FROM notification_deliveries
+WHERE person_id = $1
+ORDER BY created_at DESC
+LIMIT $2
+`
+
+type ListPersonDeliveriesParams struct {
+	PersonID uuid.UUID
+	Limit    int32
+}
+
+func (q *Queries) ListPersonDeliveries(ctx context.Context, arg ListPersonDeliveriesParams) ([]NotificationDelivery, error) {
+	rows, err := q.db.QueryContext(ctx, listPersonDeliveries, arg.PersonID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []NotificationDelivery
+	for rows.Next() {
+		var i NotificationDelivery
+		if err := rows.Scan(
+			&i.ID,
+			&i.PersonID,
+			&i.Channel,
+			&i.Kind,
+			&i.DedupeKey,
+			&i.Payload,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.NextAttemptAt,
+			&i.SentAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRiskProfileEvents = `-- name: ListRiskProfileEvents :many
+SELECT e.id, e.name, em.role as user_role
+FROM events e
+LEFT JOIN event_members em ON em.event_id = e.id AND em.person_id = $2
+WHERE e.risk_profile_id = $1
+ORDER BY e.event_date
+`
+
+type ListRiskProfileEventsParams struct {
+	RiskProfileID uuid.NullUUID
+	PersonID      uuid.UUID
+}
+
+type ListRiskProfileEventsRow struct {
+	ID       uuid.UUID
+	Name     string
+	UserRole sql.NullString
+}
+
+func (q *Queries) ListRiskProfileEvents(ctx context.Context, arg ListRiskProfileEventsParams) ([]ListRiskProfileEventsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRiskProfileEvents, arg.RiskProfileID, arg.PersonID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRiskProfileEventsRow
+	for rows.Next() {
+		var i ListRiskProfileEventsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.UserRole); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listRiskProfilesForPerson = `-- name: ListRiskProfilesForPerson :many
+SELECT rp.id, rp.overdue_points, rp.due_soon_points, rp.due_soon_days, rp.stale_short_days, rp.stale_short_points, rp.stale_long_days, rp.stale_long_points, rp.blocked_points, rp.priority_weight, rp.blocks_weight, rp.blocks_cap, rp.overdue_upstream_points, rp.critical_step_points, rp.behind_schedule_points, rp.high_threshold, rp.med_threshold, rp.updated_at, rp.name, rp.builtin, rp.created_by, COUNT(e.id) as event_count
+FROM risk_profiles rp
+LEFT JOIN events e ON e.risk_profile_id = rp.id
+WHERE rp.builtin
+OR rp.created_by = $1
+OR rp.id IN (
+    SELECT ev.risk_profile_id FROM events ev
+    JOIN event_members em ON em.event_id = ev.id
+    WHERE em.person_id = $1 AND em.role = 'owner'
+)
+GROUP BY rp.id
+ORDER BY rp.builtin DESC, rp.name
+`
+
+type ListRiskProfilesForPersonRow struct {
+	ID                    uuid.UUID
+	OverduePoints         int32
+	DueSoonPoints         int32
+	DueSoonDays           int32
+	StaleShortDays        int32
+	StaleShortPoints      int32
+	StaleLongDays         int32
+	StaleLongPoints       int32
+	BlockedPoints         int32
+	PriorityWeight        int32
+	BlocksWeight          int32
+	BlocksCap             int32
+	OverdueUpstreamPoints int32
+	CriticalStepPoints    int32
+	BehindSchedulePoints  int32
+	HighThreshold         int32
+	MedThreshold          int32
+	UpdatedAt             time.Time
+	Name                  string
+	Builtin               bool
+	CreatedBy             uuid.NullUUID
+	EventCount            int64
+}
+
+func (q *Queries) ListRiskProfilesForPerson(ctx context.Context, createdBy uuid.NullUUID) ([]ListRiskProfilesForPersonRow, error) {
+	rows, err := q.db.QueryContext(ctx, listRiskProfilesForPerson, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRiskProfilesForPersonRow
+	for rows.Next() {
+		var i ListRiskProfilesForPersonRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OverduePoints,
+			&i.DueSoonPoints,
+			&i.DueSoonDays,
+			&i.StaleShortDays,
+			&i.StaleShortPoints,
+			&i.StaleLongDays,
+			&i.StaleLongPoints,
+			&i.BlockedPoints,
+			&i.PriorityWeight,
+			&i.BlocksWeight,
+			&i.BlocksCap,
+			&i.OverdueUpstreamPoints,
+			&i.CriticalStepPoints,
+			&i.BehindSchedulePoints,
+			&i.HighThreshold,
+			&i.MedThreshold,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Builtin,
+			&i.CreatedBy,
+			&i.EventCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskCategories = `-- name: ListTaskCategories :many
+SELECT DISTINCT t.category
+FROM tasks t
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+WHERE t.deleted_at IS NULL
+AND ($2::uuid IS NULL OR t.event_id = $2)
+ORDER BY t.category
+`
+
+type ListTaskCategoriesParams struct {
+	PersonID uuid.UUID
+	EventID  uuid.NullUUID
+}
+
+func (q *Queries) ListTaskCategories(ctx context.Context, arg ListTaskCategoriesParams) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskCategories, arg.PersonID, arg.EventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var category string
+		if err := rows.Scan(&category); err != nil {
+			return nil, err
+		}
+		items = append(items, category)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskDependencies = `-- name: ListTaskDependencies :many
+SELECT t.id, t.title, t.status, t.due_date
+FROM task_dependencies d
+JOIN tasks t ON d.dependency_id = t.id
+WHERE d.task_id = $1 
+AND t.deleted_at IS NULL
+ORDER BY t.title ASC
+`
+
+type ListTaskDependenciesRow struct {
+	ID      uuid.UUID
+	Title   string
+	Status  string
+	DueDate sql.NullTime
+}
+
+func (q *Queries) ListTaskDependencies(ctx context.Context, taskID uuid.UUID) ([]ListTaskDependenciesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskDependencies, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTaskDependenciesRow
+	for rows.Next() {
+		var i ListTaskDependenciesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Status,
+			&i.DueDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskDependents = `-- name: ListTaskDependents :many
+SELECT t.id, t.title, t.status, t.due_date
+FROM task_dependencies d
+JOIN tasks t ON d.task_id = t.id
+WHERE d.dependency_id = $1 
+AND t.deleted_at IS NULL
+ORDER BY t.title ASC
+`
+
+type ListTaskDependentsRow struct {
+	ID      uuid.UUID
+	Title   string
+	Status  string
+	DueDate sql.NullTime
+}
+
+func (q *Queries) ListTaskDependents(ctx context.Context, dependencyID uuid.UUID) ([]ListTaskDependentsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskDependents, dependencyID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTaskDependentsRow
+	for rows.Next() {
+		var i ListTaskDependentsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Status,
+			&i.DueDate,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskEventIDs = `-- name: ListTaskEventIDs :many
+SELECT DISTINCT event_id FROM tasks 
+WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) ListTaskEventIDs(ctx context.Context, dollar_1 []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskEventIDs, pq.Array(dollar_1))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var event_id uuid.UUID
+		if err := rows.Scan(&event_id); err != nil {
+			return nil, err
+		}
+		items = append(items, event_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTaskOwners = `-- name: ListTaskOwners :many
+SELECT DISTINCT p.id, p.name
+FROM tasks t
+JOIN people p ON t.owner_id = p.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+WHERE t.deleted_at IS NULL
+AND ($2::uuid IS NULL OR t.event_id = $2)
+ORDER BY p.name
+`
+
+type ListTaskOwnersParams struct {
+	PersonID uuid.UUID
+	EventID  uuid.NullUUID
+}
+
+type ListTaskOwnersRow struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) ListTaskOwners(ctx context.Context, arg ListTaskOwnersParams) ([]ListTaskOwnersRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTaskOwners, arg.PersonID, arg.EventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTaskOwnersRow
+	for rows.Next() {
+		var i ListTaskOwnersRow
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTasks = `-- name: ListTasks :many
+SELECT 
+    t.id, t.title, t.description, t.owner_id, t.status, t.priority, t.due_date, t.tags, t.last_update_at, t.created_at, t.event_id, t.category, t.completed_at, t.is_archived, t.deleted_at, t.assignee_text, t.subtasks, 
+    p.name as owner_name,
+    e.name as event_name,
+    e.event_date,
+    em.role as user_role
+FROM tasks t
+LEFT JOIN people p ON t.owner_id = p.id
+JOIN events e ON t.event_id = e.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+WHERE t.deleted_at IS NULL
+AND ($2::uuid IS NULL OR t.event_id = $2)
+AND (cardinality($3::text[]) = 0 OR t.status = ANY($3::text[]))
+AND ($4::text IS NULL OR t.category = $4)
+AND ($5::uuid IS NULL OR t.owner_id = $5)
+AND ($6::boolean = FALSE OR t.owner_id IS NULL)
+AND (cardinality($7::text[]) = 0 OR t.tags @> $7::text[])
+AND ($8::date IS NULL OR t.due_date >= $8)
+AND ($9::date IS NULL OR t.due_date <= $9)
+AND ($10::text = ''
+    OR t.title ILIKE '%' || replace(replace(replace($10, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR t.description ILIKE '%' || replace(replace(replace($10, '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+AND ($11::uuid IS NULL
+    OR ($12::text = 'due' AND (COALESCE(t.due_date, '9999-12-31'), t.id) > ($13::date, $11))
+    OR ($12 = 'priority' AND (t.priority < $14::int
+        OR (t.priority = $14 AND t.id > $11)))
+    OR ($12 = 'updated' AND (COALESCE(t.last_update_at, t.created_at) < $15::timestamp
+        OR (COALESCE(t.last_update_at, t.created_at) = $15 AND t.id > $11))))
+ORDER BY
+    CASE WHEN $12 = 'due' THEN COALESCE(t.due_date, '9999-12-31') END ASC,
+    CASE WHEN $12 = 'priority' THEN t.priority END DESC,
+    CASE WHEN $12 = 'updated' THEN COALESCE(t.last_update_at, t.created_at) END DESC,
+    t.id ASC
+LIMIT $16
+`
+
+type ListTasksParams struct {
+	PersonID      uuid.UUID
+	EventID       uuid.NullUUID
+	Statuses      []string
+	Category      sql.NullString
+	OwnerID       uuid.NullUUID
+	Unassigned    bool
+	Tags          []string
+	DueFrom       sql.NullTime
+	DueTo         sql.NullTime
+	Query         string
+	AfterID       uuid.NullUUID
+	Sort          string
+	AfterDue      sql.NullTime
+	AfterPriority sql.NullInt32
+	AfterUpdated  sql.NullTime
+	PageLimit     sql.NullInt32
+}
+
+type ListTasksRow struct {
+	ID           uuid.UUID
+	Title        string
+	Description  sql.NullString
+	OwnerID      uuid.NullUUID
+	Status       string
+	Priority     int32
+	DueDate      sql.NullTime
+	Tags         []string
+	LastUpdateAt sql.NullTime
+	CreatedAt    time.Time
+	EventID      uuid.UUID
+	Category     string
+	CompletedAt  sql.NullTime
+	IsArchived   bool
+	DeletedAt    sql.NullTime
+	AssigneeText sql.NullString
+	Subtasks     pqtype.NullRawMessage
+	OwnerName    sql.NullString
+	EventName    string
+	EventDate    time.Time
+	UserRole     string
+}
+
+func (q *Queries) ListTasks(ctx context.Context, arg ListTasksParams) ([]ListTasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, listTasks,
+		arg.PersonID,
+		arg.EventID,
+		pq.Array(arg.Statuses),
+		arg.Category,
+		arg.OwnerID,
+		arg.Unassigned,
+		pq.Array(arg.Tags),
+		arg.DueFrom,
+		arg.DueTo,
+		arg.Query,
+		arg.AfterID,
+		arg.Sort,
+		arg.AfterDue,
+		arg.AfterPriority,
+		arg.AfterUpdated,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTasksRow
+	for rows.Next() {
+		var i ListTasksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Description,
+			&i.OwnerID,
+			&i.Status,
+			&i.Priority,
+			&i.DueDate,
+			pq.Array(&i.Tags),
+			&i.LastUpdateAt,
+			&i.CreatedAt,
+			&i.EventID,
+			&i.Category,
+			&i.CompletedAt,
+			&i.IsArchived,
+			&i.DeletedAt,
+			&i.AssigneeText,
+			&i.Subtasks,
+			&i.OwnerName,
+			&i.EventName,
+			&i.EventDate,
+			&i.UserRole,
 		); err != nil {
 			return nil, err
 		}
@@ -724,14 +2463,366 @@ func (q *Queries) ListUserEvents(ctx context.Context, personID uuid.UUID) ([]Lis
 	return items, nil
 }
 
-const softDeleteTask = `-- name: SoftDeleteTask :exec
+const lockEvent = `-- name: LockEvent :exec
+SELECT id FROM events WHERE id = $1 FOR UPDATE
+`
+
+func (q *Queries) LockEvent(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, lockEvent, id)
+	return err
+}
+
+const lockEventOwners = `-- name: LockEventOwners :many
+SELECT person_id FROM event_members
+WHERE event_id = $1 AND role = 'owner'
+FOR UPDATE
+`
+
+func (q *Queries) LockEventOwners(ctx context.Context, eventID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := q.db.QueryContext(ctx, lockEventOwners, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []uuid.UUID
+	for rows.Next() {
+		var person_id uuid.UUID
+		if err := rows.Scan(&person_id); err != nil {
+			return nil, err
+		}
+		items = append(items, person_id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markDeliveryFailed = `-- name: MarkDeliveryFailed :exec
+UPDATE notification_deliveries
+SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+WHERE id = $1
+`
+
+type MarkDeliveryFailedParams struct {
+	ID            uuid.UUID
+	Status        string
+	LastError     sql.NullString
+	NextAttemptAt time.Time
+}
+
+func (q *Queries) MarkDeliveryFailed(ctx context.Context, arg MarkDeliveryFailedParams) error {
+	_, err := q.db.ExecContext(ctx, markDeliveryFailed,
+		arg.ID,
+		arg.Status,
+		arg.LastError,
+		arg.NextAttemptAt,
+	)
+	return err
+}
+
+const markDeliverySent = `-- name: MarkDeliverySent :exec
+UPDATE notification_deliveries
+SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) MarkDeliverySent(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markDeliverySent, id)
+	return err
+}
+
+const openTaskAlert = `-- name: OpenTaskAlert :execrows
+INSERT INTO task_alerts (task_id, event_id, rule, message)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (task_id, rule) WHERE resolved_at IS NULL DO NOTHING
+`
+
+type OpenTaskAlertParams struct {
+	TaskID  uuid.UUID
+	EventID uuid.UUID
+	Rule    string
+	Message string
+}
+
+func (q *Queries) OpenTaskAlert(ctx context.Context, arg OpenTaskAlertParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, openTaskAlert,
+		arg.TaskID,
+		arg.EventID,
+		arg.Rule,
+		arg.Message,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const purgeDeletedTasks = `-- name: PurgeDeletedTasks :execrows
+DELETE FROM tasks 
+WHERE deleted_at IS NOT NULL 
+AND deleted_at < NOW() - make_interval(days => $1::int)
+`
+
+func (q *Queries) PurgeDeletedTasks(ctx context.Context, dollar_1 int32) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedTasks, dollar_1)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeEventMember = `-- name: RemoveEventMember :exec
+DELETE FROM event_members 
+WHERE event_id = $1 AND person_id = $2
+`
+
+type RemoveEventMemberParams struct {
+	EventID  uuid.UUID
+	PersonID uuid.UUID
+}
+
+func (q *Queries) RemoveEventMember(ctx context.Context, arg RemoveEventMemberParams) error {
+	_, err := q.db.ExecContext(ctx, removeEventMember, arg.EventID, arg.PersonID)
+	return err
+}
+
+const removeTaskDependency = `-- name: RemoveTaskDependency :execrows
+DELETE FROM task_dependencies 
+WHERE task_id = $1 AND dependency_id = $2
+`
+
+type RemoveTaskDependencyParams struct {
+	TaskID       uuid.UUID
+	DependencyID uuid.UUID
+}
+
+func (q *Queries) RemoveTaskDependency(ctx context.Context, arg RemoveTaskDependencyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, removeTaskDependency, arg.TaskID, arg.DependencyID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const resolveTaskAlert = `-- name: ResolveTaskAlert :exec
+UPDATE task_alerts
+SET resolved_at = NOW(), resolution = $2
+WHERE id = $1 AND resolved_at IS NULL
+`
+
+type ResolveTaskAlertParams struct {
+	ID         uuid.UUID
+	Resolution sql.NullString
+}
+
+func (q *Queries) ResolveTaskAlert(ctx context.Context, arg ResolveTaskAlertParams) error {
+	_, err := q.db.ExecContext(ctx, resolveTaskAlert, arg.ID, arg.Resolution)
+	return err
+}
+
+const resolveTouchedTaskAlerts = `-- name: ResolveTouchedTaskAlerts :exec
+UPDATE task_alerts
+SET resolved_at = NOW(), resolution = 'touched'
+WHERE task_id = $1 AND rule = ANY($2::text[]) AND resolved_at IS NULL
+`
+
+type ResolveTouchedTaskAlertsParams struct {
+	TaskID  uuid.UUID
+	Column2 []string
+}
+
+func (q *Queries) ResolveTouchedTaskAlerts(ctx context.Context, arg ResolveTouchedTaskAlertsParams) error {
+	_, err := q.db.ExecContext(ctx, resolveTouchedTaskAlerts, arg.TaskID, pq.Array(arg.Column2))
+	return err
+}
+
+const restoreTask = `-- name: RestoreTask :one
+UPDATE tasks 
+SET deleted_at = NULL 
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, title, description, owner_id, status, priority, due_date, tags, last_update_at, created_at, event_id, category, completed_at, is_archived, deleted_at, assignee_text, subtasks
+`
+
+func (q *Queries) RestoreTask(ctx context.Context, id uuid.UUID) (Task, error) {
+	row := q.db.QueryRowContext(ctx, restoreTask, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.OwnerID,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		pq.Array(&i.Tags),
+		&i.LastUpdateAt,
+		&i.CreatedAt,
+		&i.EventID,
+		&i.Category,
+		&i.CompletedAt,
+		&i.IsArchived,
+		&i.DeletedAt,
+		&i.AssigneeText,
+		&i.Subtasks,
+	)
+	return i, err
+}
+
+const revokeApiToken = `-- name: RevokeApiToken :execrows
+UPDATE api_tokens SET revoked_at = NOW()
+WHERE id = $1 AND person_id = $2 AND revoked_at IS NULL
+`
+
+type RevokeApiTokenParams struct {
+	ID       uuid.UUID
+	PersonID uuid.UUID
+}
+
+func (q *Queries) RevokeApiToken(ctx context.Context, arg RevokeApiTokenParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, revokeApiToken, arg.ID, arg.PersonID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchTasks = `-- name: SearchTasks :many
+SELECT
+    t.id, t.title, t.status, t.priority, t.category, t.due_date, t.event_id,
+    e.name as event_name,
+    ts_rank(s.document, query)::real as rank,
+    ts_headline('english', t.title, query, 'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text as title_snippet,
+    ts_headline('english', s.body, query, 'MaxFragments=2, MaxWords=20, MinWords=8, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text as body_snippet
+FROM task_search s
+JOIN tasks t ON t.id = s.task_id
+JOIN events e ON t.event_id = e.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1,
+    websearch_to_tsquery('english', $2) query
+WHERE s.document @@ query
+AND t.deleted_at IS NULL
+AND (cardinality($3::uuid[]) = 0 OR t.event_id = ANY($3::uuid[]))
+ORDER BY rank DESC, t.id
+LIMIT $4
+`
+
+type SearchTasksParams struct {
+	PersonID  uuid.UUID
+	Query     string
+	EventIds  []uuid.UUID
+	PageLimit int32
+}
+
+type SearchTasksRow struct {
+	ID           uuid.UUID
+	Title        string
+	Status       string
+	Priority     int32
+	Category     string
+	DueDate      sql.NullTime
+	EventID      uuid.UUID
+	EventName    string
+	Rank         float32
+	TitleSnippet string
+	BodySnippet  string
+}
+
+func (q *Queries) SearchTasks(ctx context.Context, arg SearchTasksParams) ([]SearchTasksRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchTasks,
+		arg.PersonID,
+		arg.Query,
+		pq.Array(arg.EventIds),
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchTasksRow
+	for rows.Next() {
+		var i SearchTasksRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Status,
+			&i.Priority,
+			&i.Category,
+			&i.DueDate,
+			&i.EventID,
+			&i.EventName,
+			&i.Rank,
+			&i.TitleSnippet,
+			&i.BodySnippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setEventRiskProfile = `-- name: SetEventRiskProfile :exec
+UPDATE events SET risk_profile_id = $2 WHERE id = $1
+`
+
+type SetEventRiskProfileParams struct {
+	ID            uuid.UUID
+	RiskProfileID uuid.NullUUID
+}
+
+func (q *Queries) SetEventRiskProfile(ctx context.Context, arg SetEventRiskProfileParams) error {
+	_, err := q.db.ExecContext(ctx, setEventRiskProfile, arg.ID, arg.RiskProfileID)
+	return err
+}
+
+const softDeleteTask = `-- name: SoftDeleteTask :one
 UPDATE tasks 
 SET deleted_at = NOW() 
-WHERE id = $1
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, title, description, owner_id, status, priority, due_date, tags, last_update_at, created_at, event_id, category, completed_at, is_archived, deleted_at, assignee_text, subtasks
+`
+
+func (q *Queries) SoftDeleteTask(ctx context.Context, id uuid.UUID) (Task, error) {
+	row := q.db.QueryRowContext(ctx, softDeleteTask, id)
+	var i Task
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Description,
+		&i.OwnerID,
+		&i.Status,
+		&i.Priority,
+		&i.DueDate,
+		pq.Array(&i.Tags),
+		&i.LastUpdateAt,
+		&i.CreatedAt,
+		&i.EventID,
+		&i.Category,
+		&i.CompletedAt,
+		&i.IsArchived,
+		&i.DeletedAt,
+		&i.AssigneeText,
+		&i.Subtasks,
+	)
+	return i, err
+}
+
+const touchApiToken = `-- name: TouchApiToken :exec
+UPDATE api_tokens SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
 `
 
-func (q *Queries) SoftDeleteTask(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, softDeleteTask, id)
+func (q *Queries) TouchApiToken(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, touchApiToken, id)
 	return err
 }
 
@@ -743,7 +2834,7 @@ SET
     location = COALESCE($3, location),
     summary = COALESCE($4, summary)
 WHERE id = $5
-RETURNING id, name, event_date, created_at, location, summary
+RETURNING id, name, event_date, created_at, location, summary, risk_profile_id
 `
 
 type UpdateEventParams struct {
@@ -770,6 +2861,117 @@ func (q *Queries) UpdateEvent(ctx context.Context, arg UpdateEventParams) (Event
 		&i.CreatedAt,
 		&i.Location,
 		&i.Summary,
+		&i.RiskProfileID,
+	)
+	return i, err
+}
+
+const updateEventMemberRole = `-- name: UpdateEventMemberRole :exec
+UPDATE event_members 
+SET role = $3 
+WHERE event_id = $1 AND person_id = $2
+`
+
+type UpdateEventMemberRoleParams struct {
+	EventID  uuid.UUID
+	PersonID uuid.UUID
+	Role     string
+}
+
+func (q *Queries) UpdateEventMemberRole(ctx context.Context, arg UpdateEventMemberRoleParams) error {
+	_, err := q.db.ExecContext(ctx, updateEventMemberRole, arg.EventID, arg.PersonID, arg.Role)
+	return err
+}
+
+const updateRiskProfile = `-- name: UpdateRiskProfile :one
+UPDATE risk_profiles SET
+    name = $2,
+    overdue_points = $3,
+    due_soon_points = $4,
+    due_soon_days = $5,
+    stale_short_days = $6,
+    stale_short_points = $7,
+    stale_long_days = $8,
+    stale_long_points = $9,
+    blocked_points = $10,
+    priority_weight = $11,
+    blocks_weight = $12,
+    blocks_cap = $13,
+    overdue_upstream_points = $14,
+    critical_step_points = $15,
+    behind_schedule_points = $16,
+    high_threshold = $17,
+    med_threshold = $18,
+    updated_at = NOW()
+WHERE id = $1 AND NOT builtin
+RETURNING id, overdue_points, due_soon_points, due_soon_days, stale_short_days, stale_short_points, stale_long_days, stale_long_points, blocked_points, priority_weight, blocks_weight, blocks_cap, overdue_upstream_points, critical_step_points, behind_schedule_points, high_threshold, med_threshold, updated_at, name, builtin, created_by
+`
+
+type UpdateRiskProfileParams struct {
+	ID                    uuid.UUID
+	Name                  string
+	OverduePoints         int32
+	DueSoonPoints         int32
+	DueSoonDays           int32
+	StaleShortDays        int32
+	StaleShortPoints      int32
+	StaleLongDays         int32
+	StaleLongPoints       int32
+	BlockedPoints         int32
+	PriorityWeight        int32
+	BlocksWeight          int32
+	BlocksCap             int32
+	OverdueUpstreamPoints int32
+	CriticalStepPoints    int32
+	BehindSchedulePoints  int32
+	HighThreshold         int32
+	MedThreshold          int32
+}
+
+func (q *Queries) UpdateRiskProfile(ctx context.Context, arg UpdateRiskProfileParams) (RiskProfile, error) {
+	row := q.db.QueryRowContext(ctx, updateRiskProfile,
+		arg.ID,
+		arg.Name,
+		arg.OverduePoints,
+		arg.DueSoonPoints,
+		arg.DueSoonDays,
+		arg.StaleShortDays,
+		arg.StaleShortPoints,
+		arg.StaleLongDays,
+		arg.StaleLongPoints,
+		arg.BlockedPoints,
+		arg.PriorityWeight,
+		arg.BlocksWeight,
+		arg.BlocksCap,
+		arg.OverdueUpstreamPoints,
+		arg.CriticalStepPoints,
+		arg.BehindSchedulePoints,
+		arg.HighThreshold,
+		arg.MedThreshold,
+	)
+	var i RiskProfile
+	err := row.Scan(
+		&i.ID,
+		&i.OverduePoints,
+		&i.DueSoonPoints,
+		&i.DueSoonDays,
+		&i.StaleShortDays,
+		&i.StaleShortPoints,
+		&i.StaleLongDays,
+		&i.StaleLongPoints,
+		&i.BlockedPoints,
+		&i.PriorityWeight,
+		&i.BlocksWeight,
+		&i.BlocksCap,
+		&i.OverdueUpstreamPoints,
+		&i.CriticalStepPoints,
+		&i.BehindSchedulePoints,
+		&i.HighThreshold,
+		&i.MedThreshold,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Builtin,
+		&i.CreatedBy,
 	)
 	return i, err
 }
@@ -839,3 +3041,72 @@ func (q *Queries) UpdateTask(ctx context.Context, arg UpdateTaskParams) (Task, e
 	)
 	return i, err
 }
+
+const upsertNotificationPrefs = `-- name: UpsertNotificationPrefs :exec
+INSERT INTO notification_prefs (person_id, email_enabled, webhook_url, timezone, quiet_start, quiet_end, digest_hour, digest_channel)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (person_id) DO UPDATE SET
+    email_enabled = EXCLUDED.email_enabled,
+    webhook_url = EXCLUDED.webhook_url,
+    timezone = EXCLUDED.timezone,
+    quiet_start = EXCLUDED.quiet_start,
+    quiet_end = EXCLUDED.quiet_end,
+    digest_hour = EXCLUDED.digest_hour,
+    digest_channel = EXCLUDED.digest_channel,
+    updated_at = NOW()
+`
+
+type UpsertNotificationPrefsParams struct {
+	PersonID      uuid.UUID
+	EmailEnabled  bool
+	WebhookUrl    sql.NullString
+	Timezone      string
+	QuietStart    sql.NullInt32
+	QuietEnd      sql.NullInt32
+	DigestHour    sql.NullInt32
+	DigestChannel sql.NullString
+}
+
+func (q *Queries) UpsertNotificationPrefs(ctx context.Context, arg UpsertNotificationPrefsParams) error {
+	_, err := q.db.ExecContext(ctx, upsertNotificationPrefs,
+		arg.PersonID,
+		arg.EmailEnabled,
+		arg.WebhookUrl,
+		arg.Timezone,
+		arg.QuietStart,
+		arg.QuietEnd,
+		arg.DigestHour,
+		arg.DigestChannel,
+	)
+	return err
+}
+
+const upsertRiskSnapshot = `-- name: UpsertRiskSnapshot :exec
+INSERT INTO risk_snapshots (task_id, event_id, snapshot_date, score, level, breakdown)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (task_id, snapshot_date) DO UPDATE SET
+    score = EXCLUDED.score,
+    level = EXCLUDED.level,
+    breakdown = EXCLUDED.breakdown
+`
+
+type UpsertRiskSnapshotParams struct {
+	TaskID       uuid.UUID
+	EventID      uuid.UUID
+	SnapshotDate time.Time
+	Score        int32
+	Level        string
+	Breakdown    json.RawMessage
+}
+
+func (q *Queries) UpsertRiskSnapshot(ctx context.Context, arg UpsertRiskSnapshotParams) error {
+	_, err := q.db.ExecContext(ctx, upsertRiskSnapshot,
+		arg.TaskID,
+		arg.EventID,
+		arg.SnapshotDate,
+		arg.Score,
+		arg.Level,
+		arg.Breakdown,
+	)
+	return err
+}
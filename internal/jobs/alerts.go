@@ -0,0 +1,97 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// ScanAlerts scores every open task of every upcoming event and opens an
+// alert for each alert rule that fires. The partial unique index keeps one
+// open alert per task per rule, so re-raising is a no-op. Open alerts whose
+// rule no longer fires (task done, trashed, rescheduled) are cleared.
+func ScanAlerts(q *db.Queries) func(context.Context) error {
+	return func(ctx context.Context) error {
+		now := time.Now()
+		today := now.UTC().Truncate(24 * time.Hour)
+
+		events, err := q.ListEvents(ctx)
+		if err != nil {
+			return err
+		}
+
+		type key struct {
+			task uuid.UUID
+			rule string
+		}
+		firing := map[key]bool{}
+		opened := int64(0)
+		for _, e := range events {
+			if e.EventDate.Before(today) {
+				continue
+			}
+
+			tasks, err := q.GetEventTasks(ctx, db.GetEventTasksParams{EventID: e.ID, Column2: false})
+			if err != nil {
+				return err
+			}
+			deps, err := q.ListEventDependencies(ctx, e.ID)
+			if err != nil {
+				return err
+			}
+			profile, _, err := logic.LoadRiskProfile(ctx, q, e.ID)
+			if err != nil {
+				return err
+			}
+
+			signals := logic.DependencySignals(tasks, deps, e.EventDate, now)
+			for _, t := range tasks {
+				if t.Status == "done" {
+					continue
+				}
+				for _, f := range logic.TaskAlerts(logic.ScoreTaskRow(t, signals[t.ID], profile)) {
+					firing[key{t.ID, f.Rule}] = true
+					n, err := q.OpenTaskAlert(ctx, db.OpenTaskAlertParams{
+						TaskID:  t.ID,
+						EventID: e.ID,
+						Rule:    f.Rule,
+						Message: f.Factor,
+					})
+					if err != nil {
+						return err
+					}
+					opened += n
+				}
+			}
+		}
+
+		open, err := q.ListOpenTaskAlerts(ctx)
+		if err != nil {
+			return err
+		}
+		cleared := 0
+		for _, a := range open {
+			if firing[key{a.TaskID, a.Rule}] {
+				continue
+			}
+			if err := q.ResolveTaskAlert(ctx, db.ResolveTaskAlertParams{
+				ID:         a.ID,
+				Resolution: sql.NullString{String: "cleared", Valid: true},
+			}); err != nil {
+				return err
+			}
+			cleared++
+		}
+
+		if opened > 0 || cleared > 0 {
+			log.Printf("🔔 Alert scan: %d opened, %d cleared", opened, cleared)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,151 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+func TestEvery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var runs atomic.Int32
+	done := make(chan struct{})
+	go func() {
+		Every(ctx, "test", 10*time.Millisecond, func(context.Context) error {
+			// A failing run mustn't stop the loop.
+			if runs.Add(1) == 3 {
+				cancel()
+			}
+			return errors.New("boom")
+		})
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Every didn't return after cancel")
+	}
+	if n := runs.Load(); n != 3 {
+		t.Errorf("ran %d times, want 3", n)
+	}
+}
+
+func TestEveryRunsImmediately(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ran := false
+	// The interval is far off, so only the first, immediate run can happen.
+	Every(ctx, "test", time.Hour, func(context.Context) error {
+		ran = true
+		cancel()
+		return nil
+	})
+	if !ran {
+		t.Error("fn didn't run before the first tick")
+	}
+}
+
+// testQueries connects to TEST_DATABASE_URL, a migrated Postgres database
+// (e.g. `goose -dir sql/migrations postgres "$TEST_DATABASE_URL" up`).
+func testQueries(t *testing.T) (*sql.DB, *db.Queries) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, db.New(conn)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	conn, q := testQueries(t)
+	ctx := context.Background()
+
+	event, err := q.CreateEvent(ctx, db.CreateEventParams{Name: "Purge " + uuid.NewString()[:8], EventDate: time.Now().AddDate(0, 1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trashed := map[string]int{"long ago": 40, "yesterday": 1}
+	ids := map[string]uuid.UUID{}
+	for title, days := range trashed {
+		task, err := q.CreateTask(ctx, db.CreateTaskParams{Title: title, EventID: event.ID, Priority: 1, Category: "general", Tags: []string{}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.ExecContext(ctx, `UPDATE tasks SET deleted_at = NOW() - make_interval(days => $2) WHERE id = $1`, task.ID, days); err != nil {
+			t.Fatal(err)
+		}
+		ids[title] = task.ID
+	}
+
+	if err := PurgeTrash(q, 30)(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for title, want := range map[string]bool{"long ago": false, "yesterday": true} {
+		var exists bool
+		if err := conn.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, ids[title]).Scan(&exists); err != nil {
+			t.Fatal(err)
+		}
+		if exists != want {
+			t.Errorf("task trashed %s: exists = %v, want %v", title, exists, want)
+		}
+	}
+}
+
+func TestBuildDigest(t *testing.T) {
+	_, q := testQueries(t)
+	ctx := context.Background()
+	suffix := uuid.NewString()[:8]
+
+	me, err := q.CreatePerson(ctx, db.CreatePersonParams{Name: "Digest " + suffix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := q.CreatePerson(ctx, db.CreatePersonParams{Name: "Other " + suffix})
+	if err != nil {
+		t.Fatal(err)
+	}
+	event, err := q.CreateEvent(ctx, db.CreateEventParams{Name: "Digest fair " + suffix, EventDate: time.Now().AddDate(0, 1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []db.Person{me, other} {
+		if err := q.AddEventMember(ctx, db.AddEventMemberParams{EventID: event.ID, PersonID: p.ID, Role: "editor"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	overdue := sql.NullTime{Time: time.Now().AddDate(0, 0, -3), Valid: true}
+	mine, err := q.CreateTask(ctx, db.CreateTaskParams{Title: "Mine", EventID: event.ID, Priority: 2, Category: "general", Tags: []string{},
+		OwnerID: uuid.NullUUID{UUID: me.ID, Valid: true}, DueDate: overdue})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.CreateTask(ctx, db.CreateTaskParams{Title: "Theirs", EventID: event.ID, Priority: 2, Category: "general", Tags: []string{},
+		OwnerID: uuid.NullUUID{UUID: other.ID, Valid: true}, DueDate: overdue}); err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := buildDigest(ctx, q, me, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Overdue) != 1 || d.Overdue[0].ID != mine.ID || d.Overdue[0].EventName != event.Name {
+		t.Errorf("overdue = %+v, want only my task", d.Overdue)
+	}
+	if d.Empty() {
+		t.Error("a digest with an overdue task isn't empty")
+	}
+}
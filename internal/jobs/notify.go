@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+	"github.com/navyaalva/sbf-os/internal/notify"
+)
+
+// QueueFollowUps queues each person's follow-up suggestions (the same list
+// the dashboard briefing shows). The dedupe key is the date, so however often
+// this ticks a person gets at most one follow-up message per day.
+func QueueFollowUps(q *db.Queries, n *notify.Notifier) func(context.Context) error {
+	return func(ctx context.Context) error {
+		people, err := q.ListPeople(ctx)
+		if err != nil {
+			return err
+		}
+
+		key := "follow-ups:" + time.Now().UTC().Format("2006-01-02")
+		queued := 0
+		for _, p := range people {
+			tasks, err := q.GetTasksForFollowUp(ctx, p.ID)
+			if err != nil {
+				return err
+			}
+			followUps := logic.CheckFollowUps(tasks)
+			if len(followUps) == 0 {
+				continue
+			}
+			msg, err := notify.FollowUpMessage(p.Name, followUps)
+			if err != nil {
+				return err
+			}
+			added, err := n.Enqueue(ctx, p.ID, msg, key)
+			if err != nil {
+				return err
+			}
+			queued += added
+		}
+
+		if queued > 0 {
+			log.Printf("🔔 Queued %d follow-up notification(s)", queued)
+		}
+		return nil
+	}
+}
+
+// QueueDigests sends each person their daily digest once their local clock
+// passes their digest hour. The dedupe key is the person's local date, so a
+// late or repeated tick never sends twice, and a missed hour still goes out
+// on the next tick that day. Empty digests are skipped.
+func QueueDigests(q *db.Queries, n *notify.Notifier) func(context.Context) error {
+	return func(ctx context.Context) error {
+		people, err := q.ListPeople(ctx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		queued := 0
+		for _, p := range people {
+			prefs, err := notify.Prefs(ctx, q, p.ID)
+			if err != nil {
+				return err
+			}
+			if !prefs.DigestHour.Valid {
+				continue
+			}
+			loc, err := time.LoadLocation(prefs.Timezone)
+			if err != nil {
+				loc = time.UTC
+			}
+			local := now.In(loc)
+			if local.Hour() < int(prefs.DigestHour.Int32) {
+				continue
+			}
+
+			key := "digest:" + local.Format("2006-01-02")
+			if sent, err := q.HasDelivery(ctx, db.HasDeliveryParams{PersonID: p.ID, DedupeKey: key}); err != nil {
+				return err
+			} else if sent {
+				continue
+			}
+
+			digest, err := buildDigest(ctx, q, p, local)
+			if err != nil {
+				return err
+			}
+			if digest.Empty() {
+				continue
+			}
+			msg, err := notify.DigestMessage(digest, n.BaseURL())
+			if err != nil {
+				return err
+			}
+			added, err := n.EnqueueOn(ctx, p.ID, prefs.DigestChannel.String, msg, key)
+			if err != nil {
+				return err
+			}
+			queued += added
+		}
+
+		if queued > 0 {
+			log.Printf("📰 Queued %d digest(s)", queued)
+		}
+		return nil
+	}
+}
+
+// buildDigest gathers one person's digest. now is in the person's zone, for
+// display; task_events.created_at is a zoneless UTC TIMESTAMP, and Postgres
+// drops the offset of a zoned value, so the window is computed in UTC.
+func buildDigest(ctx context.Context, q *db.Queries, p db.Person, now time.Time) (logic.Digest, error) {
+	tasks, err := q.ListMyTasks(ctx, db.ListMyTasksParams{PersonID: p.ID})
+	if err != nil {
+		return logic.Digest{}, err
+	}
+	changes, err := q.ListOwnedTaskChanges(ctx, db.ListOwnedTaskChangesParams{
+		PersonID:  p.ID,
+		CreatedAt: now.UTC().Add(-24 * time.Hour),
+	})
+	if err != nil {
+		return logic.Digest{}, err
+	}
+
+	profiles := map[uuid.UUID]logic.RiskProfile{}
+	for _, t := range tasks {
+		if _, ok := profiles[t.EventID]; ok {
+			continue
+		}
+		if profiles[t.EventID], _, err = logic.LoadRiskProfile(ctx, q, t.EventID); err != nil {
+			return logic.Digest{}, err
+		}
+	}
+	return logic.BuildDigest(p.Name, p.ID, tasks, changes, profiles, now), nil
+}
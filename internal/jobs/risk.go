@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// SnapshotRisk scores every open task of every upcoming event and stores
+// today's score. Re-running on the same day overwrites that day's row, so the
+// job can tick more often than daily.
+func SnapshotRisk(q *db.Queries) func(context.Context) error {
+	return func(ctx context.Context) error {
+		now := time.Now()
+		today := now.UTC().Truncate(24 * time.Hour)
+
+		events, err := q.ListEvents(ctx)
+		if err != nil {
+			return err
+		}
+
+		written := 0
+		for _, e := range events {
+			if e.EventDate.Before(today) {
+				continue
+			}
+
+			tasks, err := q.GetEventTasks(ctx, db.GetEventTasksParams{EventID: e.ID, Column2: false})
+			if err != nil {
+				return err
+			}
+			deps, err := q.ListEventDependencies(ctx, e.ID)
+			if err != nil {
+				return err
+			}
+			profile, _, err := logic.LoadRiskProfile(ctx, q, e.ID)
+			if err != nil {
+				return err
+			}
+
+			signals := logic.DependencySignals(tasks, deps, e.EventDate, now)
+			for _, t := range tasks {
+				scored := logic.ScoreTaskRow(t, signals[t.ID], profile)
+				breakdown, _ := json.Marshal(scored.Breakdown)
+				if err := q.UpsertRiskSnapshot(ctx, db.UpsertRiskSnapshotParams{
+					TaskID:       t.ID,
+					EventID:      e.ID,
+					SnapshotDate: today,
+					Score:        int32(scored.Score),
+					Level:        scored.RiskLevel,
+					Breakdown:    breakdown,
+				}); err != nil {
+					return err
+				}
+				written++
+			}
+		}
+
+		log.Printf("📈 Risk snapshot for %s: %d task(s)", today.Format("2006-01-02"), written)
+		return nil
+	}
+}
@@ -0,0 +1,23 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// PurgeTrash hard-deletes tasks that have sat in the trash longer than
+// retentionDays. Their task_events go with them (ON DELETE CASCADE).
+func PurgeTrash(q *db.Queries, retentionDays int) func(context.Context) error {
+	return func(ctx context.Context) error {
+		n, err := q.PurgeDeletedTasks(ctx, int32(retentionDays))
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			log.Printf("🗑 Purged %d task(s) trashed more than %d days ago", n, retentionDays)
+		}
+		return nil
+	}
+}
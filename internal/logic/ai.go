@@ -1,15 +1,18 @@
 package logic
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"log"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/ai"
 	"github.com/navyaalva/sbf-os/internal/db"
 )
 
@@ -19,108 +22,175 @@ type Subtask struct {
 	IsDone bool   `json:"is_done"`
 }
 
-// Gemini Request Structure
-type GeminiRequest struct {
-	Contents []struct {
-		Parts []struct {
-			Text string `json:"text"`
-		} `json:"parts"`
-	} `json:"contents"`
-}
-
-// Gemini Response Structure
-type GeminiResponse struct {
-	Candidates []struct {
-		Content struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		} `json:"content"`
-	} `json:"candidates"`
-}
-
-// 1. GenerateSubtasks (REAL AI VERSION)
-func GenerateSubtasks(taskTitle, description string) []byte {
-	apiKey := os.Getenv("GEMINI_API_KEY")
+// Limits on AI checklists. A reply breaking them is invalid, not truncated,
+// so the repair round-trip gets a chance to fix it.
+const (
+	MaxGeneratedSubtasks = 8
+	MaxSubtaskTitle      = 120
+	// MaxSubtasks caps a task's whole checklist; AI steps stop being added
+	// once it's full.
+	MaxSubtasks = 25
+)
 
-	// Fallback to simulation if no key (so app doesn't crash)
-	if apiKey == "" {
-		fmt.Println("⚠️ AI Warning: No GEMINI_API_KEY found. Using simulation.")
-		return generateSimulatedSubtasks(taskTitle)
+// ErrNoProvider means AI features are off (AI_PROVIDER=none).
+var ErrNoProvider = errors.New("no AI provider is configured")
+
+// SubtaskPrompt asks for a short, machine-readable checklist. Steps the task
+// already has are listed so the model doesn't repeat them.
+func SubtaskPrompt(taskTitle, description string, existing []Subtask) string {
+	have := ""
+	if len(existing) > 0 {
+		var titles []string
+		for _, st := range existing {
+			titles = append(titles, `"`+st.Title+`"`)
+		}
+		have = "It already has these steps, don't repeat them: " + strings.Join(titles, ", ")
 	}
-
-	// The Prompt
-	prompt := fmt.Sprintf(`
+	return fmt.Sprintf(`
 		You are an expert event planner. 
 		Break down this task into 3-5 concrete, actionable steps.
 		Task: "%s"
 		Context: "%s"
-		
+		%s
+		Each step title must be under %d characters.
 		Return ONLY raw JSON in this format: 
 		[{"title": "Step 1", "is_done": false}, {"title": "Step 2", "is_done": false}]
-	`, taskTitle, description)
-
-	// Prepare Request
-	reqBody, _ := json.Marshal(GeminiRequest{
-		Contents: []struct {
-			Parts []struct {
-				Text string `json:"text"`
-			} `json:"parts"`
-		}{
-			{Parts: []struct {
-				Text string `json:"text"`
-			}{{Text: prompt}}},
-		},
-	})
-
-	// UPDATED MODEL: gemini-2.5-flash
-	url := "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.5-flash:generateContent?key=" + apiKey
-	resp, err := http.Post(url, "application/json", bytes.NewBuffer(reqBody))
-	if err != nil {
-		fmt.Println("❌ AI Network Error:", err)
-		return generateSimulatedSubtasks(taskTitle)
+	`, taskTitle, description, have, MaxSubtaskTitle)
+}
+
+// SubtaskRepairPrompt sends an invalid reply back with what was wrong.
+func SubtaskRepairPrompt(reply string, problem error) string {
+	return fmt.Sprintf(`
+		Your previous reply could not be used: %s.
+		Previous reply:
+		%s
+
+		Return ONLY the corrected raw JSON array, 1-%d items, in this format:
+		[{"title": "Step 1", "is_done": false}]
+	`, problem, reply, MaxGeneratedSubtasks)
+}
+
+// ParseSubtasks validates a model reply against the checklist schema: a
+// JSON array (optionally inside a markdown code fence) of objects with a
+// non-empty "title" and an optional boolean "is_done", nothing else. Generated
+// steps always start undone.
+func ParseSubtasks(reply string) ([]Subtask, error) {
+	text := stripCodeFence(reply)
+	if !strings.HasPrefix(text, "[") {
+		return nil, errors.New("reply is not a JSON array")
 	}
-	defer resp.Body.Close()
 
-	// Parse Response
-	body, _ := io.ReadAll(resp.Body)
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.DisallowUnknownFields()
+	var raw []struct {
+		Title  *string `json:"title"`
+		IsDone *bool   `json:"is_done"`
+	}
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if dec.More() {
+		return nil, errors.New("unexpected text after the JSON array")
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("the array is empty")
+	}
+	if len(raw) > MaxGeneratedSubtasks {
+		return nil, fmt.Errorf("%d steps returned, at most %d allowed", len(raw), MaxGeneratedSubtasks)
+	}
 
-	// --- DEBUG PRINT ---
-	fmt.Println("🤖 AI Response from Google:", string(body))
-	// -------------------
+	steps := make([]Subtask, 0, len(raw))
+	for i, item := range raw {
+		if item.Title == nil || strings.TrimSpace(*item.Title) == "" {
+			return nil, fmt.Errorf("step %d has no title", i+1)
+		}
+		title := strings.TrimSpace(*item.Title)
+		if n := utf8.RuneCountInString(title); n > MaxSubtaskTitle {
+			return nil, fmt.Errorf("step %d title is %d characters, at most %d allowed", i+1, n, MaxSubtaskTitle)
+		}
+		steps = append(steps, Subtask{Title: title})
+	}
+	return steps, nil
+}
 
-	var geminiResp GeminiResponse
-	if err := json.Unmarshal(body, &geminiResp); err != nil {
-		fmt.Println("❌ AI JSON Error:", err)
-		return generateSimulatedSubtasks(taskTitle)
+// stripCodeFence unwraps a reply the model put in a markdown code fence.
+func stripCodeFence(reply string) string {
+	text := strings.TrimSpace(reply)
+	if strings.HasPrefix(text, "```") {
+		text = strings.TrimPrefix(text, "```json")
+		text = strings.TrimPrefix(text, "```")
+		text = strings.TrimSuffix(strings.TrimSpace(text), "```")
+		text = strings.TrimSpace(text)
 	}
+	return text
+}
 
-	if len(geminiResp.Candidates) == 0 {
-		fmt.Println("❌ AI Error: No candidates returned.")
-		return generateSimulatedSubtasks(taskTitle)
+// 1. GenerateSubtasks
+// Asks the provider for a checklist and validates it with ParseSubtasks. An
+// invalid reply gets one repair round-trip; if that fails too, the error is
+// returned for the caller to show. Steps matching the existing checklist are
+// left to MergeSubtasks.
+func GenerateSubtasks(ctx context.Context, p ai.LLMProvider, taskTitle, description string, existing []Subtask) ([]Subtask, error) {
+	if p == nil {
+		return nil, ErrNoProvider
 	}
 
-	text := geminiResp.Candidates[0].Content.Parts[0].Text
+	reply, err := p.Complete(ctx, SubtaskPrompt(taskTitle, description, existing))
+	if err != nil {
+		log.Printf("❌ AI Error (%s): %v", p.Name(), err)
+		return nil, fmt.Errorf("the AI provider failed: %w", err)
+	}
+	steps, err := ParseSubtasks(reply)
+	if err == nil {
+		return steps, nil
+	}
 
-	// --- NEW BULLETPROOF CLEANING LOGIC ---
-	// Instead of guessing markdown tags, we just find the JSON array brackets.
-	start := strings.Index(text, "[")
-	end := strings.LastIndex(text, "]")
+	log.Printf("⚠️ AI Warning (%s): invalid subtasks (%v), asking for a repair.", p.Name(), err)
+	repaired, rerr := p.Complete(ctx, SubtaskRepairPrompt(reply, err))
+	if rerr != nil {
+		log.Printf("❌ AI Error (%s): %v", p.Name(), rerr)
+		return nil, fmt.Errorf("the AI provider failed: %w", rerr)
+	}
+	steps, err = ParseSubtasks(repaired)
+	if err != nil {
+		log.Printf("❌ AI Error (%s): repair still invalid: %v", p.Name(), err)
+		return nil, fmt.Errorf("the AI reply was invalid: %w", err)
+	}
+	return steps, nil
+}
 
-	if start != -1 && end != -1 && end > start {
-		text = text[start : end+1]
-	} else {
-		// If we can't find brackets, something is wrong with the AI output
-		fmt.Println("❌ AI Error: Could not find JSON array in response.")
-		return generateSimulatedSubtasks(taskTitle)
+// MergeSubtasks appends generated steps that aren't already on the
+// checklist (ignoring case, spacing and trailing punctuation), up to
+// MaxSubtasks in total. It returns the merged list and how many were added.
+func MergeSubtasks(existing, generated []Subtask) ([]Subtask, int) {
+	seen := map[string]bool{}
+	for _, st := range existing {
+		seen[subtaskKey(st.Title)] = true
+	}
+	merged := existing
+	added := 0
+	for _, st := range generated {
+		if len(merged) >= MaxSubtasks {
+			break
+		}
+		key := subtaskKey(st.Title)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, st)
+		added++
 	}
-	// ---------------------------------------
+	return merged, added
+}
 
-	return []byte(text)
+func subtaskKey(title string) string {
+	key := strings.ToLower(strings.Join(strings.Fields(title), " "))
+	return strings.TrimRight(key, ".!?;:")
 }
 
-// Fallback logic
-func generateSimulatedSubtasks(title string) []byte {
+// SimulatedSubtasks are placeholder steps for when no provider is configured.
+func SimulatedSubtasks(title string) []Subtask {
 	lowerTitle := strings.ToLower(title)
 	var steps []Subtask
 
@@ -144,13 +214,23 @@ func generateSimulatedSubtasks(title string) []byte {
 		}
 	}
 
-	b, _ := json.Marshal(steps)
-	return b
+	return steps
+}
+
+// FollowUp is one suggested nudge: who to chase and about what.
+type FollowUp struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	TaskTitle string    `json:"task_title"`
+	Assignee  string    `json:"assignee"`
+	DaysUntil int       `json:"days_until"`
+	Overdue   bool      `json:"overdue"`
 }
 
 // 2. CheckFollowUps
-func CheckFollowUps(tasks []db.Task) []string {
-	var reminders []string
+// Returns plain data; the dashboard and notification channels each render it
+// their own way.
+func CheckFollowUps(tasks []db.Task) []FollowUp {
+	var reminders []FollowUp
 
 	for _, t := range tasks {
 		if !t.DueDate.Valid || t.AssigneeText.String == "" {
@@ -158,12 +238,14 @@ func CheckFollowUps(tasks []db.Task) []string {
 		}
 
 		daysUntil := int(time.Until(t.DueDate.Time).Hours() / 24)
-		assignee := t.AssigneeText.String
-
-		if daysUntil < 0 {
-			reminders = append(reminders, fmt.Sprintf("🚨 <strong>%s</strong> is overdue on '%s'", assignee, t.Title))
-		} else if daysUntil <= 2 {
-			reminders = append(reminders, fmt.Sprintf("👉 Nudge <strong>%s</strong> about '%s' (Due in %d days)", assignee, t.Title, daysUntil))
+		if daysUntil <= 2 {
+			reminders = append(reminders, FollowUp{
+				TaskID:    t.ID,
+				TaskTitle: t.Title,
+				Assignee:  t.AssigneeText.String,
+				DaysUntil: daysUntil,
+				Overdue:   daysUntil < 0,
+			})
 		}
 	}
 	return reminders
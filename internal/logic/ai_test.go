@@ -0,0 +1,180 @@
+package logic
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/navyaalva/sbf-os/internal/ai"
+)
+
+func TestParseSubtasks(t *testing.T) {
+	long := strings.Repeat("x", MaxSubtaskTitle+1)
+	tests := []struct {
+		name    string
+		reply   string
+		want    []Subtask
+		wantErr string
+	}{
+		{
+			name:  "plain array",
+			reply: `[{"title": "Book venue", "is_done": false}, {"title": "Send invites"}]`,
+			want:  []Subtask{{Title: "Book venue"}, {Title: "Send invites"}},
+		},
+		{
+			name:  "code fence",
+			reply: "```json\n[{\"title\": \"Book venue\"}]\n```",
+			want:  []Subtask{{Title: "Book venue"}},
+		},
+		{
+			name:  "generated steps start undone",
+			reply: `[{"title": "  Book venue  ", "is_done": true}]`,
+			want:  []Subtask{{Title: "Book venue"}},
+		},
+		{name: "prose around the array", reply: `Sure! [{"title": "Book venue"}]`, wantErr: "not a JSON array"},
+		{name: "trailing text", reply: `[{"title": "Book venue"}] hope this helps`, wantErr: "after the JSON array"},
+		{name: "unknown field", reply: `[{"title": "Book venue", "owner": "Sam"}]`, wantErr: "invalid JSON"},
+		{name: "wrong type", reply: `[{"title": "Book venue", "is_done": "no"}]`, wantErr: "invalid JSON"},
+		{name: "empty", reply: `[]`, wantErr: "empty"},
+		{name: "missing title", reply: `[{"is_done": false}]`, wantErr: "step 1 has no title"},
+		{name: "blank title", reply: `[{"title": "Book venue"}, {"title": "  "}]`, wantErr: "step 2 has no title"},
+		{name: "title too long", reply: `[{"title": "` + long + `"}]`, wantErr: "step 1 title is"},
+		{name: "too many steps", reply: `[` + strings.Repeat(`{"title": "a"},`, MaxGeneratedSubtasks) + `{"title": "a"}]`, wantErr: "at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSubtasks(tt.reply)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseSubtasks() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseSubtasks() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSubtasks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeSubtasks(t *testing.T) {
+	full := make([]Subtask, MaxSubtasks-1)
+	for i := range full {
+		full[i] = Subtask{Title: "step " + strings.Repeat("i", i+1)}
+	}
+	tests := []struct {
+		name      string
+		existing  []Subtask
+		generated []Subtask
+		wantAdded int
+		wantLast  string
+	}{
+		{
+			name:      "appends new steps",
+			existing:  []Subtask{{Title: "Book venue", IsDone: true}},
+			generated: []Subtask{{Title: "Send invites"}, {Title: "Order cake"}},
+			wantAdded: 2,
+			wantLast:  "Order cake",
+		},
+		{
+			name:      "skips case, spacing and punctuation duplicates",
+			existing:  []Subtask{{Title: "Book venue"}},
+			generated: []Subtask{{Title: "book  VENUE."}, {Title: "Send invites"}},
+			wantAdded: 1,
+			wantLast:  "Send invites",
+		},
+		{
+			name:      "skips duplicates within the reply",
+			generated: []Subtask{{Title: "Send invites"}, {Title: "send invites!"}},
+			wantAdded: 1,
+			wantLast:  "Send invites",
+		},
+		{
+			name:      "stops at the checklist cap",
+			existing:  full,
+			generated: []Subtask{{Title: "Send invites"}, {Title: "Order cake"}},
+			wantAdded: 1,
+			wantLast:  "Send invites",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, added := MergeSubtasks(tt.existing, tt.generated)
+			if added != tt.wantAdded {
+				t.Errorf("added = %d, want %d", added, tt.wantAdded)
+			}
+			if len(merged) != len(tt.existing)+tt.wantAdded {
+				t.Fatalf("merged has %d steps, want %d", len(merged), len(tt.existing)+tt.wantAdded)
+			}
+			if last := merged[len(merged)-1].Title; last != tt.wantLast {
+				t.Errorf("last step = %q, want %q", last, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestGenerateSubtasks(t *testing.T) {
+	const title, desc = "Book venue", "200 guests"
+	prompt := SubtaskPrompt(title, desc, nil)
+	bad := `Here you go: [{"title": "Visit halls"}]`
+	badErr := func() error { _, err := ParseSubtasks(bad); return err }()
+	repair := SubtaskRepairPrompt(bad, badErr)
+
+	tests := []struct {
+		name    string
+		replies map[string]string
+		want    []Subtask
+		wantErr string
+	}{
+		{
+			name:    "valid first reply",
+			replies: map[string]string{prompt: `[{"title": "Visit halls"}, {"title": "Sign contract"}]`},
+			want:    []Subtask{{Title: "Visit halls"}, {Title: "Sign contract"}},
+		},
+		{
+			name: "repaired reply",
+			replies: map[string]string{
+				prompt: bad,
+				repair: "```json\n[{\"title\": \"Visit halls\"}]\n```",
+			},
+			want: []Subtask{{Title: "Visit halls"}},
+		},
+		{
+			name:    "repair still invalid",
+			replies: map[string]string{prompt: bad, repair: `[]`},
+			wantErr: "the AI reply was invalid",
+		},
+		{
+			name:    "provider fails on the repair",
+			replies: map[string]string{prompt: bad},
+			wantErr: "the AI provider failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := ai.NewFixtureProvider(tt.replies)
+			got, err := GenerateSubtasks(context.Background(), p, title, desc, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("GenerateSubtasks() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateSubtasks() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GenerateSubtasks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := GenerateSubtasks(context.Background(), nil, title, desc, nil); !errors.Is(err, ErrNoProvider) {
+		t.Errorf("GenerateSubtasks(nil provider) error = %v, want ErrNoProvider", err)
+	}
+}
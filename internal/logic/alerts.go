@@ -0,0 +1,33 @@
+package logic
+
+// AlertRules are the risk checks worth an inbox entry. The rest only move the
+// score: they're either expected (priority, due soon) or chosen by a person
+// (blocked).
+var AlertRules = map[string]bool{
+	RuleOverdue:        true,
+	RuleStaleLong:      true,
+	RuleBehindSchedule: true,
+}
+
+// TouchResolvedRules clear as soon as someone updates the task, since the
+// update itself is the fix. Other alerts wait for the next scan.
+var TouchResolvedRules = []string{RuleStale, RuleStaleLong}
+
+// TouchesTask reports whether a ledger entry of this type comes with a fresh
+// tasks.last_update_at (or created_at). Only those may resolve staleness
+// alerts; the rest (dependency edits, trash moves) leave the task as stale
+// as it was, and the next scan would just reopen the alert.
+func TouchesTask(eventType string) bool {
+	return eventType == EventCreated || eventType == EventUpdated
+}
+
+// TaskAlerts picks the alert-worthy factors out of a scored task.
+func TaskAlerts(st ScoredTask) []RiskFactor {
+	var alerts []RiskFactor
+	for _, f := range st.Breakdown {
+		if AlertRules[f.Rule] {
+			alerts = append(alerts, f)
+		}
+	}
+	return alerts
+}
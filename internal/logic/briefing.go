@@ -0,0 +1,474 @@
+package logic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/ai"
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// BriefingSourceRules marks a briefing written by RulesBriefing rather than a model.
+const BriefingSourceRules = "rules"
+
+// Caps keep the prompt bounded on busy events and the report readable.
+const (
+	briefingMaxChanges = 150
+	briefingMaxTasks   = 40
+	briefingMaxItems   = 8
+	briefingMaxOwners  = 5
+	briefingMaxText    = 300
+)
+
+// BriefingItem is one line of a briefing section, optionally about a task.
+type BriefingItem struct {
+	Text   string `json:"text"`
+	TaskID string `json:"task_id,omitempty"`
+}
+
+// OwnerSuggestion proposes a team member for a task nobody owns.
+type OwnerSuggestion struct {
+	TaskID string `json:"task_id,omitempty"`
+	Task   string `json:"task"`
+	Person string `json:"person"`
+	Reason string `json:"reason"`
+}
+
+// Briefing is the structured report stored in event_briefings.report.
+type Briefing struct {
+	Summary         string            `json:"summary"`
+	Wins            []BriefingItem    `json:"wins"`
+	Risks           []BriefingItem    `json:"risks"`
+	DecisionsNeeded []BriefingItem    `json:"decisions_needed"`
+	SuggestedOwners []OwnerSuggestion `json:"suggested_owners"`
+	// Note explains a fallback, e.g. the model's reply couldn't be used.
+	Note string `json:"note,omitempty"`
+}
+
+// BriefingInput is everything a briefing is written from.
+type BriefingInput struct {
+	EventName string
+	EventDate time.Time
+	Days      int
+	// Now is when the briefing is written for. It shows up in the prompt, so
+	// tests pin it to replay recorded fixtures.
+	Now       time.Time
+	Changes   []db.ListEventTaskChangesRow
+	Tasks     []ScoredTask // open tasks from ScoreEventTasks, highest risk first
+	BlockedBy map[uuid.UUID][]string
+	Members   []db.ListEventMembersRow
+}
+
+// GenerateBriefing asks the provider for a briefing and returns it with the
+// source to store. With no provider, or when the call or its reply fails
+// validation, it falls back to RulesBriefing and says why in Note.
+func GenerateBriefing(ctx context.Context, p ai.LLMProvider, in BriefingInput) (Briefing, string) {
+	if p == nil {
+		return RulesBriefing(in), BriefingSourceRules
+	}
+
+	text, err := p.Complete(ctx, BriefingPrompt(in))
+	if err != nil {
+		log.Printf("❌ AI Error (%s): %v", p.Name(), err)
+		b := RulesBriefing(in)
+		b.Note = "The AI provider failed, so this briefing was built from rules."
+		return b, BriefingSourceRules
+	}
+	b, err := ParseBriefing(text, in)
+	if err != nil {
+		log.Printf("❌ AI Error (%s): %v", p.Name(), err)
+		b := RulesBriefing(in)
+		b.Note = "The AI reply couldn't be used, so this briefing was built from rules."
+		return b, BriefingSourceRules
+	}
+	return b, p.Name()
+}
+
+// briefingFacts is the JSON the model sees. Field names double as the
+// vocabulary the prompt refers to.
+type briefingFacts struct {
+	Event       string         `json:"event"`
+	EventDate   string         `json:"event_date"`
+	DaysToEvent int            `json:"days_to_event"`
+	WindowDays  int            `json:"window_days"`
+	Activity    []activityFact `json:"activity"`
+	OpenTasks   []taskFact     `json:"open_tasks"`
+	Team        []memberFact   `json:"team"`
+}
+
+type activityFact struct {
+	TaskID    string   `json:"task_id"`
+	Task      string   `json:"task"`
+	Actor     string   `json:"actor"`
+	Type      string   `json:"type"`
+	Fields    []string `json:"fields,omitempty"`
+	Completed bool     `json:"completed,omitempty"`
+	At        string   `json:"at"`
+}
+
+type taskFact struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Status    string   `json:"status"`
+	Priority  int32    `json:"priority"`
+	Owner     string   `json:"owner,omitempty"`
+	Due       string   `json:"due,omitempty"`
+	Risk      int      `json:"risk_score"`
+	Level     string   `json:"risk_level"`
+	Reasons   []string `json:"risk_reasons,omitempty"`
+	BlockedBy []string `json:"blocked_by,omitempty"`
+}
+
+type memberFact struct {
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	OpenTasks int    `json:"open_tasks"`
+}
+
+func buildBriefingFacts(in BriefingInput) briefingFacts {
+	f := briefingFacts{
+		Event:       in.EventName,
+		EventDate:   in.EventDate.Format("2006-01-02"),
+		DaysToEvent: dayOffset(dateOnly(in.Now), dateOnly(in.EventDate)),
+		WindowDays:  in.Days,
+		Activity:    []activityFact{},
+		OpenTasks:   []taskFact{},
+		Team:        []memberFact{},
+	}
+
+	// The most recent activity matters most, so trim from the front.
+	changes := in.Changes
+	if len(changes) > briefingMaxChanges {
+		changes = changes[len(changes)-briefingMaxChanges:]
+	}
+	for _, c := range changes {
+		fields, completed := changedFields(c)
+		f.Activity = append(f.Activity, activityFact{
+			TaskID:    c.TaskID.String(),
+			Task:      c.TaskTitle,
+			Actor:     actorName(c),
+			Type:      c.EventType,
+			Fields:    fields,
+			Completed: completed,
+			At:        c.CreatedAt.Format("2006-01-02 15:04"),
+		})
+	}
+
+	for i, st := range in.Tasks {
+		if i == briefingMaxTasks {
+			break
+		}
+		t, ok := st.Task.(db.GetEventTasksRow)
+		if !ok {
+			continue
+		}
+		tf := taskFact{
+			ID:        t.ID.String(),
+			Title:     t.Title,
+			Status:    t.Status,
+			Priority:  t.Priority,
+			Owner:     taskOwner(t),
+			Risk:      st.Score,
+			Level:     st.RiskLevel,
+			Reasons:   st.Reasons,
+			BlockedBy: in.BlockedBy[t.ID],
+		}
+		if t.DueDate.Valid {
+			tf.Due = t.DueDate.Time.Format("2006-01-02")
+		}
+		f.OpenTasks = append(f.OpenTasks, tf)
+	}
+
+	load := openLoad(in.Tasks)
+	for _, m := range in.Members {
+		f.Team = append(f.Team, memberFact{Name: m.Name, Role: m.Role, OpenTasks: load[m.PersonID]})
+	}
+	return f
+}
+
+// BriefingPrompt asks for a briefing grounded in the facts, as raw JSON.
+func BriefingPrompt(in BriefingInput) string {
+	facts, _ := json.MarshalIndent(buildBriefingFacts(in), "", "  ")
+	return fmt.Sprintf(`
+		You are the lead planner for the event "%s".
+		Write today's briefing for the team from the facts below.
+		Use only these facts. Refer to tasks by their id.
+		- wins: what got done or moved forward in the last %d days
+		- risks: open tasks most likely to slip, and why
+		- decisions_needed: questions someone has to decide on to unblock work
+		- suggested_owners: people from "team" (never viewers) for open tasks without an owner
+		Keep each text under one sentence and each list to at most %d items.
+
+		Facts (JSON):
+		%s
+
+		Return ONLY raw JSON in this format:
+		{"summary": "One or two sentences.", "wins": [{"text": "...", "task_id": "..."}], "risks": [{"text": "...", "task_id": "..."}], "decisions_needed": [{"text": "...", "task_id": "..."}], "suggested_owners": [{"task_id": "...", "task": "...", "person": "...", "reason": "..."}]}
+	`, in.EventName, in.Days, briefingMaxItems, facts)
+}
+
+// ParseBriefing validates a model reply against the input. The reply must be
+// exactly the JSON object the prompt asks for (optionally inside a markdown
+// code fence), with no other fields. Unknown task IDs are then dropped from
+// items, owner suggestions must name a non-viewer on the team and an open
+// task, and long lists and texts are trimmed. A reply with nothing usable is
+// an error.
+func ParseBriefing(text string, in BriefingInput) (Briefing, error) {
+	text = stripCodeFence(text)
+	if !strings.HasPrefix(text, "{") {
+		return Briefing{}, errors.New("briefing: reply is not a JSON object")
+	}
+
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.DisallowUnknownFields()
+	var raw struct {
+		Summary         string            `json:"summary"`
+		Wins            []BriefingItem    `json:"wins"`
+		Risks           []BriefingItem    `json:"risks"`
+		DecisionsNeeded []BriefingItem    `json:"decisions_needed"`
+		SuggestedOwners []OwnerSuggestion `json:"suggested_owners"`
+	}
+	if err := dec.Decode(&raw); err != nil {
+		return Briefing{}, fmt.Errorf("briefing: invalid JSON: %v", err)
+	}
+	if dec.More() {
+		return Briefing{}, errors.New("briefing: unexpected text after the JSON object")
+	}
+
+	titles := map[string]string{}
+	for _, c := range in.Changes {
+		titles[c.TaskID.String()] = c.TaskTitle
+	}
+	// Only open tasks can be handed to someone.
+	open := map[string]string{}
+	for _, st := range in.Tasks {
+		if t, ok := st.Task.(db.GetEventTasksRow); ok {
+			titles[t.ID.String()] = t.Title
+			open[t.ID.String()] = t.Title
+		}
+	}
+	people := map[string]string{}
+	for _, m := range in.Members {
+		if m.Role != "viewer" {
+			people[strings.ToLower(m.Name)] = m.Name
+		}
+	}
+
+	b := Briefing{
+		Summary:         clip(raw.Summary),
+		Wins:            cleanItems(raw.Wins, titles),
+		Risks:           cleanItems(raw.Risks, titles),
+		DecisionsNeeded: cleanItems(raw.DecisionsNeeded, titles),
+	}
+	for _, s := range raw.SuggestedOwners {
+		title, known := open[strings.TrimSpace(s.TaskID)]
+		person, member := people[strings.ToLower(strings.TrimSpace(s.Person))]
+		if !known || !member || len(b.SuggestedOwners) == briefingMaxOwners {
+			continue
+		}
+		b.SuggestedOwners = append(b.SuggestedOwners, OwnerSuggestion{
+			TaskID: strings.TrimSpace(s.TaskID),
+			Task:   title,
+			Person: person,
+			Reason: clip(s.Reason),
+		})
+	}
+
+	if b.Summary == "" && len(b.Wins)+len(b.Risks)+len(b.DecisionsNeeded)+len(b.SuggestedOwners) == 0 {
+		return Briefing{}, errors.New("briefing: reply had no usable content")
+	}
+	return b, nil
+}
+
+func cleanItems(items []BriefingItem, titles map[string]string) []BriefingItem {
+	var out []BriefingItem
+	for _, it := range items {
+		text := clip(it.Text)
+		if text == "" {
+			continue
+		}
+		id := strings.TrimSpace(it.TaskID)
+		if _, ok := titles[id]; !ok {
+			id = ""
+		}
+		out = append(out, BriefingItem{Text: text, TaskID: id})
+		if len(out) == briefingMaxItems {
+			break
+		}
+	}
+	return out
+}
+
+func clip(s string) string {
+	s = strings.TrimSpace(s)
+	if r := []rune(s); len(r) > briefingMaxText {
+		s = string(r[:briefingMaxText]) + "…"
+	}
+	return s
+}
+
+// RulesBriefing writes a briefing without a model: completions are wins,
+// high-risk tasks are risks, blocked or ownerless urgent work needs a
+// decision, and unowned tasks go to the editor with the lightest load.
+// The same input always gives the same briefing.
+func RulesBriefing(in BriefingInput) Briefing {
+	b := Briefing{}
+
+	touched := map[uuid.UUID]bool{}
+	done := map[uuid.UUID]bool{}
+	for _, c := range in.Changes {
+		touched[c.TaskID] = true
+		if _, completed := changedFields(c); completed && !done[c.TaskID] {
+			done[c.TaskID] = true
+			if len(b.Wins) < briefingMaxItems {
+				b.Wins = append(b.Wins, BriefingItem{
+					Text:   fmt.Sprintf("'%s' was completed by %s.", c.TaskTitle, actorName(c)),
+					TaskID: c.TaskID.String(),
+				})
+			}
+		}
+	}
+
+	high := 0
+	var unowned []db.GetEventTasksRow
+	for _, st := range in.Tasks {
+		t, ok := st.Task.(db.GetEventTasksRow)
+		if !ok {
+			continue
+		}
+		if st.RiskLevel == "high" {
+			high++
+			if len(st.Reasons) > 0 && len(b.Risks) < briefingMaxItems {
+				b.Risks = append(b.Risks, BriefingItem{
+					Text:   fmt.Sprintf("'%s': %s.", t.Title, strings.Join(st.Reasons, ", ")),
+					TaskID: t.ID.String(),
+				})
+			}
+		}
+
+		if len(b.DecisionsNeeded) < briefingMaxItems {
+			switch {
+			case t.Status == "blocked":
+				text := fmt.Sprintf("'%s' is blocked. Decide how to unblock it.", t.Title)
+				if ups := in.BlockedBy[t.ID]; len(ups) > 0 {
+					text = fmt.Sprintf("'%s' is blocked, waiting on %s. Decide how to unblock it.", t.Title, strings.Join(ups, ", "))
+				}
+				b.DecisionsNeeded = append(b.DecisionsNeeded, BriefingItem{Text: text, TaskID: t.ID.String()})
+			case taskOwner(t) == "" && (st.RiskLevel == "high" || t.Priority >= 4):
+				b.DecisionsNeeded = append(b.DecisionsNeeded, BriefingItem{
+					Text:   fmt.Sprintf("'%s' is urgent and has no owner. Decide who takes it.", t.Title),
+					TaskID: t.ID.String(),
+				})
+			}
+		}
+
+		if taskOwner(t) == "" {
+			unowned = append(unowned, t)
+		}
+	}
+
+	b.SuggestedOwners = suggestOwners(unowned, in)
+
+	toEvent := dayOffset(dateOnly(in.Now), dateOnly(in.EventDate))
+	var when string
+	switch {
+	case toEvent > 1:
+		when = fmt.Sprintf("The event is in %d days.", toEvent)
+	case toEvent == 1:
+		when = "The event is tomorrow."
+	case toEvent == 0:
+		when = "The event is today."
+	default:
+		when = fmt.Sprintf("The event was %d days ago.", -toEvent)
+	}
+	b.Summary = fmt.Sprintf("%d update(s) on %d task(s) in the last %d day(s), %d completed. %d open task(s), %d at high risk. %s",
+		len(in.Changes), len(touched), in.Days, len(done), len(in.Tasks), high, when)
+	return b
+}
+
+// suggestOwners hands unowned tasks, riskiest first, to the non-viewer with
+// the fewest open tasks, counting each suggestion toward their load.
+func suggestOwners(unowned []db.GetEventTasksRow, in BriefingInput) []OwnerSuggestion {
+	var candidates []db.ListEventMembersRow
+	for _, m := range in.Members {
+		if m.Role != "viewer" {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	load := openLoad(in.Tasks)
+	var out []OwnerSuggestion
+	for _, t := range unowned {
+		if len(out) == briefingMaxOwners {
+			break
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if load[candidates[i].PersonID] != load[candidates[j].PersonID] {
+				return load[candidates[i].PersonID] < load[candidates[j].PersonID]
+			}
+			return candidates[i].Name < candidates[j].Name
+		})
+		pick := candidates[0]
+		out = append(out, OwnerSuggestion{
+			TaskID: t.ID.String(),
+			Task:   t.Title,
+			Person: pick.Name,
+			Reason: fmt.Sprintf("Lightest load on the team (%d open task(s)).", load[pick.PersonID]),
+		})
+		load[pick.PersonID]++
+	}
+	return out
+}
+
+// openLoad counts open tasks per owner.
+func openLoad(tasks []ScoredTask) map[uuid.UUID]int {
+	load := map[uuid.UUID]int{}
+	for _, st := range tasks {
+		if t, ok := st.Task.(db.GetEventTasksRow); ok && t.OwnerID.Valid {
+			load[t.OwnerID.UUID]++
+		}
+	}
+	return load
+}
+
+// changedFields lists the fields a task_events row touched, and whether it
+// moved the task to done.
+func changedFields(c db.ListEventTaskChangesRow) ([]string, bool) {
+	var changes []Change
+	_ = json.Unmarshal(c.Changes, &changes)
+
+	var fields []string
+	completed := false
+	for _, ch := range changes {
+		fields = append(fields, ch.Field)
+		if ch.Field == "status" && ch.To == "done" {
+			completed = true
+		}
+	}
+	return fields, completed
+}
+
+func actorName(c db.ListEventTaskChangesRow) string {
+	if c.ActorName.Valid {
+		return c.ActorName.String
+	}
+	return "System"
+}
+
+func taskOwner(t db.GetEventTasksRow) string {
+	if t.OwnerName.Valid {
+		return t.OwnerName.String
+	}
+	return t.AssigneeText.String
+}
@@ -0,0 +1,193 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/ai"
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+func briefingTestInput(now time.Time) BriefingInput {
+	task := db.GetEventTasksRow{ID: uuid.MustParse("11111111-1111-1111-1111-111111111111"), Title: "Book venue", Status: "backlog"}
+	return BriefingInput{
+		EventName: "Spring Fair",
+		EventDate: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+		Days:      7,
+		Now:       now,
+		Tasks:     []ScoredTask{{Task: task, Score: 60, RiskLevel: "high"}},
+		Members:   []db.ListEventMembersRow{{Name: "Sam", Role: "editor"}, {Name: "Viv", Role: "viewer"}},
+	}
+}
+
+func TestParseBriefing(t *testing.T) {
+	in := briefingTestInput(time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC))
+	const taskID = "11111111-1111-1111-1111-111111111111"
+	tests := []struct {
+		name       string
+		reply      string
+		wantErr    string
+		wantRisks  int
+		wantOwners int
+	}{
+		{
+			name:       "valid",
+			reply:      `{"summary": "On track.", "risks": [{"text": "Venue unbooked.", "task_id": "` + taskID + `"}], "suggested_owners": [{"task_id": "` + taskID + `", "person": "sam", "reason": "Free."}]}`,
+			wantRisks:  1,
+			wantOwners: 1,
+		},
+		{
+			name:      "code fence",
+			reply:     "```json\n{\"summary\": \"On track.\", \"risks\": [{\"text\": \"Venue unbooked.\"}]}\n```",
+			wantRisks: 1,
+		},
+		{
+			name:  "viewers and unknown tasks aren't suggested",
+			reply: `{"summary": "On track.", "suggested_owners": [{"task_id": "` + taskID + `", "person": "Viv"}, {"task_id": "nope", "person": "Sam"}]}`,
+		},
+		{name: "prose around the object", reply: `Here it is: {"summary": "On track."}`, wantErr: "not a JSON object"},
+		{name: "two objects", reply: `{"summary": "On track."} {"summary": "Again."}`, wantErr: "after the JSON object"},
+		{name: "unknown field", reply: `{"summary": "On track.", "mood": "good"}`, wantErr: "invalid JSON"},
+		{name: "model sets the note", reply: `{"summary": "On track.", "note": "hi"}`, wantErr: "invalid JSON"},
+		{name: "nothing usable", reply: `{"summary": " ", "wins": [{"text": ""}]}`, wantErr: "no usable content"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ParseBriefing(tt.reply, in)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseBriefing() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseBriefing() error = %v", err)
+			}
+			if len(b.Risks) != tt.wantRisks || len(b.SuggestedOwners) != tt.wantOwners {
+				t.Errorf("got %d risks and %d owners, want %d and %d", len(b.Risks), len(b.SuggestedOwners), tt.wantRisks, tt.wantOwners)
+			}
+		})
+	}
+}
+
+func TestGenerateBriefingReplaysFixture(t *testing.T) {
+	pinned := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	p := ai.NewFixtureProvider(map[string]string{
+		BriefingPrompt(briefingTestInput(pinned)): `{"summary": "Recorded."}`,
+	})
+
+	b, source := GenerateBriefing(context.Background(), p, briefingTestInput(pinned))
+	if source != ai.ProviderFixture || b.Summary != "Recorded." {
+		t.Fatalf("pinned date: got %q from %q, want the recorded briefing", b.Summary, source)
+	}
+
+	// A different day is a different prompt, so it falls back to rules.
+	_, source = GenerateBriefing(context.Background(), p, briefingTestInput(pinned.AddDate(0, 0, 1)))
+	if source != BriefingSourceRules {
+		t.Errorf("next day: source = %q, want %q", source, BriefingSourceRules)
+	}
+}
+
+func TestRulesBriefing(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.UTC)
+	ann, bea := uuid.New(), uuid.New()
+	done, renamed := uuid.New(), uuid.New()
+	risky := db.GetEventTasksRow{ID: uuid.New(), Title: "Book venue", Status: "in_progress", Priority: 3,
+		OwnerID: uuid.NullUUID{UUID: ann, Valid: true}, OwnerName: sql.NullString{String: "Ann", Valid: true}}
+	blocked := db.GetEventTasksRow{ID: uuid.New(), Title: "Print menus", Status: "blocked", Priority: 2,
+		AssigneeText: sql.NullString{String: "Vendor Co", Valid: true}}
+	urgent := db.GetEventTasksRow{ID: uuid.New(), Title: "Hire security", Status: "backlog", Priority: 4}
+	minor := db.GetEventTasksRow{ID: uuid.New(), Title: "Buy balloons", Status: "backlog", Priority: 1}
+
+	completed := json.RawMessage(`[{"field": "status", "from": "in_progress", "to": "done"}]`)
+	in := BriefingInput{
+		EventName: "Spring Fair",
+		Days:      7,
+		Now:       now,
+		Changes: []db.ListEventTaskChangesRow{
+			{TaskID: done, TaskTitle: "Send invites", EventType: EventUpdated, Changes: completed, ActorName: sql.NullString{String: "Sam", Valid: true}},
+			{TaskID: renamed, TaskTitle: "Order cake", EventType: EventUpdated, Changes: json.RawMessage(`[{"field": "title", "from": "Cake", "to": "Order cake"}]`)},
+			// A second completion of the same task is still one win.
+			{TaskID: done, TaskTitle: "Send invites", EventType: EventUpdated, Changes: completed},
+		},
+		Tasks: []ScoredTask{
+			{Task: risky, Score: 70, RiskLevel: "high", Reasons: []string{"due in 2 days"}},
+			{Task: blocked, Score: 40, RiskLevel: "med"},
+			{Task: urgent, Score: 20, RiskLevel: "low"},
+			{Task: minor, Score: 5, RiskLevel: "low"},
+		},
+		BlockedBy: map[uuid.UUID][]string{blocked.ID: {"Pick a menu"}},
+		Members: []db.ListEventMembersRow{
+			{PersonID: uuid.New(), Name: "Aaron", Role: "viewer"},
+			{PersonID: ann, Name: "Ann", Role: "editor"},
+			{PersonID: bea, Name: "Bea", Role: "owner"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		eventDate time.Time
+		wantWhen  string
+	}{
+		{name: "upcoming", eventDate: now.AddDate(0, 0, 10), wantWhen: "The event is in 10 days."},
+		{name: "tomorrow", eventDate: now.AddDate(0, 0, 1), wantWhen: "The event is tomorrow."},
+		{name: "today", eventDate: now.Add(-6 * time.Hour), wantWhen: "The event is today."},
+		{name: "past", eventDate: now.AddDate(0, 0, -3), wantWhen: "The event was 3 days ago."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := in
+			in.EventDate = tt.eventDate
+			b := RulesBriefing(in)
+
+			if !strings.HasSuffix(b.Summary, tt.wantWhen) {
+				t.Errorf("summary %q, want it to end with %q", b.Summary, tt.wantWhen)
+			}
+			if !strings.HasPrefix(b.Summary, "3 update(s) on 2 task(s) in the last 7 day(s), 1 completed. 4 open task(s), 1 at high risk.") {
+				t.Errorf("summary counts wrong: %q", b.Summary)
+			}
+
+			if len(b.Wins) != 1 || b.Wins[0].TaskID != done.String() || !strings.Contains(b.Wins[0].Text, "completed by Sam") {
+				t.Errorf("wins = %+v, want one completion by Sam", b.Wins)
+			}
+			if len(b.Risks) != 1 || b.Risks[0].TaskID != risky.ID.String() || !strings.Contains(b.Risks[0].Text, "due in 2 days") {
+				t.Errorf("risks = %+v, want only the high-risk task with its reason", b.Risks)
+			}
+
+			if len(b.DecisionsNeeded) != 2 {
+				t.Fatalf("decisions = %+v, want the blocked and the unowned urgent task", b.DecisionsNeeded)
+			}
+			if d := b.DecisionsNeeded[0]; d.TaskID != blocked.ID.String() || !strings.Contains(d.Text, "waiting on Pick a menu") {
+				t.Errorf("first decision = %+v, want the blocked task and its blocker", d)
+			}
+			if d := b.DecisionsNeeded[1]; d.TaskID != urgent.ID.String() || !strings.Contains(d.Text, "no owner") {
+				t.Errorf("second decision = %+v, want the unowned urgent task", d)
+			}
+
+			// Bea has no open tasks, so she gets the first one; then Ann and
+			// Bea tie at one each and the name decides. Aaron is a viewer.
+			want := []OwnerSuggestion{
+				{TaskID: urgent.ID.String(), Person: "Bea"},
+				{TaskID: minor.ID.String(), Person: "Ann"},
+			}
+			if len(b.SuggestedOwners) != len(want) {
+				t.Fatalf("suggestions = %+v, want %d", b.SuggestedOwners, len(want))
+			}
+			for i, w := range want {
+				if got := b.SuggestedOwners[i]; got.TaskID != w.TaskID || got.Person != w.Person {
+					t.Errorf("suggestion %d = %s for %s, want %s for %s", i, got.Person, got.TaskID, w.Person, w.TaskID)
+				}
+			}
+		})
+	}
+
+	if b := RulesBriefing(BriefingInput{Now: now, EventDate: now}); len(b.SuggestedOwners) != 0 {
+		t.Errorf("no team: suggestions = %+v, want none", b.SuggestedOwners)
+	}
+}
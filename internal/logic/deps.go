@@ -0,0 +1,100 @@
+package logic
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// Dependency event types. They don't touch task columns, so replay skips them.
+const (
+	EventDependencyAdded   = "DEPENDENCY_ADDED"
+	EventDependencyRemoved = "DEPENDENCY_REMOVED"
+)
+
+// DepGraph maps a task to the tasks it waits on (its upstream).
+type DepGraph map[uuid.UUID][]uuid.UUID
+
+// BuildDepGraph turns an event's dependency rows into an adjacency list.
+func BuildDepGraph(rows []db.ListEventDependenciesRow) DepGraph {
+	g := DepGraph{}
+	for _, r := range rows {
+		g[r.TaskID] = append(g[r.TaskID], r.DependencyID)
+	}
+	return g
+}
+
+// CyclePath reports whether making taskID depend on dependsOn would close a
+// loop. If so it returns the offending chain, starting at dependsOn and ending
+// back at taskID; otherwise nil.
+func (g DepGraph) CyclePath(taskID, dependsOn uuid.UUID) []uuid.UUID {
+	if taskID == dependsOn {
+		return []uuid.UUID{taskID, taskID}
+	}
+
+	// Walk upstream from the new dependency; reaching taskID means taskID
+	// already feeds into it.
+	prev := map[uuid.UUID]uuid.UUID{}
+	seen := map[uuid.UUID]bool{dependsOn: true}
+	queue := []uuid.UUID{dependsOn}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, up := range g[cur] {
+			if seen[up] {
+				continue
+			}
+			seen[up] = true
+			prev[up] = cur
+			if up == taskID {
+				path := []uuid.UUID{taskID}
+				for n := taskID; n != dependsOn; {
+					n = prev[n]
+					path = append(path, n)
+				}
+				// Reverse into dependsOn → … → taskID.
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path
+			}
+			queue = append(queue, up)
+		}
+	}
+	return nil
+}
+
+// BlockedBy lists, per task, the titles of upstream tasks that aren't done yet.
+func BlockedBy(rows []db.ListEventDependenciesRow) map[uuid.UUID][]string {
+	out := map[uuid.UUID][]string{}
+	for _, r := range rows {
+		if r.DependencyStatus == "done" {
+			continue
+		}
+		out[r.TaskID] = append(out[r.TaskID], r.DependencyTitle)
+	}
+	for id := range out {
+		sort.Strings(out[id])
+	}
+	return out
+}
+
+// DependencyRef is how a dependency edge is recorded in the ledger.
+type DependencyRef struct {
+	ID    uuid.UUID `json:"id"`
+	Title string    `json:"title"`
+}
+
+// DependencyChanges builds the payload for a DEPENDENCY_ADDED/REMOVED event.
+func DependencyChanges(dep db.Task, added bool) []byte {
+	ref := DependencyRef{ID: dep.ID, Title: dep.Title}
+	c := Change{Field: "dependency", To: ref}
+	if !added {
+		c = Change{Field: "dependency", From: ref}
+	}
+	b, _ := json.Marshal([]Change{c})
+	return b
+}
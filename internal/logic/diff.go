@@ -1,39 +1,202 @@
 package logic
 
 import (
+	"bytes"
+	"database/sql"
 	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
 
 	"github.com/navyaalva/sbf-os/internal/db"
 )
 
+// Task lifecycle event types written to task_events.event_type.
+const (
+	EventCreated      = "CREATED"
+	EventUpdated      = "UPDATED"
+	EventDeleted      = "DELETED"
+	EventBatchDeleted = "BATCH_DELETED"
+	EventRestored     = "RESTORED"
+)
+
 type Change struct {
 	Field string      `json:"field"`
 	From  interface{} `json:"from"`
 	To    interface{} `json:"to"`
+	// Items breaks a subtasks change down per checklist entry.
+	Items []SubtaskChange `json:"items,omitempty"`
 }
 
+// SubtaskChange is one entry of a subtasks diff: "added", "removed" or "toggled".
+type SubtaskChange struct {
+	Action string `json:"action"`
+	Title  string `json:"title"`
+	IsDone bool   `json:"is_done"`
+}
+
+// CalculateChanges diffs every task column except id/created_at (immutable)
+// and last_update_at (bumped on every write, so it would only add noise).
 func CalculateChanges(oldT, newT db.Task) []byte {
 	var changes []Change
 
-	if oldT.Title != newT.Title {
-		changes = append(changes, Change{Field: "title", From: oldT.Title, To: newT.Title})
+	oldFields, newFields := taskFields(oldT), taskFields(newT)
+	for i, f := range oldFields {
+		to := newFields[i].Value
+		if sameValue(f.Value, to) {
+			continue
+		}
+
+		c := Change{Field: f.Name, From: f.Value, To: to}
+		if f.Name == "subtasks" {
+			c.Items = diffSubtasks(f.Value, to)
+		}
+		changes = append(changes, c)
 	}
-	if oldT.Description.String != newT.Description.String || oldT.Description.Valid != newT.Description.Valid {
-		changes = append(changes, Change{Field: "description", From: oldT.Description, To: newT.Description})
+
+	if len(changes) == 0 {
+		return []byte(`[]`)
 	}
-	if oldT.Status != newT.Status {
-		changes = append(changes, Change{Field: "status", From: oldT.Status, To: newT.Status})
+
+	b, _ := json.Marshal(changes)
+	return b
+}
+
+// sameValue compares by JSON encoding, which is exactly what lands in the ledger.
+func sameValue(a, b interface{}) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// diffSubtasks matches checklist entries by title (they have no IDs). Duplicate
+// titles pair up in order; leftovers are additions/removals.
+func diffSubtasks(from, to interface{}) []SubtaskChange {
+	oldSubs, _ := from.([]Subtask)
+	newSubs, _ := to.([]Subtask)
+
+	unmatched := map[string][]Subtask{}
+	for _, s := range oldSubs {
+		unmatched[s.Title] = append(unmatched[s.Title], s)
 	}
-	if oldT.Priority != newT.Priority {
-		changes = append(changes, Change{Field: "priority", From: oldT.Priority, To: newT.Priority})
+
+	var items []SubtaskChange
+	for _, s := range newSubs {
+		prev, ok := unmatched[s.Title]
+		if !ok || len(prev) == 0 {
+			items = append(items, SubtaskChange{Action: "added", Title: s.Title, IsDone: s.IsDone})
+			continue
+		}
+		unmatched[s.Title] = prev[1:]
+		if prev[0].IsDone != s.IsDone {
+			items = append(items, SubtaskChange{Action: "toggled", Title: s.Title, IsDone: s.IsDone})
+		}
 	}
 
-	// due_date, tags if you want (optional)
+	// Walk the old list again so removals keep their original order.
+	for _, s := range oldSubs {
+		if rest := unmatched[s.Title]; len(rest) > 0 {
+			unmatched[s.Title] = rest[1:]
+			items = append(items, SubtaskChange{Action: "removed", Title: rest[0].Title, IsDone: rest[0].IsDone})
+		}
+	}
+	return items
+}
 
-	if len(changes) == 0 {
-		return []byte(`[]`)
+// SnapshotChanges records every field of a freshly created task as a change
+// from null, so a CREATED event alone is enough to rebuild the task.
+func SnapshotChanges(t db.Task) []byte {
+	fields := taskFields(t)
+	changes := make([]Change, 0, len(fields))
+	for _, f := range fields {
+		changes = append(changes, Change{Field: f.Name, From: nil, To: f.Value})
 	}
 
 	b, _ := json.Marshal(changes)
 	return b
 }
+
+// DeletionChanges records the soft delete stamp for DELETED/BATCH_DELETED events.
+func DeletionChanges(t db.Task) []byte {
+	b, _ := json.Marshal([]Change{
+		{Field: "deleted_at", From: nil, To: nullTimestamp(t.DeletedAt)},
+	})
+	return b
+}
+
+type fieldValue struct {
+	Name  string
+	Value interface{}
+}
+
+// taskFields flattens a task into plain JSON-friendly values in a stable order.
+// NULL columns become nil rather than {"String":..,"Valid":..} blobs.
+func taskFields(t db.Task) []fieldValue {
+	return []fieldValue{
+		{"title", t.Title},
+		{"description", nullString(t.Description)},
+		{"status", t.Status},
+		{"priority", t.Priority},
+		{"due_date", nullDate(t.DueDate)},
+		{"category", t.Category},
+		{"owner_id", nullUUID(t.OwnerID)},
+		{"assignee_text", nullString(t.AssigneeText)},
+		{"tags", tagList(t.Tags)},
+		{"subtasks", subtaskList(t.Subtasks)},
+		{"event_id", t.EventID.String()},
+		{"completed_at", nullTimestamp(t.CompletedAt)},
+		{"is_archived", t.IsArchived},
+		{"deleted_at", nullTimestamp(t.DeletedAt)},
+	}
+}
+
+func nullString(v sql.NullString) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.String
+}
+
+func nullUUID(v uuid.NullUUID) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.UUID.String()
+}
+
+func nullDate(v sql.NullTime) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.Time.Format("2006-01-02")
+}
+
+func nullTimestamp(v sql.NullTime) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	return v.Time.UTC().Format(time.RFC3339Nano)
+}
+
+func tagList(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func subtaskList(v pqtype.NullRawMessage) interface{} {
+	if !v.Valid {
+		return nil
+	}
+	var subs []Subtask
+	if err := json.Unmarshal(v.RawMessage, &subs); err != nil {
+		// Keep whatever is stored rather than losing it from the ledger.
+		return json.RawMessage(v.RawMessage)
+	}
+	if subs == nil {
+		subs = []Subtask{}
+	}
+	return subs
+}
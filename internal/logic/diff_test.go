@@ -0,0 +1,162 @@
+package logic
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+func subtasksJSON(t *testing.T, subs ...Subtask) pqtype.NullRawMessage {
+	t.Helper()
+	b, err := json.Marshal(subs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pqtype.NullRawMessage{RawMessage: b, Valid: true}
+}
+
+func TestCalculateChanges(t *testing.T) {
+	base := db.Task{
+		ID:        uuid.New(),
+		Title:     "Book venue",
+		Status:    "backlog",
+		Priority:  3,
+		Category:  "Venue",
+		EventID:   uuid.MustParse("22222222-2222-2222-2222-222222222222"),
+		CreatedAt: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+	}
+	due := sql.NullTime{Time: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), Valid: true}
+
+	tests := []struct {
+		name   string
+		change func(*db.Task)
+		want   string
+	}{
+		{
+			name:   "nothing changed",
+			change: func(*db.Task) {},
+			want:   `[]`,
+		},
+		{
+			name: "bookkeeping columns are ignored",
+			change: func(t *db.Task) {
+				t.LastUpdateAt = sql.NullTime{Time: time.Now(), Valid: true}
+				t.CreatedAt = time.Now()
+				t.Tags = []string{}
+			},
+			want: `[]`,
+		},
+		{
+			name:   "only changed fields are listed",
+			change: func(t *db.Task) { t.Title = "Book the hall"; t.Priority = 5 },
+			want:   `[{"field":"title","from":"Book venue","to":"Book the hall"},{"field":"priority","from":3,"to":5}]`,
+		},
+		{
+			name:   "NULL to a value",
+			change: func(t *db.Task) { t.Description = sql.NullString{String: "200 guests", Valid: true}; t.DueDate = due },
+			want:   `[{"field":"description","from":null,"to":"200 guests"},{"field":"due_date","from":null,"to":"2026-05-01"}]`,
+		},
+		{
+			name: "owner set",
+			change: func(t *db.Task) {
+				t.OwnerID = uuid.NullUUID{UUID: uuid.MustParse("33333333-3333-3333-3333-333333333333"), Valid: true}
+			},
+			want: `[{"field":"owner_id","from":null,"to":"33333333-3333-3333-3333-333333333333"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			after := base
+			tt.change(&after)
+			if got := string(CalculateChanges(base, after)); got != tt.want {
+				t.Errorf("CalculateChanges() =\n  %s\nwant\n  %s", got, tt.want)
+			}
+		})
+	}
+
+	// And back again: a value to NULL is a JSON null, not a {"Valid": false} blob.
+	withDue := base
+	withDue.DueDate = due
+	if got, want := string(CalculateChanges(withDue, base)), `[{"field":"due_date","from":"2026-05-01","to":null}]`; got != want {
+		t.Errorf("clearing the due date = %s, want %s", got, want)
+	}
+}
+
+func TestCalculateChangesSubtasks(t *testing.T) {
+	before := db.Task{Subtasks: subtasksJSON(t, Subtask{Title: "Call venues"}, Subtask{Title: "Sign contract"})}
+	after := before
+	after.Subtasks = subtasksJSON(t, Subtask{Title: "Call venues", IsDone: true}, Subtask{Title: "Pay deposit"})
+
+	var changes []Change
+	if err := json.Unmarshal(CalculateChanges(before, after), &changes); err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Field != "subtasks" {
+		t.Fatalf("changes = %+v, want one subtasks change", changes)
+	}
+	want := []SubtaskChange{
+		{Action: "toggled", Title: "Call venues", IsDone: true},
+		{Action: "added", Title: "Pay deposit"},
+		{Action: "removed", Title: "Sign contract"},
+	}
+	if !reflect.DeepEqual(changes[0].Items, want) {
+		t.Errorf("items = %+v, want %+v", changes[0].Items, want)
+	}
+}
+
+func TestDiffSubtasks(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to []Subtask
+		want     []SubtaskChange
+	}{
+		{
+			name: "add to an empty list",
+			to:   []Subtask{{Title: "Call venues"}},
+			want: []SubtaskChange{{Action: "added", Title: "Call venues"}},
+		},
+		{
+			name: "remove keeps the old order",
+			from: []Subtask{{Title: "A"}, {Title: "B", IsDone: true}, {Title: "C"}},
+			to:   []Subtask{{Title: "B", IsDone: true}},
+			want: []SubtaskChange{{Action: "removed", Title: "A"}, {Action: "removed", Title: "C"}},
+		},
+		{
+			name: "toggle both ways",
+			from: []Subtask{{Title: "A"}, {Title: "B", IsDone: true}},
+			to:   []Subtask{{Title: "A", IsDone: true}, {Title: "B"}},
+			want: []SubtaskChange{{Action: "toggled", Title: "A", IsDone: true}, {Action: "toggled", Title: "B"}},
+		},
+		{
+			name: "rename is a removal and an addition",
+			from: []Subtask{{Title: "Call venue"}},
+			to:   []Subtask{{Title: "Call venues"}},
+			want: []SubtaskChange{{Action: "added", Title: "Call venues"}, {Action: "removed", Title: "Call venue"}},
+		},
+		{
+			name: "reordering alone is not a change",
+			from: []Subtask{{Title: "A"}, {Title: "B"}},
+			to:   []Subtask{{Title: "B"}, {Title: "A"}},
+		},
+		{
+			name: "duplicate titles pair up in order",
+			from: []Subtask{{Title: "Call", IsDone: true}, {Title: "Call"}},
+			to:   []Subtask{{Title: "Call"}},
+			want: []SubtaskChange{{Action: "toggled", Title: "Call"}, {Action: "removed", Title: "Call"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffSubtasks(tt.from, tt.to); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffSubtasks() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package logic
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// DigestTask is one line of a digest section.
+type DigestTask struct {
+	ID        uuid.UUID
+	Title     string
+	EventName string
+	DueDate   time.Time // zero when the task has no due date
+	DaysIdle  int
+	// Idle is set when DaysIdle has reached the event's StaleShortDays, so
+	// the digest mentions it.
+	Idle bool
+}
+
+// DigestChange is someone else's edit to a task the person owns.
+type DigestChange struct {
+	TaskID    uuid.UUID
+	TaskTitle string
+	EventName string
+	Actor     string
+	Fields    []string
+	At        time.Time
+}
+
+// Digest is one person's daily summary.
+type Digest struct {
+	Name          string
+	Date          time.Time
+	Overdue       []DigestTask
+	DueSoon       []DigestTask
+	Stale         []DigestTask
+	NewlyAssigned []DigestTask
+	Changes       []DigestChange
+}
+
+// Empty reports whether there's nothing worth sending.
+func (d Digest) Empty() bool {
+	return len(d.Overdue)+len(d.DueSoon)+len(d.Stale)+len(d.NewlyAssigned)+len(d.Changes) == 0
+}
+
+// BuildDigest sorts a person's open tasks (ListMyTasks) into overdue, due
+// soon and stale using each event's risk profile, and turns the last day of
+// other people's task_events on those tasks into newly assigned and changed.
+// A task is listed once, in the most urgent section it fits.
+func BuildDigest(name string, personID uuid.UUID, tasks []db.ListMyTasksRow, changes []db.ListOwnedTaskChangesRow, profiles map[uuid.UUID]RiskProfile, now time.Time) Digest {
+	d := Digest{Name: name, Date: dateOnly(now)}
+
+	open := map[uuid.UUID]db.ListMyTasksRow{}
+	for _, t := range tasks {
+		open[t.ID] = t
+		p, ok := profiles[t.EventID]
+		if !ok {
+			p = DefaultRiskProfile()
+		}
+
+		lastTouch := t.CreatedAt
+		if t.LastUpdateAt.Valid {
+			lastTouch = t.LastUpdateAt.Time
+		}
+		dt := digestTask(t)
+		dt.DaysIdle = int(now.Sub(lastTouch).Hours() / 24)
+		dt.Idle = dt.DaysIdle >= p.StaleShortDays
+
+		switch {
+		case t.DueDate.Valid && dayOffset(d.Date, dateOnly(t.DueDate.Time)) < 0:
+			d.Overdue = append(d.Overdue, dt)
+		case t.DueDate.Valid && dayOffset(d.Date, dateOnly(t.DueDate.Time)) <= p.DueSoonDays:
+			d.DueSoon = append(d.DueSoon, dt)
+		case dt.Idle:
+			d.Stale = append(d.Stale, dt)
+		}
+	}
+
+	me := personID.String()
+	assigned := map[uuid.UUID]bool{}
+	for _, c := range changes {
+		var fields []Change
+		_ = json.Unmarshal(c.Changes, &fields)
+
+		var names []string
+		for _, f := range fields {
+			if f.Field == "owner_id" && f.To == me && !assigned[c.TaskID] {
+				// Only still-open tasks; a task handed over and finished
+				// the same day needs no introduction.
+				if t, ok := open[c.TaskID]; ok {
+					assigned[c.TaskID] = true
+					d.NewlyAssigned = append(d.NewlyAssigned, digestTask(t))
+				}
+			}
+			names = append(names, strings.ReplaceAll(f.Field, "_", " "))
+		}
+		if c.EventType == EventCreated {
+			names = []string{"created"}
+		}
+		if len(names) == 0 {
+			continue
+		}
+
+		actor := "System"
+		if c.ActorName.Valid {
+			actor = c.ActorName.String
+		}
+		d.Changes = append(d.Changes, DigestChange{
+			TaskID:    c.TaskID,
+			TaskTitle: c.TaskTitle,
+			EventName: c.EventName,
+			Actor:     actor,
+			Fields:    names,
+			At:        c.CreatedAt,
+		})
+	}
+	return d
+}
+
+func digestTask(t db.ListMyTasksRow) DigestTask {
+	dt := DigestTask{ID: t.ID, Title: t.Title, EventName: t.EventName}
+	if t.DueDate.Valid {
+		dt.DueDate = t.DueDate.Time
+	}
+	return dt
+}
@@ -0,0 +1,81 @@
+package logic
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+func TestBuildDigest(t *testing.T) {
+	now := time.Date(2026, 4, 10, 9, 0, 0, 0, time.UTC)
+	relaxed, strict := uuid.New(), uuid.New()
+	strictProfile := DefaultRiskProfile()
+	strictProfile.StaleShortDays = 3
+	profiles := map[uuid.UUID]RiskProfile{relaxed: DefaultRiskProfile(), strict: strictProfile}
+
+	task := func(title string, event uuid.UUID, idleDays int, dueInDays *int) db.ListMyTasksRow {
+		row := db.ListMyTasksRow{ID: uuid.New(), Title: title, EventID: event, EventName: "Fair", CreatedAt: now.AddDate(0, 0, -idleDays)}
+		if dueInDays != nil {
+			row.DueDate = sql.NullTime{Time: now.AddDate(0, 0, *dueInDays), Valid: true}
+		}
+		return row
+	}
+	days := func(n int) *int { return &n }
+
+	d := BuildDigest("Sam", uuid.New(), []db.ListMyTasksRow{
+		task("Late and quiet", relaxed, 10, days(-2)),
+		task("Late but busy", strict, 1, days(-1)),
+		task("Soon", relaxed, 0, days(2)),
+		task("Quiet under a strict profile", strict, 4, nil),
+		task("Quiet under the default", relaxed, 4, nil),
+		task("Far off", relaxed, 8, days(30)),
+	}, nil, profiles, now)
+
+	sections := map[string][]DigestTask{"overdue": d.Overdue, "due soon": d.DueSoon, "stale": d.Stale}
+	want := map[string][]string{
+		"overdue":  {"Late and quiet", "Late but busy"},
+		"due soon": {"Soon"},
+		"stale":    {"Quiet under a strict profile", "Far off"},
+	}
+	for name, tasks := range sections {
+		var got []string
+		for _, t := range tasks {
+			got = append(got, t.Title)
+		}
+		if len(got) != len(want[name]) {
+			t.Errorf("%s = %v, want %v", name, got, want[name])
+			continue
+		}
+		for i := range got {
+			if got[i] != want[name][i] {
+				t.Errorf("%s = %v, want %v", name, got, want[name])
+			}
+		}
+	}
+
+	// Idle follows each event's own stale threshold, in every section.
+	idle := map[string]bool{}
+	for _, tasks := range sections {
+		for _, t := range tasks {
+			idle[t.Title] = t.Idle
+		}
+	}
+	for title, want := range map[string]bool{
+		"Late and quiet":               true,
+		"Late but busy":                false,
+		"Soon":                         false,
+		"Quiet under a strict profile": true,
+		"Far off":                      true,
+	} {
+		if idle[title] != want {
+			t.Errorf("%s: Idle = %v, want %v", title, idle[title], want)
+		}
+	}
+	if d.Empty() {
+		t.Error("digest with tasks reported empty")
+	}
+}
@@ -1,33 +1,156 @@
 package logic
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
+	"github.com/google/uuid"
+
 	"github.com/navyaalva/sbf-os/internal/db"
 )
 
 type ScoredTask struct {
-	Task      interface{} // Can hold db.GetEventTasksRow or db.GetGlobalActiveTasksRow
+	Task      interface{} // Can hold db.GetEventTasksRow or db.ListTasksRow
 	Score     int
 	Reasons   []string
 	RiskLevel string
+	Breakdown []RiskFactor
+}
+
+// RiskFactor is one line of a score: what fired and how many points it added.
+// The factors always sum to the score.
+type RiskFactor struct {
+	Rule   string `json:"rule,omitempty"`
+	Factor string `json:"factor"`
+	Points int    `json:"points"`
+}
+
+// Rule keys name each check in calculateRisk. Unlike the factor labels they
+// don't change with the profile, so alerts can dedupe on them.
+const (
+	RuleOverdue         = "overdue"
+	RuleDueSoon         = "due_soon"
+	RuleStale           = "stale"
+	RuleStaleLong       = "stale_long"
+	RuleBlocked         = "blocked"
+	RulePriority        = "priority"
+	RuleBlocks          = "blocks"
+	RuleOverdueUpstream = "overdue_upstream"
+	RuleCriticalPath    = "critical_path"
+	RuleBehindSchedule  = "behind_schedule"
+)
+
+// DepSignals is what the dependency graph adds to a task's own risk.
+type DepSignals struct {
+	Blocks          int // open tasks downstream, directly or transitively
+	BlockedPriority int // sum of those tasks' priorities
+	OverdueUpstream int // open upstream tasks already past their due date
+	CriticalStep    int // 1-based position on the critical path, 0 if off it
+	CriticalLength  int
+	SlackDays       int
+}
+
+// CriticalSlackDays is the most slack a critical path can have and still
+// count towards risk.
+const CriticalSlackDays = 3
+
+// DependencySignals walks an event's dependency graph once for every open task.
+func DependencySignals(tasks []db.GetEventTasksRow, deps []db.ListEventDependenciesRow, eventDate, now time.Time) map[uuid.UUID]DepSignals {
+	open := map[uuid.UUID]db.GetEventTasksRow{}
+	for _, t := range tasks {
+		if t.Status != "done" {
+			open[t.ID] = t
+		}
+	}
+
+	upstream, downstream := map[uuid.UUID][]uuid.UUID{}, map[uuid.UUID][]uuid.UUID{}
+	for _, d := range deps {
+		if _, ok := open[d.TaskID]; !ok {
+			continue
+		}
+		if _, ok := open[d.DependencyID]; !ok {
+			continue
+		}
+		upstream[d.TaskID] = append(upstream[d.TaskID], d.DependencyID)
+		downstream[d.DependencyID] = append(downstream[d.DependencyID], d.TaskID)
+	}
+
+	signals := map[uuid.UUID]DepSignals{}
+	for id := range open {
+		var sig DepSignals
+		for _, down := range reachable(id, downstream) {
+			sig.Blocks++
+			sig.BlockedPriority += int(open[down].Priority)
+		}
+		for _, up := range reachable(id, upstream) {
+			if due := open[up].DueDate; due.Valid && due.Time.Before(dateOnly(now)) {
+				sig.OverdueUpstream++
+			}
+		}
+		signals[id] = sig
+	}
+
+	sched := BuildSchedule(tasks, deps, eventDate, now)
+	for _, st := range sched.Tasks {
+		sig := signals[st.ID]
+		sig.SlackDays = st.SlackDays
+		signals[st.ID] = sig
+	}
+	// A lone task isn't a path, and a chain with a week to spare isn't
+	// critical in any useful sense; only flag real, tight chains.
+	if len(sched.CriticalPath) < 2 || sched.CriticalPath[0].SlackDays > CriticalSlackDays {
+		return signals
+	}
+	for i, st := range sched.CriticalPath {
+		sig := signals[st.ID]
+		sig.CriticalStep = i + 1
+		sig.CriticalLength = len(sched.CriticalPath)
+		signals[st.ID] = sig
+	}
+	return signals
+}
+
+// reachable lists every node reachable from start along edges, excluding start.
+func reachable(start uuid.UUID, edges map[uuid.UUID][]uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{start: true}
+	var out []uuid.UUID
+	queue := []uuid.UUID{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[cur] {
+			if !seen[next] {
+				seen[next] = true
+				out = append(out, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return out
 }
 
 // Common scoring engine
-func calculateRisk(status string, priority int32, dueDate, createdAt, lastUpdate time.Time, isDueDateValid, isLastUpdateValid bool) (int, []string, string) {
+func calculateRisk(p RiskProfile, status string, priority int32, dueDate, createdAt, lastUpdate time.Time, isDueDateValid, isLastUpdateValid bool, deps DepSignals) (int, []string, string, []RiskFactor) {
 	score := 0
 	reasons := []string{}
+	breakdown := []RiskFactor{}
 	now := time.Now()
 
+	// flag adds points and the reason label shown in the tooltip.
+	flag := func(rule, reason string, points int) {
+		score += points
+		reasons = append(reasons, reason)
+		breakdown = append(breakdown, RiskFactor{Rule: rule, Factor: reason, Points: points})
+	}
+
 	// 1. Due Date
 	if isDueDateValid {
 		daysUntil := int(time.Until(dueDate).Hours() / 24)
 		if daysUntil < 0 {
-			score += 50
-			reasons = append(reasons, "OVERDUE")
-		} else if daysUntil <= 3 {
-			score += 30
-			reasons = append(reasons, "Due Soon")
+			flag(RuleOverdue, "OVERDUE", p.OverduePoints)
+		} else if daysUntil <= p.DueSoonDays {
+			flag(RuleDueSoon, "Due Soon", p.DueSoonPoints)
 		}
 	}
 
@@ -38,37 +161,61 @@ func calculateRisk(status string, priority int32, dueDate, createdAt, lastUpdate
 	}
 	daysSince := int(now.Sub(lastTouch).Hours() / 24)
 	if status != "done" {
-		if daysSince >= 14 {
-			score += 30
-			reasons = append(reasons, "Stale (14d)")
-		} else if daysSince >= 7 {
-			score += 10
-			reasons = append(reasons, "Stale (7d)")
+		if daysSince >= p.StaleLongDays {
+			flag(RuleStaleLong, fmt.Sprintf("Stale (%dd)", p.StaleLongDays), p.StaleLongPoints)
+		} else if daysSince >= p.StaleShortDays {
+			flag(RuleStale, fmt.Sprintf("Stale (%dd)", p.StaleShortDays), p.StaleShortPoints)
 		}
 	}
 
 	// 3. Status
 	if status == "blocked" {
-		score += 25
-		reasons = append(reasons, "Blocked")
+		flag(RuleBlocked, "Blocked", p.BlockedPoints)
+	}
+
+	// 4. Priority (always applies, so it's a factor but not a reason)
+	if pts := int(priority) * p.PriorityWeight; pts != 0 {
+		score += pts
+		breakdown = append(breakdown, RiskFactor{Rule: RulePriority, Factor: fmt.Sprintf("Priority %d", priority), Points: pts})
 	}
 
-	// 4. Priority
-	score += int(priority) * 5
+	// 5. Dependencies: what this task holds up, and what holds it up
+	if status != "done" {
+		if deps.Blocks > 0 {
+			// Weighted by what's waiting, so blocking critical work counts more.
+			pts := min(deps.BlockedPriority*p.BlocksWeight, p.BlocksCap)
+			if deps.Blocks == 1 {
+				flag(RuleBlocks, "Blocks 1 task", pts)
+			} else {
+				flag(RuleBlocks, fmt.Sprintf("Blocks %d tasks", deps.Blocks), pts)
+			}
+		}
+		if deps.OverdueUpstream > 0 {
+			flag(RuleOverdueUpstream, "Waiting on overdue task", p.OverdueUpstreamPoints)
+		}
+		if deps.CriticalStep > 0 {
+			// Earlier steps push every later step back when they slip.
+			flag(RuleCriticalPath, fmt.Sprintf("Critical path (step %d of %d)", deps.CriticalStep, deps.CriticalLength),
+				p.CriticalStepPoints*(deps.CriticalLength-deps.CriticalStep+1))
+		}
+		if deps.SlackDays < 0 {
+			flag(RuleBehindSchedule, fmt.Sprintf("Behind schedule (%dd slack)", deps.SlackDays), p.BehindSchedulePoints)
+		}
+	}
 
 	// Level
 	level := "low"
-	if score >= 50 {
+	if score >= p.HighThreshold {
 		level = "high"
-	} else if score >= 25 {
+	} else if score >= p.MedThreshold {
 		level = "med"
 	}
 
-	return score, reasons, level
+	return score, reasons, level, breakdown
 }
 
 // Wrapper for Event View
-func ScoreTaskRow(t db.GetEventTasksRow) ScoredTask {
+func ScoreTaskRow(t db.GetEventTasksRow, deps DepSignals, p RiskProfile) ScoredTask {
 	// Helper to convert sql.NullTime to generic inputs
 	due := time.Time{}
 	if t.DueDate.Valid {
@@ -79,12 +226,13 @@ func ScoreTaskRow(t db.GetEventTasksRow) ScoredTask {
 		upd = t.LastUpdateAt.Time
 	}
 
-	s, r, l := calculateRisk(t.Status, t.Priority, due, t.CreatedAt, upd, t.DueDate.Valid, t.LastUpdateAt.Valid)
-	return ScoredTask{Task: t, Score: s, Reasons: r, RiskLevel: l}
+	s, r, l, b := calculateRisk(p, t.Status, t.Priority, due, t.CreatedAt, upd, t.DueDate.Valid, t.LastUpdateAt.Valid, deps)
+	return ScoredTask{Task: t, Score: s, Reasons: r, RiskLevel: l, Breakdown: b}
 }
 
-// Wrapper for Global View (AI Briefing)
-func ScoreGlobalTask(t db.GetGlobalActiveTasksRow) ScoredTask {
+// Wrapper for task listings (Pulse, filtered event pages, the API). Signals
+// come from the task's whole event graph, not just the listed page.
+func ScoreListedTask(t db.ListTasksRow, deps DepSignals, p RiskProfile) ScoredTask {
 	due := time.Time{}
 	if t.DueDate.Valid {
 		due = t.DueDate.Time
@@ -94,6 +242,50 @@ func ScoreGlobalTask(t db.GetGlobalActiveTasksRow) ScoredTask {
 		upd = t.LastUpdateAt.Time
 	}
 
-	s, r, l := calculateRisk(t.Status, t.Priority, due, t.CreatedAt, upd, t.DueDate.Valid, t.LastUpdateAt.Valid)
-	return ScoredTask{Task: t, Score: s, Reasons: r, RiskLevel: l}
+	s, r, l, b := calculateRisk(p, t.Status, t.Priority, due, t.CreatedAt, upd, t.DueDate.Valid, t.LastUpdateAt.Valid, deps)
+	return ScoredTask{Task: t, Score: s, Reasons: r, RiskLevel: l, Breakdown: b}
+}
+
+// ScoreEventTasks scores an event's tasks under one profile, highest risk first.
+func ScoreEventTasks(tasks []db.GetEventTasksRow, signals map[uuid.UUID]DepSignals, p RiskProfile) []ScoredTask {
+	scored := make([]ScoredTask, 0, len(tasks))
+	for _, t := range tasks {
+		scored = append(scored, ScoreTaskRow(t, signals[t.ID], p))
+	}
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].Score > scored[j].Score
+	})
+	return scored
+}
+
+// RiskRisingPoints is how far a score has to climb since the last snapshot
+// before the task is flagged as "risk rising".
+const RiskRisingPoints = 15
+
+// RisingRisk compares live scores with a previous snapshot (task ID → score)
+// and returns the jump for every task that rose by at least RiskRisingPoints.
+// Tasks without a previous score are new, not rising.
+func RisingRisk(scored []ScoredTask, previous map[uuid.UUID]int) map[uuid.UUID]int {
+	rising := map[uuid.UUID]int{}
+	for _, st := range scored {
+		id, ok := scoredTaskID(st)
+		if !ok {
+			continue
+		}
+		before, seen := previous[id]
+		if seen && st.Score-before >= RiskRisingPoints {
+			rising[id] = st.Score - before
+		}
+	}
+	return rising
+}
+
+func scoredTaskID(st ScoredTask) (uuid.UUID, bool) {
+	switch t := st.Task.(type) {
+	case db.GetEventTasksRow:
+		return t.ID, true
+	case db.ListTasksRow:
+		return t.ID, true
+	}
+	return uuid.Nil, false
 }
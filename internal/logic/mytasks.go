@@ -0,0 +1,50 @@
+package logic
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// MyTask is one row of the operator view with its checklist unpacked.
+type MyTask struct {
+	db.ListMyTasksRow
+	Checklist []Subtask
+}
+
+// TaskBucket is one heading of the operator view.
+type TaskBucket struct {
+	Name  string
+	Tasks []MyTask
+}
+
+// GroupMyTasks sorts a person's open tasks into Overdue / Today / This Week /
+// Later by due date. Tasks without a due date are Later. Row order (due date,
+// then priority) is kept inside each bucket, and empty buckets are kept so the
+// page always has the same shape.
+func GroupMyTasks(rows []db.ListMyTasksRow, now time.Time) []TaskBucket {
+	buckets := []TaskBucket{{Name: "Overdue"}, {Name: "Today"}, {Name: "This Week"}, {Name: "Later"}}
+	today := dateOnly(now)
+
+	for _, row := range rows {
+		t := MyTask{ListMyTasksRow: row}
+		if row.Subtasks.Valid {
+			_ = json.Unmarshal(row.Subtasks.RawMessage, &t.Checklist)
+		}
+
+		i := 3
+		if row.DueDate.Valid {
+			switch days := dayOffset(today, dateOnly(row.DueDate.Time)); {
+			case days < 0:
+				i = 0
+			case days == 0:
+				i = 1
+			case days <= 7:
+				i = 2
+			}
+		}
+		buckets[i].Tasks = append(buckets[i].Tasks, t)
+	}
+	return buckets
+}
@@ -0,0 +1,216 @@
+package logic
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// RiskProfile holds every weight and threshold calculateRisk uses.
+type RiskProfile struct {
+	OverduePoints    int `json:"overdue_points"`
+	DueSoonPoints    int `json:"due_soon_points"`
+	DueSoonDays      int `json:"due_soon_days"`
+	StaleShortDays   int `json:"stale_short_days"`
+	StaleShortPoints int `json:"stale_short_points"`
+	StaleLongDays    int `json:"stale_long_days"`
+	StaleLongPoints  int `json:"stale_long_points"`
+	BlockedPoints    int `json:"blocked_points"`
+	PriorityWeight   int `json:"priority_weight"`
+
+	BlocksWeight          int `json:"blocks_weight"`
+	BlocksCap             int `json:"blocks_cap"`
+	OverdueUpstreamPoints int `json:"overdue_upstream_points"`
+	CriticalStepPoints    int `json:"critical_step_points"`
+	BehindSchedulePoints  int `json:"behind_schedule_points"`
+
+	HighThreshold int `json:"high_threshold"`
+	MedThreshold  int `json:"med_threshold"`
+}
+
+// DefaultRiskProfile is what events with no profile attached use. The
+// built-in "Default" row in risk_profiles holds the same numbers.
+func DefaultRiskProfile() RiskProfile {
+	return RiskProfile{
+		OverduePoints:    50,
+		DueSoonPoints:    30,
+		DueSoonDays:      3,
+		StaleShortDays:   7,
+		StaleShortPoints: 10,
+		StaleLongDays:    14,
+		StaleLongPoints:  30,
+		BlockedPoints:    25,
+		PriorityWeight:   5,
+
+		BlocksWeight:          2,
+		BlocksCap:             40,
+		OverdueUpstreamPoints: 20,
+		CriticalStepPoints:    5,
+		BehindSchedulePoints:  25,
+
+		HighThreshold: 50,
+		MedThreshold:  25,
+	}
+}
+
+// LoadRiskProfile returns the profile attached to an event, or the defaults
+// if none is. The bool reports whether one is attached. The server and the
+// background jobs both score with it.
+func LoadRiskProfile(ctx context.Context, q *db.Queries, eventID uuid.UUID) (RiskProfile, bool, error) {
+	row, err := q.GetEventRiskProfile(ctx, eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultRiskProfile(), false, nil
+	}
+	if err != nil {
+		return RiskProfile{}, false, err
+	}
+	return RiskProfileFromDB(row), true, nil
+}
+
+// RiskProfileFromDB converts a stored row.
+func RiskProfileFromDB(p db.RiskProfile) RiskProfile {
+	return RiskProfile{
+		OverduePoints:    int(p.OverduePoints),
+		DueSoonPoints:    int(p.DueSoonPoints),
+		DueSoonDays:      int(p.DueSoonDays),
+		StaleShortDays:   int(p.StaleShortDays),
+		StaleShortPoints: int(p.StaleShortPoints),
+		StaleLongDays:    int(p.StaleLongDays),
+		StaleLongPoints:  int(p.StaleLongPoints),
+		BlockedPoints:    int(p.BlockedPoints),
+		PriorityWeight:   int(p.PriorityWeight),
+
+		BlocksWeight:          int(p.BlocksWeight),
+		BlocksCap:             int(p.BlocksCap),
+		OverdueUpstreamPoints: int(p.OverdueUpstreamPoints),
+		CriticalStepPoints:    int(p.CriticalStepPoints),
+		BehindSchedulePoints:  int(p.BehindSchedulePoints),
+
+		HighThreshold: int(p.HighThreshold),
+		MedThreshold:  int(p.MedThreshold),
+	}
+}
+
+// ProfileField is one editable knob of a RiskProfile.
+type ProfileField struct {
+	Key   string
+	Label string
+	Group string
+	Value int
+}
+
+type profileKnob struct {
+	key, label, group string
+	ptr               *int
+}
+
+func (p *RiskProfile) fields() []profileKnob {
+	return []profileKnob{
+		{"overdue_points", "Overdue", "Due date", &p.OverduePoints},
+		{"due_soon_points", "Due soon", "Due date", &p.DueSoonPoints},
+		{"due_soon_days", "“Due soon” window (days)", "Due date", &p.DueSoonDays},
+		{"stale_short_days", "Stale after (days)", "Staleness", &p.StaleShortDays},
+		{"stale_short_points", "Stale", "Staleness", &p.StaleShortPoints},
+		{"stale_long_days", "Very stale after (days)", "Staleness", &p.StaleLongDays},
+		{"stale_long_points", "Very stale", "Staleness", &p.StaleLongPoints},
+		{"blocked_points", "Status is blocked", "Status & priority", &p.BlockedPoints},
+		{"priority_weight", "Points per priority level", "Status & priority", &p.PriorityWeight},
+		{"blocks_weight", "Per priority level of each blocked task", "Dependencies", &p.BlocksWeight},
+		{"blocks_cap", "Cap on blocking points", "Dependencies", &p.BlocksCap},
+		{"overdue_upstream_points", "Waiting on overdue task", "Dependencies", &p.OverdueUpstreamPoints},
+		{"critical_step_points", "Per remaining critical-path step", "Dependencies", &p.CriticalStepPoints},
+		{"behind_schedule_points", "Negative slack", "Dependencies", &p.BehindSchedulePoints},
+		{"high_threshold", "High risk at", "Levels", &p.HighThreshold},
+		{"med_threshold", "Medium risk at", "Levels", &p.MedThreshold},
+	}
+}
+
+// Fields lists the profile in form order.
+func (p RiskProfile) Fields() []ProfileField {
+	var out []ProfileField
+	for _, f := range p.fields() {
+		out = append(out, ProfileField{Key: f.key, Label: f.label, Group: f.group, Value: *f.ptr})
+	}
+	return out
+}
+
+// Set assigns one knob by its form key.
+func (p *RiskProfile) Set(key string, v int) bool {
+	for _, f := range p.fields() {
+		if f.key == key {
+			*f.ptr = v
+			return true
+		}
+	}
+	return false
+}
+
+// Validate rejects profiles that would make the levels meaningless.
+func (p RiskProfile) Validate() error {
+	for _, f := range p.fields() {
+		if *f.ptr < 0 || *f.ptr > 1000 {
+			return fmt.Errorf("%s must be between 0 and 1000", f.label)
+		}
+	}
+	if p.MedThreshold >= p.HighThreshold {
+		return errors.New("medium risk threshold must be below the high risk threshold")
+	}
+	if p.StaleLongDays < p.StaleShortDays {
+		return errors.New("“very stale” must not come before “stale”")
+	}
+	return nil
+}
+
+// RankChange is one task's position under the saved profile vs a draft.
+type RankChange struct {
+	TaskID    uuid.UUID
+	Title     string
+	OldRank   int
+	NewRank   int
+	OldScore  int
+	NewScore  int
+	OldLevel  string
+	NewLevel  string
+	Movement  int // positive = moved up the list
+	Reasons   []string
+	Breakdown []RiskFactor // under the draft
+}
+
+// PreviewProfile re-ranks an event's tasks under draft, ordered by the new rank.
+func PreviewProfile(tasks []db.GetEventTasksRow, signals map[uuid.UUID]DepSignals, current, draft RiskProfile) []RankChange {
+	before := ScoreEventTasks(tasks, signals, current)
+	after := ScoreEventTasks(tasks, signals, draft)
+
+	oldPos := map[uuid.UUID]int{}
+	oldByID := map[uuid.UUID]ScoredTask{}
+	for i, st := range before {
+		id := st.Task.(db.GetEventTasksRow).ID
+		oldPos[id] = i + 1
+		oldByID[id] = st
+	}
+
+	changes := make([]RankChange, 0, len(after))
+	for i, st := range after {
+		t := st.Task.(db.GetEventTasksRow)
+		old := oldByID[t.ID]
+		changes = append(changes, RankChange{
+			TaskID:    t.ID,
+			Title:     t.Title,
+			OldRank:   oldPos[t.ID],
+			NewRank:   i + 1,
+			OldScore:  old.Score,
+			NewScore:  st.Score,
+			OldLevel:  old.RiskLevel,
+			NewLevel:  st.RiskLevel,
+			Movement:  oldPos[t.ID] - (i + 1),
+			Reasons:   st.Reasons,
+			Breakdown: st.Breakdown,
+		})
+	}
+	return changes
+}
@@ -0,0 +1,46 @@
+package logic
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+func TestPreviewProfile(t *testing.T) {
+	now := time.Now()
+	overdue := db.GetEventTasksRow{ID: uuid.New(), Title: "Overdue", Status: "in_progress", CreatedAt: now,
+		DueDate: sql.NullTime{Time: now.AddDate(0, 0, -2), Valid: true}}
+	blocked := db.GetEventTasksRow{ID: uuid.New(), Title: "Blocked", Status: "blocked", CreatedAt: now}
+	tasks := []db.GetEventTasksRow{overdue, blocked}
+
+	current := DefaultRiskProfile()
+	draft := current
+	draft.BlockedPoints = 90
+
+	changes := PreviewProfile(tasks, nil, current, draft)
+	if len(changes) != 2 {
+		t.Fatalf("got %d changes, want 2", len(changes))
+	}
+	first, second := changes[0], changes[1]
+	if first.Title != "Blocked" || first.OldRank != 2 || first.NewRank != 1 || first.Movement != 1 {
+		t.Errorf("first = %+v, want Blocked moving from 2 to 1", first)
+	}
+	if second.Title != "Overdue" || second.Movement != -1 || second.OldScore != second.NewScore {
+		t.Errorf("second = %+v, want Overdue dropping one place with its score unchanged", second)
+	}
+	if first.OldScore == first.NewScore || first.NewLevel != "high" {
+		t.Errorf("blocked score %d → %d (%s), want a higher high-level score", first.OldScore, first.NewScore, first.NewLevel)
+	}
+	// The breakdown explains the draft score, factor by factor.
+	sum := 0
+	for _, f := range first.Breakdown {
+		sum += f.Points
+	}
+	if len(first.Breakdown) == 0 || sum != first.NewScore {
+		t.Errorf("breakdown %+v sums to %d, want %d", first.Breakdown, sum, first.NewScore)
+	}
+}
@@ -0,0 +1,175 @@
+package logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// ErrNoCreatedEvent means the ledger has no CREATED row at or before the
+// requested time (the task predates full audit coverage, or `at` is too early).
+var ErrNoCreatedEvent = errors.New("no CREATED event in task history")
+
+// TaskState is a task rebuilt from task_events, keyed by Change.Field.
+type TaskState map[string]json.RawMessage
+
+// StateField is one row of a TaskState rendered for display.
+type StateField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Fields lists the state in the same order the diff engine uses.
+func (s TaskState) Fields() []StateField {
+	var out []StateField
+	for _, name := range taskFieldNames() {
+		v, ok := s[name]
+		if !ok {
+			continue
+		}
+		out = append(out, StateField{Name: name, Value: string(v)})
+	}
+	return out
+}
+
+// ReplayResult is the outcome of folding a task's ledger up to a point in time.
+type ReplayResult struct {
+	At          time.Time `json:"at"`
+	State       TaskState `json:"state"`
+	Applied     int       `json:"events_applied"`
+	LastEventAt time.Time `json:"last_event_at"`
+	// Conflicts are changes whose "from" didn't match the replayed value,
+	// i.e. the ledger itself skipped a write.
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// ReplayTask folds task_events forward from CREATED to rebuild the task as it
+// was at `at`. Event types that don't carry field changes are skipped.
+func ReplayTask(events []db.GetTaskEventsRow, at time.Time) (ReplayResult, error) {
+	ordered := make([]db.GetTaskEventsRow, len(events))
+	copy(ordered, events)
+	// Events from one transaction share a timestamp; seq keeps them in
+	// the order they were written.
+	sort.Slice(ordered, func(i, j int) bool {
+		if !ordered[i].CreatedAt.Equal(ordered[j].CreatedAt) {
+			return ordered[i].CreatedAt.Before(ordered[j].CreatedAt)
+		}
+		return ordered[i].Seq < ordered[j].Seq
+	})
+
+	res := ReplayResult{At: at}
+	for _, e := range ordered {
+		if e.CreatedAt.After(at) {
+			break
+		}
+		if !replayable(e.EventType) {
+			continue
+		}
+
+		var changes []Change
+		if err := json.Unmarshal(e.Changes, &changes); err != nil {
+			return res, fmt.Errorf("event %s: bad changes payload: %w", e.ID, err)
+		}
+
+		if e.EventType == EventCreated {
+			res.State = TaskState{}
+			res.Conflicts = nil
+		} else if res.State == nil {
+			// Changes before the birth certificate can't be placed; ignore them.
+			continue
+		}
+
+		for _, c := range changes {
+			to := normalizeLegacyValue(mustJSON(c.To))
+			if e.EventType != EventCreated {
+				from := normalizeLegacyValue(mustJSON(c.From))
+				if cur, ok := res.State[c.Field]; ok && !bytes.Equal(cur, from) {
+					res.Conflicts = append(res.Conflicts, fmt.Sprintf(
+						"%s %s: expected %s to be %s, ledger had %s",
+						e.CreatedAt.Format("2006-01-02 15:04:05"), e.EventType, c.Field, from, cur))
+				}
+			}
+			res.State[c.Field] = to
+		}
+		res.Applied++
+		res.LastEventAt = e.CreatedAt
+	}
+
+	if res.State == nil {
+		return res, ErrNoCreatedEvent
+	}
+	return res, nil
+}
+
+// CheckDrift compares a replayed head with the live tasks row and returns one
+// Change per field where they disagree (From = ledger, To = table).
+func CheckDrift(state TaskState, current db.Task) []Change {
+	var drift []Change
+	for _, f := range taskFields(current) {
+		actual := mustJSON(f.Value)
+		replayed, ok := state[f.Name]
+		if !ok {
+			replayed = json.RawMessage(`null`)
+		}
+		if !bytes.Equal(replayed, actual) {
+			drift = append(drift, Change{Field: f.Name, From: replayed, To: actual})
+		}
+	}
+	return drift
+}
+
+func replayable(eventType string) bool {
+	switch eventType {
+	case EventCreated, EventUpdated, EventDeleted, EventBatchDeleted, EventRestored:
+		return true
+	}
+	return false
+}
+
+func taskFieldNames() []string {
+	fields := taskFields(db.Task{})
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Name
+	}
+	return names
+}
+
+func mustJSON(v interface{}) json.RawMessage {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return json.RawMessage(`null`)
+	}
+	return b
+}
+
+// normalizeLegacyValue unwraps {"String":"x","Valid":true}-style blobs written
+// by the old diff engine so early events replay to plain values.
+func normalizeLegacyValue(raw json.RawMessage) json.RawMessage {
+	var blob map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &blob); err != nil || len(blob) != 2 {
+		return raw
+	}
+	validRaw, ok := blob["Valid"]
+	if !ok {
+		return raw
+	}
+	var valid bool
+	if err := json.Unmarshal(validRaw, &valid); err != nil {
+		return raw
+	}
+	if !valid {
+		return json.RawMessage(`null`)
+	}
+	for k, v := range blob {
+		if k != "Valid" {
+			return v
+		}
+	}
+	return raw
+}
@@ -0,0 +1,185 @@
+package logic
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// ledger builds task_events rows newest first, the way GetTaskEvents returns
+// them, numbering seq in the order they're listed here.
+func ledger(events ...db.GetTaskEventsRow) []db.GetTaskEventsRow {
+	out := make([]db.GetTaskEventsRow, len(events))
+	for i, e := range events {
+		e.ID = uuid.New()
+		e.Seq = int64(i + 1)
+		out[len(events)-1-i] = e
+	}
+	return out
+}
+
+func taskEvent(eventType string, at time.Time, changes string) db.GetTaskEventsRow {
+	return db.GetTaskEventsRow{EventType: eventType, CreatedAt: at, Changes: json.RawMessage(changes)}
+}
+
+func TestReplayTask(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	t1, t2 := t0.Add(time.Hour), t0.Add(2*time.Hour)
+	created := `[{"field":"title","from":null,"to":"Book venue"},{"field":"status","from":null,"to":"backlog"},{"field":"description","from":null,"to":"200 guests"},{"field":"deleted_at","from":null,"to":null}]`
+
+	tests := []struct {
+		name          string
+		events        []db.GetTaskEventsRow
+		at            time.Time
+		want          map[string]string
+		wantApplied   int
+		wantConflicts int
+		wantErr       error
+	}{
+		{
+			name: "mid-history",
+			events: ledger(
+				taskEvent(EventCreated, t0, created),
+				taskEvent(EventUpdated, t1, `[{"field":"status","from":"backlog","to":"in_progress"}]`),
+				taskEvent(EventUpdated, t2, `[{"field":"status","from":"in_progress","to":"done"}]`),
+			),
+			at:          t1.Add(time.Minute),
+			want:        map[string]string{"status": `"in_progress"`, "title": `"Book venue"`},
+			wantApplied: 2,
+		},
+		{
+			// CREATED and the template seed share NOW(); replaying them the
+			// wrong way round would reset the title.
+			name: "same timestamp follows seq",
+			events: ledger(
+				taskEvent(EventCreated, t0, created),
+				taskEvent(EventUpdated, t0, `[{"field":"title","from":"Book venue","to":"Book the hall"}]`),
+			),
+			at:          t0,
+			want:        map[string]string{"title": `"Book the hall"`},
+			wantApplied: 2,
+		},
+		{
+			name: "deleted then restored",
+			events: ledger(
+				taskEvent(EventCreated, t0, created),
+				taskEvent(EventDeleted, t1, `[{"field":"deleted_at","from":null,"to":"2026-03-01T10:00:00Z"}]`),
+				taskEvent(EventRestored, t2, `[{"field":"deleted_at","from":"2026-03-01T10:00:00Z","to":null}]`),
+			),
+			at:          t2,
+			want:        map[string]string{"deleted_at": `null`},
+			wantApplied: 3,
+		},
+		{
+			name: "deleted, replayed before the restore",
+			events: ledger(
+				taskEvent(EventCreated, t0, created),
+				taskEvent(EventDeleted, t1, `[{"field":"deleted_at","from":null,"to":"2026-03-01T10:00:00Z"}]`),
+				taskEvent(EventRestored, t2, `[{"field":"deleted_at","from":"2026-03-01T10:00:00Z","to":null}]`),
+			),
+			at:          t1,
+			want:        map[string]string{"deleted_at": `"2026-03-01T10:00:00Z"`},
+			wantApplied: 2,
+		},
+		{
+			name: "legacy sql.Null blobs",
+			events: ledger(
+				taskEvent(EventCreated, t0, created),
+				taskEvent(EventUpdated, t1, `[{"field":"description","from":{"String":"200 guests","Valid":true},"to":{"String":"","Valid":false}}]`),
+			),
+			at:          t1,
+			want:        map[string]string{"description": `null`},
+			wantApplied: 2,
+		},
+		{
+			name: "a skipped write shows up as a conflict",
+			events: ledger(
+				taskEvent(EventCreated, t0, created),
+				taskEvent(EventUpdated, t1, `[{"field":"status","from":"blocked","to":"done"}]`),
+			),
+			at:            t1,
+			want:          map[string]string{"status": `"done"`},
+			wantApplied:   2,
+			wantConflicts: 1,
+		},
+		{
+			name: "dependency events carry no fields",
+			events: ledger(
+				taskEvent(EventCreated, t0, created),
+				taskEvent(EventDependencyAdded, t1, `{"dependency_id":"x"}`),
+			),
+			at:          t1,
+			want:        map[string]string{"title": `"Book venue"`},
+			wantApplied: 1,
+		},
+		{
+			name:    "before the task existed",
+			events:  ledger(taskEvent(EventCreated, t1, created)),
+			at:      t0,
+			wantErr: ErrNoCreatedEvent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := ReplayTask(tt.events, tt.at)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ReplayTask() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReplayTask() error = %v", err)
+			}
+			for field, want := range tt.want {
+				if got := string(res.State[field]); got != want {
+					t.Errorf("%s = %s, want %s", field, got, want)
+				}
+			}
+			if res.Applied != tt.wantApplied {
+				t.Errorf("applied %d events, want %d", res.Applied, tt.wantApplied)
+			}
+			if len(res.Conflicts) != tt.wantConflicts {
+				t.Errorf("conflicts = %q, want %d", res.Conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
+
+func TestCheckDrift(t *testing.T) {
+	task := db.Task{
+		ID:          uuid.New(),
+		Title:       "Book venue",
+		Status:      "backlog",
+		Priority:    3,
+		Category:    "Venue",
+		Description: sql.NullString{String: "200 guests", Valid: true},
+		EventID:     uuid.New(),
+	}
+	at := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	res, err := ReplayTask(ledger(db.GetTaskEventsRow{EventType: EventCreated, CreatedAt: at, Changes: SnapshotChanges(task)}), at)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if drift := CheckDrift(res.State, task); len(drift) != 0 {
+		t.Errorf("replayed snapshot drifts: %+v", drift)
+	}
+
+	// A write that skipped the ledger.
+	live := task
+	live.Status = "done"
+	drift := CheckDrift(res.State, live)
+	if len(drift) != 1 || drift[0].Field != "status" {
+		t.Fatalf("drift = %+v, want only status", drift)
+	}
+	if from, to := string(drift[0].From.(json.RawMessage)), string(drift[0].To.(json.RawMessage)); from != `"backlog"` || to != `"done"` {
+		t.Errorf("status drift %s -> %s, want \"backlog\" -> \"done\"", from, to)
+	}
+}
@@ -0,0 +1,251 @@
+package logic
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// TaskDays is the effort assumed for every open task. Tasks carry no duration,
+// so the schedule counts "one working day per step" along each chain.
+const TaskDays = 1
+
+// ScheduledTask is one open task placed on the event's timeline.
+type ScheduledTask struct {
+	ID       uuid.UUID   `json:"id"`
+	Title    string      `json:"title"`
+	Status   string      `json:"status"`
+	DueDate  *time.Time  `json:"due_date,omitempty"`
+	Upstream []uuid.UUID `json:"upstream,omitempty"`
+	// EarliestFinish is the first day the task can be done if every upstream
+	// task is finished as early as possible.
+	EarliestFinish time.Time `json:"earliest_finish"`
+	// LatestFinish is the last day it can be done without pushing a dependent
+	// task past its due date or the event past event_date.
+	LatestFinish time.Time `json:"latest_finish"`
+	SlackDays    int       `json:"slack_days"`
+	Critical     bool      `json:"critical"`
+
+	es, ef, lf int // day offsets from Today, exclusive end
+}
+
+// DueDateConflict is an edge where the upstream task is due after the task
+// that waits on it.
+type DueDateConflict struct {
+	UpstreamID      uuid.UUID `json:"upstream_id"`
+	UpstreamTitle   string    `json:"upstream_title"`
+	UpstreamDue     time.Time `json:"upstream_due"`
+	DownstreamID    uuid.UUID `json:"downstream_id"`
+	DownstreamTitle string    `json:"downstream_title"`
+	DownstreamDue   time.Time `json:"downstream_due"`
+}
+
+// Schedule is the critical-path analysis of one event.
+type Schedule struct {
+	Today     time.Time `json:"today"`
+	EventDate time.Time `json:"event_date"`
+	DaysLeft  int       `json:"days_left"`
+	// CriticalPath runs from a task that can start today to the last task
+	// that must finish before the event, following the tightest chain.
+	CriticalPath []ScheduledTask   `json:"critical_path"`
+	Tasks        []ScheduledTask   `json:"tasks"`
+	Conflicts    []DueDateConflict `json:"due_date_conflicts"`
+	// Cyclic lists tasks on (or waiting behind) a dependency loop; they are
+	// left out of the analysis.
+	Cyclic []DependencyRef `json:"cyclic,omitempty"`
+}
+
+// BuildSchedule runs a forward/backward pass over the open tasks of an event.
+// Done tasks are treated as already finished and drop out of the graph.
+func BuildSchedule(tasks []db.GetEventTasksRow, deps []db.ListEventDependenciesRow, eventDate, now time.Time) Schedule {
+	today := dateOnly(now)
+	eventDay := dateOnly(eventDate)
+	sched := Schedule{
+		Today:        today,
+		EventDate:    eventDay,
+		DaysLeft:     dayOffset(today, eventDay),
+		CriticalPath: []ScheduledTask{},
+		Tasks:        []ScheduledTask{},
+		Conflicts:    []DueDateConflict{},
+	}
+
+	nodes := map[uuid.UUID]*ScheduledTask{}
+	for _, t := range tasks {
+		if t.Status == "done" {
+			continue
+		}
+		st := &ScheduledTask{ID: t.ID, Title: t.Title, Status: t.Status}
+		if t.DueDate.Valid {
+			due := dateOnly(t.DueDate.Time)
+			st.DueDate = &due
+		}
+		nodes[t.ID] = st
+	}
+
+	downstream := map[uuid.UUID][]uuid.UUID{}
+	indegree := map[uuid.UUID]int{}
+	for _, d := range deps {
+		down, up := nodes[d.TaskID], nodes[d.DependencyID]
+		if down == nil || up == nil {
+			continue
+		}
+		down.Upstream = append(down.Upstream, up.ID)
+		downstream[up.ID] = append(downstream[up.ID], down.ID)
+		indegree[down.ID]++
+
+		if up.DueDate != nil && down.DueDate != nil && up.DueDate.After(*down.DueDate) {
+			sched.Conflicts = append(sched.Conflicts, DueDateConflict{
+				UpstreamID:      up.ID,
+				UpstreamTitle:   up.Title,
+				UpstreamDue:     *up.DueDate,
+				DownstreamID:    down.ID,
+				DownstreamTitle: down.Title,
+				DownstreamDue:   *down.DueDate,
+			})
+		}
+	}
+
+	// Kahn's algorithm; whatever never reaches indegree 0 sits on a cycle.
+	var order []uuid.UUID
+	var queue []uuid.UUID
+	for id := range nodes {
+		if indegree[id] == 0 {
+			queue = append(queue, id)
+		}
+	}
+	sortIDs(queue, nodes)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		order = append(order, id)
+		for _, down := range downstream[id] {
+			indegree[down]--
+			if indegree[down] == 0 {
+				queue = append(queue, down)
+			}
+		}
+	}
+	placed := map[uuid.UUID]bool{}
+	for _, id := range order {
+		placed[id] = true
+	}
+	if len(order) < len(nodes) {
+		var stuck []uuid.UUID
+		for id := range nodes {
+			if !placed[id] {
+				stuck = append(stuck, id)
+			}
+		}
+		sortIDs(stuck, nodes)
+		for _, id := range stuck {
+			sched.Cyclic = append(sched.Cyclic, DependencyRef{ID: id, Title: nodes[id].Title})
+		}
+	}
+
+	// Forward pass: earliest finish.
+	for _, id := range order {
+		n := nodes[id]
+		for _, up := range n.Upstream {
+			if ef := nodes[up].ef; ef > n.es {
+				n.es = ef
+			}
+		}
+		n.ef = n.es + TaskDays
+	}
+
+	// Backward pass: latest finish bounded by own due date, the event, and
+	// whatever dependents need.
+	for i := len(order) - 1; i >= 0; i-- {
+		n := nodes[order[i]]
+		n.lf = sched.DaysLeft + 1
+		if n.DueDate != nil {
+			if due := dayOffset(today, *n.DueDate) + 1; due < n.lf {
+				n.lf = due
+			}
+		}
+		for _, down := range downstream[n.ID] {
+			if !placed[down] {
+				continue
+			}
+			if start := nodes[down].lf - TaskDays; start < n.lf {
+				n.lf = start
+			}
+		}
+		n.SlackDays = n.lf - n.ef
+		n.EarliestFinish = today.AddDate(0, 0, n.ef-1)
+		n.LatestFinish = today.AddDate(0, 0, n.lf-1)
+	}
+
+	if len(order) == 0 {
+		return sched
+	}
+
+	minSlack := nodes[order[0]].SlackDays
+	for _, id := range order {
+		if s := nodes[id].SlackDays; s < minSlack {
+			minSlack = s
+		}
+	}
+	for _, id := range order {
+		nodes[id].Critical = nodes[id].SlackDays == minSlack
+	}
+	sched.CriticalPath = criticalPath(order, nodes)
+
+	for _, id := range order {
+		sched.Tasks = append(sched.Tasks, *nodes[id])
+	}
+	sort.SliceStable(sched.Tasks, func(i, j int) bool {
+		if sched.Tasks[i].SlackDays != sched.Tasks[j].SlackDays {
+			return sched.Tasks[i].SlackDays < sched.Tasks[j].SlackDays
+		}
+		return sched.Tasks[i].EarliestFinish.Before(sched.Tasks[j].EarliestFinish)
+	})
+	return sched
+}
+
+// criticalPath walks back from the latest-finishing critical task through the
+// upstream task that actually drives its start.
+func criticalPath(order []uuid.UUID, nodes map[uuid.UUID]*ScheduledTask) []ScheduledTask {
+	var end *ScheduledTask
+	for _, id := range order {
+		n := nodes[id]
+		if n.Critical && (end == nil || n.ef > end.ef) {
+			end = n
+		}
+	}
+
+	var path []ScheduledTask
+	for cur := end; cur != nil; {
+		path = append(path, *cur)
+		var next *ScheduledTask
+		for _, up := range cur.Upstream {
+			u := nodes[up]
+			if u.Critical && u.ef == cur.es && (next == nil || u.Title < next.Title) {
+				next = u
+			}
+		}
+		cur = next
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func sortIDs(ids []uuid.UUID, nodes map[uuid.UUID]*ScheduledTask) {
+	sort.Slice(ids, func(i, j int) bool {
+		return nodes[ids[i]].Title < nodes[ids[j]].Title
+	})
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func dayOffset(from, to time.Time) int {
+	return int(to.Sub(from).Hours() / 24)
+}
@@ -0,0 +1,186 @@
+package logic
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// scheduleGraph builds tasks and edges by title. Specs are "Title",
+// "Title@2026-04-05" (due date) or "Title!" (done); edges are "Up>Down".
+func scheduleGraph(specs []string, edges []string) ([]db.GetEventTasksRow, []db.ListEventDependenciesRow) {
+	ids := map[string]uuid.UUID{}
+	var tasks []db.GetEventTasksRow
+	for _, spec := range specs {
+		t := db.GetEventTasksRow{ID: uuid.New(), Status: "backlog"}
+		if strings.HasSuffix(spec, "!") {
+			spec, t.Status = strings.TrimSuffix(spec, "!"), "done"
+		}
+		if title, due, ok := strings.Cut(spec, "@"); ok {
+			d, _ := time.Parse("2006-01-02", due)
+			spec, t.DueDate = title, sql.NullTime{Time: d, Valid: true}
+		}
+		t.Title = spec
+		ids[spec] = t.ID
+		tasks = append(tasks, t)
+	}
+	var deps []db.ListEventDependenciesRow
+	for _, e := range edges {
+		up, down, _ := strings.Cut(e, ">")
+		deps = append(deps, db.ListEventDependenciesRow{TaskID: ids[down], DependencyID: ids[up], DependencyTitle: up})
+	}
+	return tasks, deps
+}
+
+func titles(tasks []ScheduledTask) []string {
+	out := []string{}
+	for _, t := range tasks {
+		out = append(out, t.Title)
+	}
+	return out
+}
+
+func TestBuildSchedule(t *testing.T) {
+	// Today is day 0; the event is 10 days out, so a task with nothing after
+	// it can finish as late as the event day.
+	now := time.Date(2026, 4, 1, 15, 30, 0, 0, time.UTC)
+	event := time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		specs, edges  []string
+		wantPath      []string
+		wantSlack     map[string]int
+		wantLatest    map[string]string
+		wantConflicts int
+		wantCyclic    []string
+	}{
+		{
+			name:      "linear chain",
+			specs:     []string{"A", "B", "C"},
+			edges:     []string{"A>B", "B>C"},
+			wantPath:  []string{"A", "B", "C"},
+			wantSlack: map[string]int{"A": 8, "B": 8, "C": 8},
+			wantLatest: map[string]string{
+				"A": "2026-04-09", "B": "2026-04-10", "C": "2026-04-11",
+			},
+		},
+		{
+			// A fans out to a long branch (B, B2) and a short one (C); D is
+			// due exactly when the long branch can finish.
+			name:      "diamond with one zero-slack branch",
+			specs:     []string{"A", "B", "B2", "C", "D@2026-04-04"},
+			edges:     []string{"A>B", "B>B2", "B2>D", "A>C", "C>D"},
+			wantPath:  []string{"A", "B", "B2", "D"},
+			wantSlack: map[string]int{"A": 0, "B": 0, "B2": 0, "C": 1, "D": 0},
+		},
+		{
+			name:       "no due date is bounded by the event",
+			specs:      []string{"Solo"},
+			wantPath:   []string{"Solo"},
+			wantSlack:  map[string]int{"Solo": 10},
+			wantLatest: map[string]string{"Solo": "2026-04-11"},
+		},
+		{
+			name:       "due after the event is still bounded by the event",
+			specs:      []string{"Late@2026-04-20"},
+			wantPath:   []string{"Late"},
+			wantSlack:  map[string]int{"Late": 10},
+			wantLatest: map[string]string{"Late": "2026-04-11"},
+		},
+		{
+			name:          "upstream due after its dependent",
+			specs:         []string{"Quote@2026-04-06", "Sign@2026-04-03"},
+			edges:         []string{"Quote>Sign"},
+			wantPath:      []string{"Quote", "Sign"},
+			wantSlack:     map[string]int{"Quote": 1, "Sign": 1},
+			wantConflicts: 1,
+		},
+		{
+			name:      "done tasks drop out of the graph",
+			specs:     []string{"Book!", "Decorate"},
+			edges:     []string{"Book>Decorate"},
+			wantPath:  []string{"Decorate"},
+			wantSlack: map[string]int{"Decorate": 10},
+		},
+		{
+			name:       "cycle",
+			specs:      []string{"P", "Q", "R", "S"},
+			edges:      []string{"P>Q", "Q>P", "Q>R"},
+			wantPath:   []string{"S"},
+			wantSlack:  map[string]int{"S": 10},
+			wantCyclic: []string{"P", "Q", "R"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, deps := scheduleGraph(tt.specs, tt.edges)
+			sched := BuildSchedule(tasks, deps, event, now)
+
+			if sched.DaysLeft != 10 {
+				t.Errorf("DaysLeft = %d, want 10", sched.DaysLeft)
+			}
+			if got := titles(sched.CriticalPath); !reflect.DeepEqual(got, tt.wantPath) {
+				t.Errorf("critical path = %v, want %v", got, tt.wantPath)
+			}
+			if len(sched.Tasks) != len(tt.wantSlack) {
+				t.Errorf("scheduled %v, want %d tasks", titles(sched.Tasks), len(tt.wantSlack))
+			}
+			minSlack := -1
+			for i, st := range sched.Tasks {
+				if want, ok := tt.wantSlack[st.Title]; !ok || st.SlackDays != want {
+					t.Errorf("%s slack = %d, want %d", st.Title, st.SlackDays, want)
+				}
+				if i > 0 && st.SlackDays < sched.Tasks[i-1].SlackDays {
+					t.Errorf("tasks not sorted by slack: %v", titles(sched.Tasks))
+				}
+				if minSlack == -1 || st.SlackDays < minSlack {
+					minSlack = st.SlackDays
+				}
+				if want, ok := tt.wantLatest[st.Title]; ok && st.LatestFinish.Format("2006-01-02") != want {
+					t.Errorf("%s latest finish = %s, want %s", st.Title, st.LatestFinish.Format("2006-01-02"), want)
+				}
+			}
+			for _, st := range sched.Tasks {
+				if st.Critical != (st.SlackDays == minSlack) {
+					t.Errorf("%s critical = %v with slack %d (min %d)", st.Title, st.Critical, st.SlackDays, minSlack)
+				}
+			}
+			if len(sched.Conflicts) != tt.wantConflicts {
+				t.Errorf("conflicts = %+v, want %d", sched.Conflicts, tt.wantConflicts)
+			}
+			var cyclic []string
+			for _, c := range sched.Cyclic {
+				cyclic = append(cyclic, c.Title)
+			}
+			if !reflect.DeepEqual(cyclic, tt.wantCyclic) {
+				t.Errorf("cyclic = %v, want %v", cyclic, tt.wantCyclic)
+			}
+		})
+	}
+}
+
+func TestBuildScheduleConflict(t *testing.T) {
+	tasks, deps := scheduleGraph([]string{"Quote@2026-04-06", "Sign@2026-04-03"}, []string{"Quote>Sign"})
+	sched := BuildSchedule(tasks, deps, time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if len(sched.Conflicts) != 1 {
+		t.Fatalf("conflicts = %+v, want one", sched.Conflicts)
+	}
+	c := sched.Conflicts[0]
+	if c.UpstreamTitle != "Quote" || c.DownstreamTitle != "Sign" || c.UpstreamDue.Format("2006-01-02") != "2026-04-06" {
+		t.Errorf("conflict = %+v, want Quote (due 04-06) blocking Sign", c)
+	}
+}
+
+func TestBuildScheduleEmpty(t *testing.T) {
+	sched := BuildSchedule(nil, nil, time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC), time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC))
+	if sched.Tasks == nil || sched.CriticalPath == nil || sched.Conflicts == nil {
+		t.Errorf("empty schedule should encode as empty lists, got %+v", sched)
+	}
+}
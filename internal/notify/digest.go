@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+const digestText = `Hi {{.Name}}, here's your day ({{.Date.Format "Mon Jan 02"}}).
+{{- template "section" (section "Overdue" .Overdue)}}
+{{- template "section" (section "Due soon" .DueSoon)}}
+{{- template "section" (section "Gone quiet" .Stale)}}
+{{- template "section" (section "Newly assigned to you" .NewlyAssigned)}}
+{{- if .Changes}}
+
+Changed by others (last 24h):
+{{- range .Changes}}
+- {{.TaskTitle}} ({{.EventName}}): {{.Actor}} changed {{join .Fields ", "}}
+{{- end}}
+{{- end}}
+
+{{.BaseURL}}/my-tasks
+{{define "section"}}
+{{- if .Tasks}}
+
+{{.Title}}:
+{{- range .Tasks}}
+- {{.Title}} ({{.EventName}}){{if not .DueDate.IsZero}}, due {{.DueDate.Format "Jan 02"}}{{end}}{{if .Idle}}, idle {{.DaysIdle}}d{{end}}
+{{- end}}
+{{- end}}
+{{- end}}`
+
+const digestHTML = `<p>Hi {{.Name}}, here's your day ({{.Date.Format "Mon Jan 02"}}).</p>
+{{template "section" (section "🚨 Overdue" .Overdue)}}
+{{template "section" (section "⏰ Due soon" .DueSoon)}}
+{{template "section" (section "💤 Gone quiet" .Stale)}}
+{{template "section" (section "🆕 Newly assigned to you" .NewlyAssigned)}}
+{{- if .Changes}}
+<h3>✏️ Changed by others (last 24h)</h3>
+<ul>
+{{- range .Changes}}
+  <li><a href="{{$.BaseURL}}/tasks/{{.TaskID}}/edit">{{.TaskTitle}}</a> <small>({{.EventName}})</small>: {{.Actor}} changed {{join .Fields ", "}}</li>
+{{- end}}
+</ul>
+{{- end}}
+<p><a href="{{.BaseURL}}/my-tasks">Open My Tasks</a></p>
+{{define "section"}}
+{{- if .Tasks}}
+<h3>{{.Title}}</h3>
+<ul>
+{{- range .Tasks}}
+  <li><a href="{{$.BaseURL}}/tasks/{{.ID}}/edit">{{.Title}}</a> <small>({{.EventName}}){{if not .DueDate.IsZero}} · due {{.DueDate.Format "Jan 02"}}{{end}}{{if .Idle}} · idle {{.DaysIdle}}d{{end}}</small></li>
+{{- end}}
+</ul>
+{{- end}}
+{{- end}}`
+
+// digestSection is what the "section" sub-template ranges over.
+type digestSection struct {
+	Title   string
+	Tasks   []logic.DigestTask
+	BaseURL string
+}
+
+// DigestMessage renders a digest as plain text and HTML. baseURL prefixes
+// the task links.
+func DigestMessage(d logic.Digest, baseURL string) (Message, error) {
+	section := func(title string, tasks []logic.DigestTask) digestSection {
+		return digestSection{Title: title, Tasks: tasks, BaseURL: baseURL}
+	}
+	funcs := map[string]any{"section": section, "join": strings.Join}
+
+	textTmpl, err := texttemplate.New("digest").Funcs(funcs).Parse(digestText)
+	if err != nil {
+		return Message{}, err
+	}
+	htmlTmpl, err := htmltemplate.New("digest").Funcs(funcs).Parse(digestHTML)
+	if err != nil {
+		return Message{}, err
+	}
+
+	data := struct {
+		logic.Digest
+		BaseURL string
+	}{d, baseURL}
+
+	var text, html bytes.Buffer
+	if err := textTmpl.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if err := htmlTmpl.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Kind:    KindDigest,
+		Subject: "Your day: " + digestSummary(d),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+func digestSummary(d logic.Digest) string {
+	var parts []string
+	for _, p := range []struct {
+		n     int
+		label string
+	}{
+		{len(d.Overdue), "overdue"},
+		{len(d.DueSoon), "due soon"},
+		{len(d.Stale), "gone quiet"},
+		{len(d.NewlyAssigned), "new"},
+		{len(d.Changes), "changed"},
+	} {
+		if p.n > 0 {
+			parts = append(parts, strconv.Itoa(p.n)+" "+p.label)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
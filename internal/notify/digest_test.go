@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+func TestDigestMessage(t *testing.T) {
+	d := logic.Digest{
+		Name: "Sam",
+		Date: time.Date(2026, 4, 10, 0, 0, 0, 0, time.UTC),
+		Overdue: []logic.DigestTask{
+			// Under a relaxed profile ten quiet days aren't worth a mention...
+			{ID: uuid.New(), Title: "Book venue", EventName: "Fair", DueDate: time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC), DaysIdle: 10},
+		},
+		Stale: []logic.DigestTask{
+			// ...but under a strict one three are.
+			{ID: uuid.New(), Title: "Order tents", EventName: "Gala", DaysIdle: 3, Idle: true},
+		},
+		Changes: []logic.DigestChange{{TaskID: uuid.New(), TaskTitle: "Print menus", EventName: "Gala", Actor: "Ann", Fields: []string{"status", "due date"}}},
+	}
+	m, err := DigestMessage(d, "https://ops.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Kind != KindDigest || m.Subject != "Your day: 1 overdue, 1 gone quiet, 1 changed" {
+		t.Errorf("kind %q, subject %q", m.Kind, m.Subject)
+	}
+	for _, want := range []string{
+		"Hi Sam, here's your day (Fri Apr 10).",
+		"- Book venue (Fair), due Apr 08\n",
+		"- Order tents (Gala), idle 3d\n",
+		"- Print menus (Gala): Ann changed status, due date",
+		"https://ops.example.com/my-tasks",
+	} {
+		if !strings.Contains(m.Text, want) {
+			t.Errorf("text is missing %q:\n%s", want, m.Text)
+		}
+	}
+	if strings.Contains(m.Text, "Due soon") || strings.Contains(m.Text, "idle 10d") {
+		t.Errorf("text has an empty section or an unflagged idle count:\n%s", m.Text)
+	}
+	for _, want := range []string{
+		`<a href="https://ops.example.com/tasks/` + d.Stale[0].ID.String() + `/edit">Order tents</a>`,
+		"· idle 3d",
+	} {
+		if !strings.Contains(m.HTML, want) {
+			t.Errorf("HTML is missing %q:\n%s", want, m.HTML)
+		}
+	}
+	if strings.Contains(m.HTML, "idle 10d") {
+		t.Errorf("HTML shows an unflagged idle count:\n%s", m.HTML)
+	}
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+const followUpText = `Hi {{.Name}},
+
+Suggested follow-ups for today:
+{{range .FollowUps}}
+{{- if .Overdue}}
+- {{.Assignee}} is overdue on '{{.TaskTitle}}'
+{{- else}}
+- Nudge {{.Assignee}} about '{{.TaskTitle}}' (due in {{.DaysUntil}} days)
+{{- end}}
+{{- end}}
+`
+
+const followUpHTML = `<p>Hi {{.Name}},</p>
+<p><strong>🔔 Suggested follow-ups for today:</strong></p>
+<ul>
+{{- range .FollowUps}}
+{{- if .Overdue}}
+  <li>🚨 <strong>{{.Assignee}}</strong> is overdue on '{{.TaskTitle}}'</li>
+{{- else}}
+  <li>👉 Nudge <strong>{{.Assignee}}</strong> about '{{.TaskTitle}}' (due in {{.DaysUntil}} days)</li>
+{{- end}}
+{{- end}}
+</ul>
+`
+
+var (
+	followUpTextTmpl = texttemplate.Must(texttemplate.New("follow_up").Parse(followUpText))
+	followUpHTMLTmpl = htmltemplate.Must(htmltemplate.New("follow_up").Parse(followUpHTML))
+)
+
+// FollowUpMessage renders logic.CheckFollowUps output for one person.
+func FollowUpMessage(name string, followUps []logic.FollowUp) (Message, error) {
+	data := struct {
+		Name      string
+		FollowUps []logic.FollowUp
+	}{name, followUps}
+
+	var text, html bytes.Buffer
+	if err := followUpTextTmpl.Execute(&text, data); err != nil {
+		return Message{}, err
+	}
+	if err := followUpHTMLTmpl.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Kind:    KindFollowUp,
+		Subject: "Follow-ups for today",
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// TestMessage is what the settings page sends to check a channel end to end.
+func TestMessage(name string) Message {
+	return Message{
+		Kind:    KindTest,
+		Subject: "Test notification",
+		Text:    "Hi " + name + ",\n\nNotifications from Event Planning OS reach you here.\n",
+	}
+}
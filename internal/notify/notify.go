@@ -0,0 +1,295 @@
+// Package notify delivers messages to people over pluggable channels (email,
+// signed webhooks). Messages are queued in notification_deliveries and sent
+// by Deliver, which honours quiet hours and retries failures with backoff.
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// Message is one notification, rendered up front for every channel.
+type Message struct {
+	Kind    string `json:"kind"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html,omitempty"`
+}
+
+// Recipient is where one person can be reached.
+type Recipient struct {
+	PersonID   uuid.UUID
+	Name       string
+	Email      string
+	WebhookURL string
+}
+
+// Delivery is a message on its way to one recipient over one channel.
+type Delivery struct {
+	ID        uuid.UUID
+	Recipient Recipient
+	Message   Message
+}
+
+// Channel sends a delivery. A returned error is retried later, so Send should
+// not retry internally.
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, d Delivery) error
+}
+
+// Channel names, as stored in notification_deliveries.channel.
+const (
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
+
+// Kinds of message, as stored in notification_deliveries.kind.
+const (
+	KindFollowUp = "follow_up"
+	KindDigest   = "digest"
+	KindTest     = "test"
+)
+
+// Config decides which channels are available. A channel with no settings
+// is simply off.
+type Config struct {
+	SMTP SMTPConfig
+	// WebhookSecret signs outgoing webhooks; empty disables the channel.
+	WebhookSecret string
+	// WebhookAllowPrivate lets webhooks reach loopback and private addresses.
+	// Only for local development: any user can pick their webhook URL.
+	WebhookAllowPrivate bool
+	// MaxAttempts is how many sends a delivery gets before it's marked failed.
+	MaxAttempts int
+	// BaseURL is where links in messages point, e.g. https://ops.example.com.
+	BaseURL string
+}
+
+// Notifier queues and dispatches notifications.
+type Notifier struct {
+	q           *db.Queries
+	channels    map[string]Channel
+	maxAttempts int
+	baseURL     string
+}
+
+func New(q *db.Queries, cfg Config) *Notifier {
+	n := &Notifier{q: q, channels: map[string]Channel{}, maxAttempts: cfg.MaxAttempts, baseURL: strings.TrimRight(cfg.BaseURL, "/")}
+	if n.maxAttempts <= 0 {
+		n.maxAttempts = 5
+	}
+	if cfg.SMTP.Host != "" {
+		n.Register(NewSMTPChannel(cfg.SMTP))
+	}
+	if cfg.WebhookSecret != "" {
+		n.Register(NewWebhookChannel(cfg.WebhookSecret, cfg.WebhookAllowPrivate))
+	}
+	return n
+}
+
+// Register adds or replaces a channel.
+func (n *Notifier) Register(c Channel) {
+	n.channels[c.Name()] = c
+}
+
+// BaseURL is the app's public address, without a trailing slash.
+func (n *Notifier) BaseURL() string {
+	return n.baseURL
+}
+
+// Channels lists the configured channel names.
+func (n *Notifier) Channels() []string {
+	names := make([]string, 0, len(n.channels))
+	for name := range n.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Prefs loads a person's settings, falling back to the defaults when they
+// never saved any.
+func Prefs(ctx context.Context, q *db.Queries, personID uuid.UUID) (db.NotificationPref, error) {
+	p, err := q.GetNotificationPrefs(ctx, personID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return db.NotificationPref{
+			PersonID:     personID,
+			EmailEnabled: true,
+			Timezone:     "UTC",
+			DigestHour:   sql.NullInt32{Int32: 8, Valid: true},
+		}, nil
+	}
+	return p, err
+}
+
+// Enqueue queues m for every channel the person has switched on and the
+// server has configured. dedupeKey makes re-queueing the same message (say a
+// job re-running the same day) a no-op. Returns how many deliveries were added.
+func (n *Notifier) Enqueue(ctx context.Context, personID uuid.UUID, m Message, dedupeKey string) (int, error) {
+	return n.EnqueueOn(ctx, personID, "", m, dedupeKey)
+}
+
+// EnqueueOn is Enqueue limited to one channel; "" means all of them. The
+// person still has to have that channel on.
+func (n *Notifier) EnqueueOn(ctx context.Context, personID uuid.UUID, only string, m Message, dedupeKey string) (int, error) {
+	prefs, err := Prefs(ctx, n.q, personID)
+	if err != nil {
+		return 0, err
+	}
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return 0, err
+	}
+
+	var channels []string
+	if _, ok := n.channels[ChannelEmail]; ok && prefs.EmailEnabled {
+		channels = append(channels, ChannelEmail)
+	}
+	if _, ok := n.channels[ChannelWebhook]; ok && prefs.WebhookUrl.Valid {
+		channels = append(channels, ChannelWebhook)
+	}
+
+	added := 0
+	for _, ch := range channels {
+		if only != "" && ch != only {
+			continue
+		}
+		rows, err := n.q.EnqueueDelivery(ctx, db.EnqueueDeliveryParams{
+			PersonID:  personID,
+			Channel:   ch,
+			Kind:      m.Kind,
+			DedupeKey: dedupeKey,
+			Payload:   payload,
+		})
+		if err != nil {
+			return added, err
+		}
+		added += int(rows)
+	}
+	return added, nil
+}
+
+// retryBackoff is the wait after the nth failed attempt (last entry repeats).
+var retryBackoff = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 6 * time.Hour}
+
+func backoff(attempt int) time.Duration {
+	return retryBackoff[min(max(attempt, 1), len(retryBackoff))-1]
+}
+
+// Deliver sends everything that is due. Deliveries landing in the
+// recipient's quiet hours are pushed to the end of them without using up an
+// attempt.
+func (n *Notifier) Deliver(ctx context.Context) error {
+	due, err := n.q.ListDueDeliveries(ctx, 50)
+	if err != nil {
+		return err
+	}
+
+	sent, failed := 0, 0
+	for _, row := range due {
+		now := time.Now()
+		loc := time.UTC
+		if row.Timezone.Valid {
+			if l, err := time.LoadLocation(row.Timezone.String); err == nil {
+				loc = l
+			}
+		}
+		if row.QuietStart.Valid && row.QuietEnd.Valid {
+			if until, quiet := QuietUntil(now.In(loc), int(row.QuietStart.Int32), int(row.QuietEnd.Int32)); quiet {
+				if err := n.q.DeferDelivery(ctx, db.DeferDeliveryParams{ID: row.ID, NextAttemptAt: until}); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		sendErr := n.send(ctx, row)
+		if sendErr == nil {
+			if err := n.q.MarkDeliverySent(ctx, row.ID); err != nil {
+				return err
+			}
+			sent++
+			continue
+		}
+
+		attempt := int(row.Attempts) + 1
+		status := "pending"
+		if attempt >= n.maxAttempts {
+			status = "failed"
+		}
+		if err := n.q.MarkDeliveryFailed(ctx, db.MarkDeliveryFailedParams{
+			ID:            row.ID,
+			Status:        status,
+			LastError:     sql.NullString{String: sendErr.Error(), Valid: true},
+			NextAttemptAt: now.Add(backoff(attempt)),
+		}); err != nil {
+			return err
+		}
+		failed++
+	}
+
+	if sent > 0 || failed > 0 {
+		log.Printf("📨 Notifications: %d sent, %d failed", sent, failed)
+	}
+	return nil
+}
+
+func (n *Notifier) send(ctx context.Context, row db.ListDueDeliveriesRow) error {
+	ch, ok := n.channels[row.Channel]
+	if !ok {
+		return fmt.Errorf("channel %q is not configured", row.Channel)
+	}
+	var m Message
+	if err := json.Unmarshal(row.Payload, &m); err != nil {
+		return fmt.Errorf("bad payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return ch.Send(ctx, Delivery{
+		ID: row.ID,
+		Recipient: Recipient{
+			PersonID:   row.PersonID,
+			Name:       row.PersonName,
+			Email:      row.PersonEmail.String,
+			WebhookURL: row.WebhookUrl.String,
+		},
+		Message: m,
+	})
+}
+
+// QuietUntil reports whether local falls inside the quiet window
+// [start, end) (hours, may wrap midnight) and, if so, when it ends.
+// Equal start and end means no quiet hours.
+func QuietUntil(local time.Time, start, end int) (time.Time, bool) {
+	if start == end {
+		return time.Time{}, false
+	}
+	h := local.Hour()
+	quiet := h >= start && h < end
+	if start > end {
+		quiet = h >= start || h < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	y, m, d := local.Date()
+	until := time.Date(y, m, d, end, 0, 0, 0, local.Location())
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQuietUntil(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	at := func(day, hour, minute int) time.Time { return time.Date(2026, 3, day, hour, minute, 0, 0, time.UTC) }
+	tests := []struct {
+		name       string
+		local      time.Time
+		start, end int
+		want       time.Time
+		wantQuiet  bool
+	}{
+		{name: "no quiet hours", local: at(10, 23, 0), start: 8, end: 8},
+		{name: "daytime window, inside", local: at(10, 13, 15), start: 12, end: 14, want: at(10, 14, 0), wantQuiet: true},
+		{name: "daytime window, at the end", local: at(10, 14, 0), start: 12, end: 14},
+		{name: "daytime window, before", local: at(10, 11, 59), start: 12, end: 14},
+		{name: "overnight, late evening", local: at(10, 22, 30), start: 22, end: 7, want: at(11, 7, 0), wantQuiet: true},
+		{name: "overnight, after midnight", local: at(11, 3, 0), start: 22, end: 7, want: at(11, 7, 0), wantQuiet: true},
+		{name: "overnight, at the start", local: at(10, 22, 0), start: 22, end: 7, want: at(11, 7, 0), wantQuiet: true},
+		{name: "overnight, daytime", local: at(10, 12, 0), start: 22, end: 7},
+		{name: "ends at midnight", local: at(10, 23, 30), start: 21, end: 0, want: at(11, 0, 0), wantQuiet: true},
+		{
+			// The end is 7am local, whatever the UTC offset.
+			name:      "in the recipient's zone",
+			local:     time.Date(2026, 3, 10, 23, 0, 0, 0, ny),
+			start:     22,
+			end:       7,
+			want:      time.Date(2026, 3, 11, 7, 0, 0, 0, ny),
+			wantQuiet: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, quiet := QuietUntil(tt.local, tt.start, tt.end)
+			if quiet != tt.wantQuiet || !until.Equal(tt.want) {
+				t.Errorf("QuietUntil(%s, %d, %d) = %s, %v; want %s, %v", tt.local, tt.start, tt.end, until, quiet, tt.want, tt.wantQuiet)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Minute},
+		{1, time.Minute},
+		{2, 5 * time.Minute},
+		{3, 30 * time.Minute},
+		{4, 2 * time.Hour},
+		{5, 6 * time.Hour},
+		{12, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig points at a mail server. Username may be empty for local sinks
+// that accept anything.
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPChannel sends email, as multipart/alternative when the message has HTML.
+type SMTPChannel struct {
+	cfg SMTPConfig
+}
+
+func NewSMTPChannel(cfg SMTPConfig) *SMTPChannel {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTPChannel{cfg: cfg}
+}
+
+func (c *SMTPChannel) Name() string { return ChannelEmail }
+
+func (c *SMTPChannel) Send(ctx context.Context, d Delivery) error {
+	if d.Recipient.Email == "" {
+		return errors.New("recipient has no email address")
+	}
+	from, err := mail.ParseAddress(c.cfg.From)
+	if err != nil {
+		return fmt.Errorf("bad SMTP_FROM: %w", err)
+	}
+	msg, err := buildEmail(from.String(), d)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if c.cfg.Username != "" {
+		auth = smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.Host)
+	}
+	addr := net.JoinHostPort(c.cfg.Host, strconv.Itoa(c.cfg.Port))
+
+	// net/smtp has no context support, so race it against ctx.
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, from.Address, []string{d.Recipient.Email}, msg)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func buildEmail(from string, d Delivery) ([]byte, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", (&mail.Address{Name: d.Recipient.Name, Address: d.Recipient.Email}).String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", d.Message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@sbf-os>\r\n", d.ID)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if d.Message.HTML == "" {
+		buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQP(&buf, d.Message.Text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	for _, part := range []struct{ typ, body string }{
+		{"text/plain", d.Message.Text},
+		{"text/html", d.Message.HTML},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.typ + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQP(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQP(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
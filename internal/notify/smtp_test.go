@@ -0,0 +1,270 @@
+package notify
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestWriteQP(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "plain ASCII", in: "Book the venue."},
+		{name: "equals sign", in: "score = 70"},
+		{name: "non-ASCII", in: "Café déjà vu — 🎉"},
+		{name: "long line", in: strings.Repeat("word ", 40)},
+		{name: "newlines", in: "line one\nline two\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeQP(&buf, tt.in); err != nil {
+				t.Fatal(err)
+			}
+			for _, line := range strings.Split(buf.String(), "\r\n") {
+				if len(line) > 76 {
+					t.Errorf("encoded line is %d bytes: %q", len(line), line)
+				}
+				for _, r := range line {
+					if r > 127 {
+						t.Fatalf("encoded output isn't ASCII: %q", line)
+					}
+				}
+			}
+			decoded, err := io.ReadAll(quotedprintable.NewReader(&buf))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Quoted-printable lines end in CRLF on the wire.
+			if want := strings.ReplaceAll(tt.in, "\n", "\r\n"); string(decoded) != want {
+				t.Errorf("round-trip = %q, want %q", decoded, want)
+			}
+		})
+	}
+}
+
+func emailDelivery(html string) Delivery {
+	return Delivery{
+		ID:        uuid.MustParse("11111111-2222-3333-4444-555555555555"),
+		Recipient: Recipient{Name: "Zoë Park", Email: "zoe@example.com"},
+		Message:   Message{Kind: KindDigest, Subject: "Your digest — 3 tasks", Text: "Café booked.", HTML: html},
+	}
+}
+
+func TestBuildEmail(t *testing.T) {
+	t.Run("plain text", func(t *testing.T) {
+		raw, err := buildEmail(`"SBF" <ops@example.com>`, emailDelivery(""))
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeaders(t, msg)
+		if ct := msg.Header.Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, _ := io.ReadAll(quotedprintable.NewReader(msg.Body))
+		if string(body) != "Café booked." {
+			t.Errorf("body = %q", body)
+		}
+	})
+
+	t.Run("with HTML", func(t *testing.T) {
+		raw, err := buildEmail(`"SBF" <ops@example.com>`, emailDelivery("<p>Café <b>booked</b>.</p>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := mail.ReadMessage(bytes.NewReader(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkHeaders(t, msg)
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/alternative" {
+			t.Fatalf("Content-Type = %q (%v)", msg.Header.Get("Content-Type"), err)
+		}
+		// multipart.Reader undoes the quoted-printable encoding itself.
+		mr := multipart.NewReader(msg.Body, params["boundary"])
+		want := []struct{ typ, body string }{
+			{"text/plain; charset=utf-8", "Café booked."},
+			{"text/html; charset=utf-8", "<p>Café <b>booked</b>.</p>"},
+		}
+		for i, w := range want {
+			part, err := mr.NextPart()
+			if err != nil {
+				t.Fatalf("part %d: %v", i, err)
+			}
+			body, _ := io.ReadAll(part)
+			if part.Header.Get("Content-Type") != w.typ || string(body) != w.body {
+				t.Errorf("part %d = %q %q, want %q %q", i, part.Header.Get("Content-Type"), body, w.typ, w.body)
+			}
+		}
+		if _, err := mr.NextPart(); err != io.EOF {
+			t.Errorf("want exactly two parts, got more (%v)", err)
+		}
+	})
+}
+
+func checkHeaders(t *testing.T, msg *mail.Message) {
+	t.Helper()
+	if to, err := msg.Header.AddressList("To"); err != nil || len(to) != 1 || to[0].Name != "Zoë Park" || to[0].Address != "zoe@example.com" {
+		t.Errorf("To = %q (%v)", msg.Header.Get("To"), err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "Your digest — 3 tasks" {
+		t.Errorf("Subject = %q, decoded %q (%v)", msg.Header.Get("Subject"), subject, err)
+	}
+	if got := msg.Header.Get("Message-ID"); got != "<11111111-2222-3333-4444-555555555555@sbf-os>" {
+		t.Errorf("Message-ID = %q", got)
+	}
+	if _, err := msg.Header.Date(); err != nil {
+		t.Errorf("Date: %v", err)
+	}
+}
+
+// smtpTranscript is what a fake server received.
+type smtpTranscript struct {
+	from, to string
+	data     string
+}
+
+// fakeSMTP accepts one message on a loopback port, speaking just enough
+// SMTP for net/smtp (no STARTTLS, no AUTH).
+func fakeSMTP(t *testing.T) (host string, port int, got <-chan smtpTranscript) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan smtpTranscript, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+		reply := func(s string) { w.WriteString(s + "\r\n"); w.Flush() }
+
+		var tr smtpTranscript
+		reply("220 fake ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+			switch {
+			case verb == "EHLO" || verb == "HELO":
+				reply("250 fake")
+			case strings.HasPrefix(strings.ToUpper(line), "MAIL FROM:"):
+				tr.from = line[len("MAIL FROM:"):]
+				reply("250 ok")
+			case strings.HasPrefix(strings.ToUpper(line), "RCPT TO:"):
+				tr.to = line[len("RCPT TO:"):]
+				reply("250 ok")
+			case verb == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(l, "."))
+				}
+				tr.data = data.String()
+				reply("250 queued")
+			case verb == "QUIT":
+				reply("221 bye")
+				out <- tr
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, out
+}
+
+func TestSMTPChannelSend(t *testing.T) {
+	host, port, got := fakeSMTP(t)
+	ch := NewSMTPChannel(SMTPConfig{Host: host, Port: port, From: "SBF Ops <ops@example.com>"})
+	if err := ch.Send(context.Background(), emailDelivery("")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case tr := <-got:
+		if !strings.Contains(tr.from, "<ops@example.com>") || !strings.Contains(tr.to, "<zoe@example.com>") {
+			t.Errorf("envelope from %q to %q", tr.from, tr.to)
+		}
+		msg, err := mail.ReadMessage(strings.NewReader(tr.data))
+		if err != nil {
+			t.Fatalf("received message doesn't parse: %v", err)
+		}
+		checkHeaders(t, msg)
+		if from := msg.Header.Get("From"); from != `"SBF Ops" <ops@example.com>` {
+			t.Errorf("From = %q", from)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fake server received nothing")
+	}
+}
+
+func TestSMTPChannelSendErrors(t *testing.T) {
+	ch := NewSMTPChannel(SMTPConfig{Host: "127.0.0.1", Port: 1, From: "ops@example.com"})
+	if err := ch.Send(context.Background(), Delivery{}); err == nil || !strings.Contains(err.Error(), "no email address") {
+		t.Errorf("no recipient address = %v", err)
+	}
+	bad := NewSMTPChannel(SMTPConfig{Host: "127.0.0.1", Port: 1, From: "not an address"})
+	if err := bad.Send(context.Background(), emailDelivery("")); err == nil || !strings.Contains(err.Error(), "SMTP_FROM") {
+		t.Errorf("bad From = %v", err)
+	}
+
+	// A server that never greets can't hold Send past its context.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			defer conn.Close()
+			time.Sleep(5 * time.Second)
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	silent := NewSMTPChannel(SMTPConfig{Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port, From: "ops@example.com"})
+	if err := silent.Send(ctx, emailDelivery("")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("silent server = %v, want the context deadline", err)
+	}
+
+	if got := NewSMTPChannel(SMTPConfig{Host: "mail.example.com"}).cfg.Port; got != 587 {
+		t.Errorf("default port = %d, want 587", got)
+	}
+}
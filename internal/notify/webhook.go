@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Webhook headers. Receivers should check the signature and drop repeats of a
+// delivery ID, since a timed-out send is retried.
+const (
+	HeaderDelivery  = "X-SBF-Delivery"
+	HeaderTimestamp = "X-SBF-Timestamp"
+	HeaderSignature = "X-SBF-Signature"
+)
+
+// WebhookPayload is the JSON body POSTed to a person's webhook URL.
+type WebhookPayload struct {
+	DeliveryID string    `json:"delivery_id"`
+	PersonID   string    `json:"person_id"`
+	Kind       string    `json:"kind"`
+	Subject    string    `json:"subject"`
+	Text       string    `json:"text"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+// WebhookChannel POSTs JSON signed with HMAC-SHA256 over "timestamp.body".
+type WebhookChannel struct {
+	secret []byte
+	client *http.Client
+}
+
+// NewWebhookChannel sends from a client that refuses to connect to internal
+// addresses (see BlockedWebhookIP) unless allowPrivate is set. The check runs
+// on the address actually dialed, so DNS that changes between saving a URL
+// and sending to it can't sneak past, and neither can redirects.
+func NewWebhookChannel(secret string, allowPrivate bool) *WebhookChannel {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || BlockedWebhookIP(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		}
+	}
+	transport := &http.Transport{
+		// No proxy: it would dial on our behalf, past the check above.
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &WebhookChannel{
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 15 * time.Second, Transport: transport},
+	}
+}
+
+var errBlockedAddress = errors.New("webhook URL points at a private or local address")
+
+// cgnat is the shared address space (RFC 6598), internal on most networks.
+var cgnat = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// BlockedWebhookIP reports whether a webhook may not be sent to ip: loopback,
+// private (RFC 1918, fc00::/7), link-local (including 169.254.169.254, the
+// cloud metadata service), unspecified, multicast and CGNAT addresses.
+func BlockedWebhookIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() || ip.IsUnspecified() || cgnat.Contains(ip) ||
+		(ip.To4() != nil && ip.To4()[0] == 0)
+}
+
+// CheckWebhookURL validates a webhook URL when it's saved: a full http(s)
+// address whose host resolves only to addresses BlockedWebhookIP allows.
+// Sends are checked again at dial time.
+func CheckWebhookURL(ctx context.Context, raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.New("webhook URL must be a full http(s) address")
+	}
+	if allowPrivate {
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return fmt.Errorf("can't resolve %s", u.Hostname())
+	}
+	for _, a := range addrs {
+		if BlockedWebhookIP(a.IP) {
+			return errBlockedAddress
+		}
+	}
+	return nil
+}
+
+func (c *WebhookChannel) Name() string { return ChannelWebhook }
+
+func (c *WebhookChannel) Send(ctx context.Context, d Delivery) error {
+	if d.Recipient.WebhookURL == "" {
+		return errors.New("recipient has no webhook URL")
+	}
+	body, err := json.Marshal(WebhookPayload{
+		DeliveryID: d.ID.String(),
+		PersonID:   d.Recipient.PersonID.String(),
+		Kind:       d.Message.Kind,
+		Subject:    d.Message.Subject,
+		Text:       d.Message.Text,
+		SentAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.Recipient.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ts := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderDelivery, d.ID.String())
+	req.Header.Set(HeaderTimestamp, ts)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(c.secret, ts, body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Only the code is kept: the body (and reason phrase) are the receiver's
+	// text, and last_error is shown back to the person who set the URL.
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.body".
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a received webhook: the signature header must match and the
+// timestamp must be within tolerance of now, so captured requests can't be
+// replayed later.
+func Verify(secret []byte, r *http.Request, body []byte, tolerance time.Duration) error {
+	ts := r.Header.Get(HeaderTimestamp)
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return errors.New("missing or bad timestamp")
+	}
+	if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return errors.New("timestamp outside tolerance")
+	}
+	want := "sha256=" + Sign(secret, ts, body)
+	if !hmac.Equal([]byte(want), []byte(r.Header.Get(HeaderSignature))) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
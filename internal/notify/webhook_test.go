@@ -0,0 +1,238 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestBlockedWebhookIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"127.0.0.1", true},
+		{"127.8.9.10", true},
+		{"::1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"172.31.255.255", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"fe80::1", true},
+		{"fd00::1", true},
+		{"100.64.0.1", true},
+		{"100.127.255.255", true},
+		{"0.0.0.0", true},
+		{"0.1.2.3", true},
+		{"::", true},
+		{"224.0.0.1", true},
+		{"ff02::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"8.8.8.8", false},
+		{"172.32.0.1", false},
+		{"100.128.0.1", false},
+		{"2606:4700:4700::1111", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			ip := net.ParseIP(tt.ip)
+			if ip == nil {
+				t.Fatalf("bad test IP %q", tt.ip)
+			}
+			if got := BlockedWebhookIP(ip); got != tt.want {
+				t.Errorf("BlockedWebhookIP(%s) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	// IP literals resolve without DNS, so none of these touch the network.
+	tests := []struct {
+		name         string
+		url          string
+		allowPrivate bool
+		wantErr      string
+	}{
+		{name: "public address", url: "https://8.8.8.8/hooks/sbf"},
+		{name: "loopback", url: "http://127.0.0.1:8080/hook", wantErr: "private or local"},
+		{name: "IPv6 loopback", url: "http://[::1]/hook", wantErr: "private or local"},
+		{name: "private network", url: "https://192.168.0.10/hook", wantErr: "private or local"},
+		{name: "cloud metadata", url: "http://169.254.169.254/latest/meta-data", wantErr: "private or local"},
+		{name: "loopback allowed for local development", url: "http://127.0.0.1:8080/hook", allowPrivate: true},
+		{name: "not http", url: "ftp://8.8.8.8/hook", wantErr: "full http(s) address"},
+		{name: "no scheme", url: "8.8.8.8/hook", wantErr: "full http(s) address"},
+		{name: "no host", url: "https:///hook", wantErr: "full http(s) address"},
+		{name: "bad scheme even when private is allowed", url: "file:///etc/passwd", allowPrivate: true, wantErr: "full http(s) address"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckWebhookURL(context.Background(), tt.url, tt.allowPrivate)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("CheckWebhookURL(%q) = %v, want ok", tt.url, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("CheckWebhookURL(%q) = %v, want it to mention %q", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	secret, body := []byte("shh"), []byte(`{"kind":"test"}`)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("1700000000." + string(body)))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got := Sign(secret, "1700000000", body); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if Sign(secret, "1700000001", body) == want {
+		t.Error("signature should cover the timestamp")
+	}
+	if Sign([]byte("other"), "1700000000", body) == want {
+		t.Error("signature should depend on the secret")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	secret, body := []byte("shh"), []byte(`{"kind":"test"}`)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	old := strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name    string
+		ts      string
+		sig     string
+		body    []byte
+		wantErr string
+	}{
+		{name: "valid", ts: now, sig: "sha256=" + Sign(secret, now, body), body: body},
+		{name: "missing timestamp", sig: "sha256=" + Sign(secret, "", body), body: body, wantErr: "bad timestamp"},
+		{name: "garbage timestamp", ts: "yesterday", body: body, wantErr: "bad timestamp"},
+		{name: "replayed later", ts: old, sig: "sha256=" + Sign(secret, old, body), body: body, wantErr: "outside tolerance"},
+		{name: "from the future", ts: future, sig: "sha256=" + Sign(secret, future, body), body: body, wantErr: "outside tolerance"},
+		{name: "tampered body", ts: now, sig: "sha256=" + Sign(secret, now, body), body: []byte(`{"kind":"digest"}`), wantErr: "signature mismatch"},
+		{name: "wrong secret", ts: now, sig: "sha256=" + Sign([]byte("guess"), now, body), body: body, wantErr: "signature mismatch"},
+		{name: "missing prefix", ts: now, sig: Sign(secret, now, body), body: body, wantErr: "signature mismatch"},
+		{name: "no signature", ts: now, body: body, wantErr: "signature mismatch"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/hook", nil)
+			if tt.ts != "" {
+				r.Header.Set(HeaderTimestamp, tt.ts)
+			}
+			if tt.sig != "" {
+				r.Header.Set(HeaderSignature, tt.sig)
+			}
+			err := Verify(secret, r, tt.body, 5*time.Minute)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Verify() = %v, want ok", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func webhookDelivery(url string) Delivery {
+	return Delivery{
+		ID:        uuid.New(),
+		Recipient: Recipient{PersonID: uuid.New(), Name: "Sam", WebhookURL: url},
+		Message:   Message{Kind: KindTest, Subject: "Hello", Text: "It works."},
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	const secret = "shh"
+	var (
+		mu       sync.Mutex
+		failNext = true
+		seen     []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := Verify([]byte(secret), r, body, time.Minute); err != nil {
+			t.Errorf("receiver: %v", err)
+		}
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("receiver got %s with Content-Type %q", r.Method, r.Header.Get("Content-Type"))
+		}
+		var p WebhookPayload
+		if err := json.Unmarshal(body, &p); err != nil {
+			t.Errorf("receiver: bad payload: %v", err)
+		}
+		if p.DeliveryID != r.Header.Get(HeaderDelivery) || p.Kind != KindTest || p.Subject != "Hello" || p.Text != "It works." {
+			t.Errorf("receiver: payload %+v with delivery header %q", p, r.Header.Get(HeaderDelivery))
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		seen = append(seen, r.Header.Get(HeaderDelivery))
+		if failNext {
+			failNext = false
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("try later"))
+		}
+	}))
+	defer srv.Close()
+
+	// httptest listens on loopback, which only a development channel may reach.
+	ch := NewWebhookChannel(secret, true)
+	d := webhookDelivery(srv.URL + "/hook")
+
+	err := ch.Send(context.Background(), d)
+	if err == nil || err.Error() != "webhook returned HTTP 503" {
+		t.Fatalf("first send = %v, want the 503 without the receiver's text", err)
+	}
+	// Deliver retries with the same delivery, so the receiver can drop repeats.
+	if err := ch.Send(context.Background(), d); err != nil {
+		t.Fatalf("retry = %v", err)
+	}
+	if len(seen) != 2 || seen[0] != d.ID.String() || seen[1] != d.ID.String() {
+		t.Errorf("receiver saw deliveries %v, want %s twice", seen, d.ID)
+	}
+}
+
+func TestWebhookSendRefusesPrivateAddresses(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hit = true }))
+	defer srv.Close()
+
+	err := NewWebhookChannel("shh", false).Send(context.Background(), webhookDelivery(srv.URL+"/hook"))
+	if !errors.Is(err, errBlockedAddress) {
+		t.Errorf("Send to loopback = %v, want errBlockedAddress", err)
+	}
+	if hit {
+		t.Error("the loopback receiver was reached")
+	}
+
+	if err := NewWebhookChannel("shh", true).Send(context.Background(), webhookDelivery("")); err == nil {
+		t.Error("Send without a webhook URL should fail")
+	}
+}
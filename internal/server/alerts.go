@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// NOTIFICATIONS (GET)
+// Alerts on tasks you own, plus every alert in events you own. Open alerts
+// first; resolved ones stay visible for a week.
+func (s *Server) handleNotifications(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+	alerts, err := s.Q.ListPersonAlerts(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch alerts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, alerts)
+		return
+	}
+
+	var open, resolved []db.ListPersonAlertsRow
+	for _, a := range alerts {
+		if a.ResolvedAt.Valid {
+			resolved = append(resolved, a)
+		} else {
+			open = append(open, a)
+		}
+	}
+
+	data := struct {
+		Open     []db.ListPersonAlertsRow
+		Resolved []db.ListPersonAlertsRow
+	}{
+		Open:     open,
+		Resolved: resolved,
+	}
+	s.render(w, r, "notifications.html", data)
+}
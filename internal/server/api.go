@@ -0,0 +1,319 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// The JSON API under /api/v1 mirrors the HTML routes: same RBAC through
+// eventAccess, same audit trail through the shared task/event/member
+// helpers. Every response is an envelope, {"data": ...} on success (plus
+// "meta" on paginated lists) and {"error": {...}} on failure.
+
+// apiResponse wraps successful responses.
+type apiResponse struct {
+	Data interface{} `json:"data"`
+	Meta *apiMeta    `json:"meta,omitempty"`
+}
+
+// apiMeta accompanies paginated lists.
+type apiMeta struct {
+	// NextCursor is passed back as ?cursor= for the next page; null on the last.
+	NextCursor *string `json:"next_cursor"`
+	// Partial means a risk sort only ranked the first matches by
+	// due date; narrow the filters to reach the rest.
+	Partial bool `json:"partial,omitempty"`
+}
+
+// apiErrorResponse wraps failures.
+type apiErrorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Fields maps request body fields to what's wrong with them (422 only).
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+var apiErrorCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusUnauthorized:          "unauthorized",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusMethodNotAllowed:      "method_not_allowed",
+	http.StatusConflict:              "conflict",
+	http.StatusRequestEntityTooLarge: "too_large",
+	http.StatusUnprocessableEntity:   "invalid",
+	http.StatusInternalServerError:   "internal",
+}
+
+func apiJSON(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, apiResponse{Data: data})
+}
+
+// apiPage writes one page of a list with its cursor.
+func apiPage(w http.ResponseWriter, data interface{}, nextCursor string, partial bool) {
+	meta := &apiMeta{Partial: partial}
+	if nextCursor != "" {
+		meta.NextCursor = &nextCursor
+	}
+	writeJSON(w, http.StatusOK, apiResponse{Data: data, Meta: meta})
+}
+
+func apiFail(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, apiErrorResponse{Error: apiError{Code: apiErrorCodes[status], Message: msg}})
+}
+
+// apiInvalid reports field-level validation errors.
+func apiInvalid(w http.ResponseWriter, fields map[string]string) {
+	writeJSON(w, http.StatusUnprocessableEntity, apiErrorResponse{Error: apiError{
+		Code:    apiErrorCodes[http.StatusUnprocessableEntity],
+		Message: "Validation failed",
+		Fields:  fields,
+	}})
+}
+
+// apiMaxBody caps JSON request bodies.
+const apiMaxBody = 1 << 20
+
+// decodeJSON reads a request body strictly: unknown fields and trailing
+// data are errors. On failure the response is already written.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiMaxBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			apiFail(w, http.StatusRequestEntityTooLarge, "Request body too large")
+		case errors.Is(err, io.EOF):
+			apiFail(w, http.StatusBadRequest, "Request body must be a JSON object")
+		default:
+			apiFail(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		}
+		return false
+	}
+	if dec.More() {
+		apiFail(w, http.StatusBadRequest, "Invalid JSON: unexpected data after the object")
+		return false
+	}
+	return true
+}
+
+// apiURLID parses a UUID route parameter. On failure the response is already written.
+func apiURLID(w http.ResponseWriter, r *http.Request, param, what string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, param))
+	if err != nil {
+		apiFail(w, http.StatusBadRequest, "Invalid "+what+" ID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// apiAuthorizeEvent is eventAccess with a JSON error.
+func (s *Server) apiAuthorizeEvent(w http.ResponseWriter, r *http.Request, eventID uuid.UUID, min string) (string, bool) {
+	role, status, err := s.eventAccess(r.Context(), eventID, min)
+	if err != nil {
+		apiFail(w, status, err.Error())
+		return role, false
+	}
+	return role, true
+}
+
+// apiAuthorizeTask loads a live task and authorizes the caller against its event.
+func (s *Server) apiAuthorizeTask(w http.ResponseWriter, r *http.Request, taskID uuid.UUID, min string) (db.Task, string, bool) {
+	task, err := s.Q.GetTask(r.Context(), taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		apiFail(w, http.StatusNotFound, "Task not found")
+		return db.Task{}, "", false
+	}
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch task: "+err.Error())
+		return db.Task{}, "", false
+	}
+	role, ok := s.apiAuthorizeEvent(w, r, task.EventID, min)
+	return task, role, ok
+}
+
+// bearerToken extracts the token from "Authorization: Bearer <token>".
+func bearerToken(r *http.Request) (string, bool) {
+	h := r.Header.Get("Authorization")
+	if len(h) < 7 || !strings.EqualFold(h[:7], "bearer ") {
+		return "", false
+	}
+	token := strings.TrimSpace(h[7:])
+	return token, token != ""
+}
+
+// apiTokenLifetime is how long a token from POST /api/v1/auth/token lasts.
+const apiTokenLifetime = 30 * 24 * time.Hour
+
+// apiTokenPrefix marks our tokens so they're recognisable in configs and logs.
+const apiTokenPrefix = "sbf_"
+
+const apiTokenContextKey contextKey = "apiToken"
+
+// newAPIToken returns a random token and the hash stored for it.
+func newAPIToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, hashAPIToken(token), nil
+}
+
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// apiAuth accepts a bearer token, or falls back to the browser session. A
+// bad token is a 401 even with a valid cookie, so scripts don't silently act
+// as whoever is logged in. The token's owner becomes the current user, so
+// audit events carry them as the actor. Read-only tokens are held to GET
+// unless anyScope is set.
+func (s *Server) apiAuth(next http.Handler, anyScope bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			if _, loggedIn := currentUser(r.Context()); !loggedIn {
+				apiFail(w, http.StatusUnauthorized, "Authentication required: send Authorization: Bearer <token>")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		row, err := s.Q.GetApiTokenByHash(r.Context(), hashAPIToken(token))
+		if errors.Is(err, sql.ErrNoRows) {
+			apiFail(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+		if err != nil {
+			apiFail(w, http.StatusInternalServerError, "Authentication failed: "+err.Error())
+			return
+		}
+		person, err := s.Q.GetPerson(r.Context(), row.PersonID)
+		if err != nil {
+			apiFail(w, http.StatusUnauthorized, "Invalid or expired token")
+			return
+		}
+		if row.Scope == TokenScopeRead && !anyScope && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			apiFail(w, http.StatusForbidden, "Forbidden: this token is read-only")
+			return
+		}
+		if err := s.Q.TouchApiToken(r.Context(), row.ID); err != nil {
+			log.Printf("api: recording use of token %s: %v", row.ID, err)
+		}
+
+		ctx := context.WithValue(r.Context(), userContextKey, person)
+		ctx = context.WithValue(ctx, apiTokenContextKey, row)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+type apiTokenRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type apiToken struct {
+	Token     string     `json:"token"`
+	Name      string     `json:"name"`
+	Scope     string     `json:"scope" enum:"read,read_write"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// API: ISSUE TOKEN (POST)
+// Trades email + password for a read-write bearer token. Only its hash is
+// kept, so this response is the one chance to read it. Named, scoped or
+// long-lived tokens are minted under Settings → API tokens.
+func (s *Server) handleAPIIssueToken(w http.ResponseWriter, r *http.Request) {
+	var req apiTokenRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+
+	person, err := s.Q.GetPersonByEmail(r.Context(), sql.NullString{String: email, Valid: email != ""})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		apiFail(w, http.StatusInternalServerError, "Login failed: "+err.Error())
+		return
+	}
+	// Same message for unknown email, passwordless placeholder and bad password.
+	if err != nil || !person.PasswordHash.Valid ||
+		bcrypt.CompareHashAndPassword([]byte(person.PasswordHash.String), []byte(req.Password)) != nil {
+		apiFail(w, http.StatusUnauthorized, "Invalid email or password")
+		return
+	}
+
+	token, row, err := s.mintAPIToken(r.Context(), db.CreateApiTokenParams{
+		PersonID:  person.ID,
+		Name:      "API login",
+		Scope:     TokenScopeReadWrite,
+		ExpiresAt: sql.NullTime{Time: time.Now().Add(apiTokenLifetime), Valid: true},
+	})
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Token error: "+err.Error())
+		return
+	}
+	apiJSON(w, http.StatusCreated, apiToken{
+		Token:     token,
+		Name:      row.Name,
+		Scope:     row.Scope,
+		ExpiresAt: nullTimePtr(row.ExpiresAt),
+	})
+}
+
+// API: REVOKE TOKEN (DELETE)
+// Revokes the bearer token the request was made with.
+func (s *Server) handleAPIRevokeToken(w http.ResponseWriter, r *http.Request) {
+	row, ok := tokenFromContext(r.Context())
+	if !ok {
+		apiFail(w, http.StatusBadRequest, "Send the token to revoke as Authorization: Bearer <token>")
+		return
+	}
+	if _, err := s.Q.RevokeApiToken(r.Context(), db.RevokeApiTokenParams{ID: row.ID, PersonID: row.PersonID}); err != nil {
+		apiFail(w, http.StatusInternalServerError, "Revoke failed: "+err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) apiRoutes(r chi.Router) {
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		apiFail(w, http.StatusNotFound, "No such endpoint")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		apiFail(w, http.StatusMethodNotAllowed, "Method not allowed")
+	})
+
+	// The endpoint table drives both routing and /api/openapi.json.
+	for _, e := range s.apiEndpoints() {
+		h := http.Handler(e.Handler)
+		if !e.Public {
+			h = s.apiAuth(h, e.AnyScope)
+		}
+		r.Method(e.Method, e.Path, h)
+	}
+}
@@ -0,0 +1,499 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// apiDate is the wire format for DATE columns.
+const apiDate = "2006-01-02"
+
+type apiPerson struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+}
+
+type apiMe struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Email *string   `json:"email" format:"email"`
+}
+
+type apiTemplate struct {
+	ID          uuid.UUID         `json:"id"`
+	Name        string            `json:"name"`
+	Description *string           `json:"description"`
+	Tasks       []apiTemplateTask `json:"tasks,omitempty"`
+}
+
+type apiTemplateTask struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	Description *string   `json:"description"`
+	Category    string    `json:"category"`
+	Priority    int32     `json:"priority"`
+	// DaysBeforeEvent sets the due date when the template seeds an event.
+	DaysBeforeEvent int32 `json:"days_before_event"`
+}
+
+type apiEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	EventDate string    `json:"event_date" format:"date"`
+	Location  *string   `json:"location"`
+	Summary   *string   `json:"summary"`
+	// Role is the caller's role on the event.
+	Role string `json:"role" enum:"owner,editor,viewer"`
+}
+
+type apiEventSummary struct {
+	apiEvent
+	TotalTasks     int64 `json:"total_tasks"`
+	CompletedTasks int64 `json:"completed_tasks"`
+}
+
+type apiEventCreate struct {
+	Name       string     `json:"name"`
+	EventDate  string     `json:"event_date" format:"date"`
+	TemplateID *uuid.UUID `json:"template_id"`
+}
+
+// apiEventUpdate fields left out (or null) keep their current value.
+type apiEventUpdate struct {
+	Name      *string `json:"name"`
+	EventDate *string `json:"event_date" format:"date"`
+	Location  *string `json:"location"`
+	Summary   *string `json:"summary"`
+}
+
+type apiMember struct {
+	PersonID uuid.UUID `json:"person_id"`
+	Name     string    `json:"name"`
+	Email    *string   `json:"email"`
+	Role     string    `json:"role" enum:"owner,editor,viewer"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type apiMemberCreate struct {
+	Email string `json:"email" format:"email"`
+	Role  string `json:"role" enum:"owner,editor,viewer"`
+}
+
+type apiMemberUpdate struct {
+	Role string `json:"role" enum:"owner,editor,viewer"`
+}
+
+func nullStringPtr(v sql.NullString) *string {
+	if !v.Valid {
+		return nil
+	}
+	return &v.String
+}
+
+func newAPIEvent(e db.Event, role string) apiEvent {
+	return apiEvent{
+		ID:        e.ID,
+		Name:      e.Name,
+		EventDate: e.EventDate.Format(apiDate),
+		Location:  nullStringPtr(e.Location),
+		Summary:   nullStringPtr(e.Summary),
+		Role:      role,
+	}
+}
+
+// API: ME (GET)
+func (s *Server) handleAPIMe(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+	apiJSON(w, http.StatusOK, apiMe{ID: user.ID, Name: user.Name, Email: nullStringPtr(user.Email)})
+}
+
+// API: PEOPLE (GET)
+// Everyone with an account, as offered in the owner dropdowns.
+func (s *Server) handleAPIListPeople(w http.ResponseWriter, r *http.Request) {
+	people, err := s.Q.ListPeople(r.Context())
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch people: "+err.Error())
+		return
+	}
+	out := make([]apiPerson, 0, len(people))
+	for _, p := range people {
+		out = append(out, apiPerson{ID: p.ID, Name: p.Name})
+	}
+	apiJSON(w, http.StatusOK, out)
+}
+
+// API: TEMPLATES (GET)
+func (s *Server) handleAPIListTemplates(w http.ResponseWriter, r *http.Request) {
+	templates, err := s.Q.ListTemplates(r.Context())
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch templates: "+err.Error())
+		return
+	}
+	out := make([]apiTemplate, 0, len(templates))
+	for _, t := range templates {
+		out = append(out, apiTemplate{ID: t.ID, Name: t.Name, Description: nullStringPtr(t.Description)})
+	}
+	apiJSON(w, http.StatusOK, out)
+}
+
+// API: TEMPLATE (GET)
+// One template with the tasks it seeds.
+func (s *Server) handleAPIGetTemplate(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiURLID(w, r, "id", "template")
+	if !ok {
+		return
+	}
+	tmpl, found, err := s.findTemplate(r, id)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch templates: "+err.Error())
+		return
+	}
+	if !found {
+		apiFail(w, http.StatusNotFound, "Template not found")
+		return
+	}
+	tasks, err := s.Q.GetTemplateTasks(r.Context(), id)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch template tasks: "+err.Error())
+		return
+	}
+
+	out := apiTemplate{
+		ID:          tmpl.ID,
+		Name:        tmpl.Name,
+		Description: nullStringPtr(tmpl.Description),
+		Tasks:       make([]apiTemplateTask, 0, len(tasks)),
+	}
+	for _, t := range tasks {
+		out.Tasks = append(out.Tasks, apiTemplateTask{
+			ID:              t.ID,
+			Title:           t.Title,
+			Description:     nullStringPtr(t.Description),
+			Category:        t.Category,
+			Priority:        t.Priority,
+			DaysBeforeEvent: t.RelativeDueDays.Int32,
+		})
+	}
+	apiJSON(w, http.StatusOK, out)
+}
+
+// findTemplate looks a template up by ID; there are only ever a handful.
+func (s *Server) findTemplate(r *http.Request, id uuid.UUID) (db.Template, bool, error) {
+	templates, err := s.Q.ListTemplates(r.Context())
+	if err != nil {
+		return db.Template{}, false, err
+	}
+	for _, t := range templates {
+		if t.ID == id {
+			return t, true, nil
+		}
+	}
+	return db.Template{}, false, nil
+}
+
+// API: EVENTS (GET)
+// Events the caller is a member of (and their token covers), soonest first.
+func (s *Server) handleAPIListEvents(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+	events, err := s.Q.ListUserEvents(r.Context(), user.ID)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch events: "+err.Error())
+		return
+	}
+	token, hasToken := tokenFromContext(r.Context())
+	out := make([]apiEventSummary, 0, len(events))
+	for _, e := range events {
+		if hasToken && !tokenAllowsEvent(token, e.ID) {
+			continue
+		}
+		out = append(out, apiEventSummary{
+			apiEvent: apiEvent{
+				ID:        e.ID,
+				Name:      e.Name,
+				EventDate: e.EventDate.Format(apiDate),
+				Location:  nullStringPtr(e.Location),
+				Summary:   nullStringPtr(e.Summary),
+				Role:      e.UserRole,
+			},
+			TotalTasks:     e.TotalTasks,
+			CompletedTasks: e.CompletedTasks,
+		})
+	}
+	apiJSON(w, http.StatusOK, out)
+}
+
+// API: CREATE EVENT (POST)
+// The caller becomes the owner; a template seeds the event's tasks.
+func (s *Server) handleAPICreateEvent(w http.ResponseWriter, r *http.Request) {
+	if token, ok := tokenFromContext(r.Context()); ok && len(token.EventIds) > 0 {
+		apiFail(w, http.StatusForbidden, "Forbidden: this token is limited to specific events")
+		return
+	}
+	var req apiEventCreate
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	problems := map[string]string{}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		problems["name"] = "required"
+	}
+	date, err := time.Parse(apiDate, req.EventDate)
+	if err != nil {
+		problems["event_date"] = "must be a date like 2026-05-30"
+	}
+	var templateID uuid.NullUUID
+	if req.TemplateID != nil {
+		_, found, err := s.findTemplate(r, *req.TemplateID)
+		if err != nil {
+			apiFail(w, http.StatusInternalServerError, "Failed to fetch templates: "+err.Error())
+			return
+		}
+		if !found {
+			problems["template_id"] = "no such template"
+		}
+		templateID = uuid.NullUUID{UUID: *req.TemplateID, Valid: true}
+	}
+	if len(problems) > 0 {
+		apiInvalid(w, problems)
+		return
+	}
+
+	user, _ := currentUser(r.Context())
+	event, err := s.createEvent(r.Context(), user.ID, db.CreateEventParams{Name: name, EventDate: date}, templateID)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to create event: "+err.Error())
+		return
+	}
+	apiJSON(w, http.StatusCreated, newAPIEvent(event, RoleOwner))
+}
+
+// API: EVENT (GET)
+func (s *Server) handleAPIGetEvent(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	role, ok := s.apiAuthorizeEvent(w, r, eventID, RoleViewer)
+	if !ok {
+		return
+	}
+	event, err := s.Q.GetEvent(r.Context(), eventID)
+	if err != nil {
+		apiFail(w, http.StatusNotFound, "Event not found")
+		return
+	}
+	apiJSON(w, http.StatusOK, newAPIEvent(event, role))
+}
+
+// API: UPDATE EVENT (PATCH)
+func (s *Server) handleAPIUpdateEvent(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	role, ok := s.apiAuthorizeEvent(w, r, eventID, RoleEditor)
+	if !ok {
+		return
+	}
+	var req apiEventUpdate
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	params := db.UpdateEventParams{ID: eventID}
+	problems := map[string]string{}
+	if req.Name != nil {
+		if name := strings.TrimSpace(*req.Name); name == "" {
+			problems["name"] = "can't be blank"
+		} else {
+			params.Name = sql.NullString{String: name, Valid: true}
+		}
+	}
+	if req.EventDate != nil {
+		date, err := time.Parse(apiDate, *req.EventDate)
+		if err != nil {
+			problems["event_date"] = "must be a date like 2026-05-30"
+		}
+		params.EventDate = sql.NullTime{Time: date, Valid: err == nil}
+	}
+	if req.Location != nil {
+		params.Location = sql.NullString{String: *req.Location, Valid: true}
+	}
+	if req.Summary != nil {
+		params.Summary = sql.NullString{String: *req.Summary, Valid: true}
+	}
+	if len(problems) > 0 {
+		apiInvalid(w, problems)
+		return
+	}
+
+	event, err := s.Q.UpdateEvent(r.Context(), params)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Update failed: "+err.Error())
+		return
+	}
+	apiJSON(w, http.StatusOK, newAPIEvent(event, role))
+}
+
+// API: MEMBERS (GET)
+func (s *Server) handleAPIListMembers(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	if _, ok := s.apiAuthorizeEvent(w, r, eventID, RoleViewer); !ok {
+		return
+	}
+	members, err := s.Q.ListEventMembers(r.Context(), eventID)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch members: "+err.Error())
+		return
+	}
+	out := make([]apiMember, 0, len(members))
+	for _, m := range members {
+		out = append(out, apiMember{
+			PersonID: m.PersonID,
+			Name:     m.Name,
+			Email:    nullStringPtr(m.Email),
+			Role:     m.Role,
+			JoinedAt: m.CreatedAt,
+		})
+	}
+	apiJSON(w, http.StatusOK, out)
+}
+
+// API: ADD MEMBER (POST)
+// Owners invite an existing account by email.
+func (s *Server) handleAPIAddMember(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	if _, ok := s.apiAuthorizeEvent(w, r, eventID, RoleOwner); !ok {
+		return
+	}
+	var req apiMemberCreate
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !validRole(req.Role) {
+		apiInvalid(w, map[string]string{"role": "must be owner, editor or viewer"})
+		return
+	}
+
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	person, err := s.Q.GetPersonByEmail(r.Context(), sql.NullString{String: email, Valid: email != ""})
+	if errors.Is(err, sql.ErrNoRows) {
+		apiInvalid(w, map[string]string{"email": "no account uses this email"})
+		return
+	}
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Invite failed: "+err.Error())
+		return
+	}
+
+	_, err = s.Q.GetEventMembership(r.Context(), db.GetEventMembershipParams{EventID: eventID, PersonID: person.ID})
+	if err == nil {
+		apiFail(w, http.StatusConflict, person.Name+" is already a member; PATCH their role instead")
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		apiFail(w, http.StatusInternalServerError, "Invite failed: "+err.Error())
+		return
+	}
+
+	if err := s.Q.AddEventMember(r.Context(), db.AddEventMemberParams{
+		EventID:  eventID,
+		PersonID: person.ID,
+		Role:     req.Role,
+	}); err != nil {
+		apiFail(w, http.StatusInternalServerError, "Invite failed: "+err.Error())
+		return
+	}
+	apiJSON(w, http.StatusCreated, apiMember{
+		PersonID: person.ID,
+		Name:     person.Name,
+		Email:    nullStringPtr(person.Email),
+		Role:     req.Role,
+		JoinedAt: time.Now(),
+	})
+}
+
+// API: UPDATE MEMBER (PATCH)
+func (s *Server) handleAPIUpdateMember(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	personID, ok := apiURLID(w, r, "personID", "person")
+	if !ok {
+		return
+	}
+	if _, ok := s.apiAuthorizeEvent(w, r, eventID, RoleOwner); !ok {
+		return
+	}
+	var req apiMemberUpdate
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	if !validRole(req.Role) {
+		apiInvalid(w, map[string]string{"role": "must be owner, editor or viewer"})
+		return
+	}
+
+	err := s.setMemberRole(r.Context(), eventID, personID, req.Role)
+	if !apiMemberChangeOK(w, err) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// API: REMOVE MEMBER (DELETE)
+// Owners can remove anyone; everyone else can only leave.
+func (s *Server) handleAPIRemoveMember(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	personID, ok := apiURLID(w, r, "personID", "person")
+	if !ok {
+		return
+	}
+	user, _ := currentUser(r.Context())
+	min := RoleOwner
+	if user.ID == personID {
+		min = RoleViewer
+	}
+	if _, ok := s.apiAuthorizeEvent(w, r, eventID, min); !ok {
+		return
+	}
+
+	err := s.removeMember(r.Context(), eventID, personID)
+	if !apiMemberChangeOK(w, err) {
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func apiMemberChangeOK(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, errLastOwner):
+		apiFail(w, http.StatusConflict, "Can't do that: "+err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		apiFail(w, http.StatusNotFound, "Member not found")
+	case err != nil:
+		apiFail(w, http.StatusInternalServerError, "Membership update failed: "+err.Error())
+	default:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,446 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+type apiTask struct {
+	ID           uuid.UUID       `json:"id"`
+	EventID      uuid.UUID       `json:"event_id"`
+	Title        string          `json:"title"`
+	Description  *string         `json:"description"`
+	Status       string          `json:"status" enum:"backlog,in_progress,blocked,done"`
+	Priority     int32           `json:"priority"`
+	Category     string          `json:"category"`
+	DueDate      *string         `json:"due_date" format:"date"`
+	OwnerID      *uuid.UUID      `json:"owner_id"`
+	OwnerName    *string         `json:"owner_name,omitempty"`
+	AssigneeText *string         `json:"assignee_text"`
+	Tags         []string        `json:"tags"`
+	Subtasks     []logic.Subtask `json:"subtasks"`
+	CompletedAt  *time.Time      `json:"completed_at"`
+	LastUpdateAt *time.Time      `json:"last_update_at"`
+	CreatedAt    time.Time       `json:"created_at"`
+	// Risk is only filled in on listings.
+	Risk *apiRisk `json:"risk,omitempty"`
+}
+
+type apiRisk struct {
+	Score int    `json:"score"`
+	Level string `json:"level" enum:"high,med,low"`
+}
+
+type apiTaskCreate struct {
+	Title        string          `json:"title"`
+	Description  *string         `json:"description"`
+	Priority     *int32          `json:"priority"`
+	Category     *string         `json:"category"`
+	DueDate      *string         `json:"due_date" format:"date"`
+	OwnerID      *uuid.UUID      `json:"owner_id"`
+	AssigneeText *string         `json:"assignee_text"`
+	Tags         []string        `json:"tags"`
+	Subtasks     []logic.Subtask `json:"subtasks"`
+}
+
+// apiTaskUpdate fields left out (or null) keep their current value.
+type apiTaskUpdate struct {
+	Title        *string          `json:"title"`
+	Description  *string          `json:"description"`
+	Status       *string          `json:"status" enum:"backlog,in_progress,blocked,done"`
+	Priority     *int32           `json:"priority"`
+	Category     *string          `json:"category"`
+	DueDate      *string          `json:"due_date" format:"date"`
+	OwnerID      *uuid.UUID       `json:"owner_id"`
+	AssigneeText *string          `json:"assignee_text"`
+	Subtasks     *[]logic.Subtask `json:"subtasks"`
+}
+
+type apiTaskEvent struct {
+	ID        uuid.UUID       `json:"id"`
+	EventType string          `json:"event_type" enum:"CREATED,UPDATED,DELETED,BATCH_DELETED,RESTORED,DEPENDENCY_ADDED,DEPENDENCY_REMOVED"`
+	Changes   json.RawMessage `json:"changes"`
+	ActorID   *uuid.UUID      `json:"actor_id"`
+	ActorName *string         `json:"actor_name"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+var taskStatuses = []string{"backlog", "in_progress", "blocked", "done"}
+
+func validStatus(status string) bool {
+	for _, s := range taskStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+func nullTimePtr(v sql.NullTime) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Time
+}
+
+func nullUUIDPtr(v uuid.NullUUID) *uuid.UUID {
+	if !v.Valid {
+		return nil
+	}
+	return &v.UUID
+}
+
+func newAPITask(t db.Task) apiTask {
+	out := apiTask{
+		ID:           t.ID,
+		EventID:      t.EventID,
+		Title:        t.Title,
+		Description:  nullStringPtr(t.Description),
+		Status:       t.Status,
+		Priority:     t.Priority,
+		Category:     t.Category,
+		OwnerID:      nullUUIDPtr(t.OwnerID),
+		AssigneeText: nullStringPtr(t.AssigneeText),
+		Tags:         t.Tags,
+		Subtasks:     []logic.Subtask{},
+		CompletedAt:  nullTimePtr(t.CompletedAt),
+		LastUpdateAt: nullTimePtr(t.LastUpdateAt),
+		CreatedAt:    t.CreatedAt,
+	}
+	if t.DueDate.Valid {
+		d := t.DueDate.Time.Format(apiDate)
+		out.DueDate = &d
+	}
+	if out.Tags == nil {
+		out.Tags = []string{}
+	}
+	if t.Subtasks.Valid {
+		_ = json.Unmarshal(t.Subtasks.RawMessage, &out.Subtasks)
+	}
+	return out
+}
+
+// apiTaskFields validates the fields shared by create and update, adding
+// problems keyed by field name.
+func (s *Server) apiTaskFields(r *http.Request, problems map[string]string, priority *int32, dueDate *string, ownerID *uuid.UUID, subtasks *[]logic.Subtask) (sql.NullTime, uuid.NullUUID, pqtype.NullRawMessage) {
+	if priority != nil && (*priority < 1 || *priority > 5) {
+		problems["priority"] = "must be between 1 and 5"
+	}
+
+	var due sql.NullTime
+	if dueDate != nil {
+		d, err := time.Parse(apiDate, *dueDate)
+		if err != nil {
+			problems["due_date"] = "must be a date like 2026-05-30"
+		}
+		due = sql.NullTime{Time: d, Valid: err == nil}
+	}
+
+	var owner uuid.NullUUID
+	if ownerID != nil {
+		if _, err := s.Q.GetPerson(r.Context(), *ownerID); err != nil {
+			problems["owner_id"] = "no such person"
+		}
+		owner = uuid.NullUUID{UUID: *ownerID, Valid: true}
+	}
+
+	var subs pqtype.NullRawMessage
+	if subtasks != nil {
+		if len(*subtasks) > logic.MaxSubtasks {
+			problems["subtasks"] = "too many items"
+		}
+		for _, st := range *subtasks {
+			title := strings.TrimSpace(st.Title)
+			if title == "" || utf8.RuneCountInString(title) > logic.MaxSubtaskTitle {
+				problems["subtasks"] = "every item needs a title of at most 120 characters"
+				break
+			}
+		}
+		list := *subtasks
+		if list == nil {
+			list = []logic.Subtask{}
+		}
+		b, _ := json.Marshal(list)
+		subs = pqtype.NullRawMessage{RawMessage: b, Valid: true}
+	}
+	return due, owner, subs
+}
+
+func nullStringFrom(v *string) sql.NullString {
+	if v == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *v, Valid: true}
+}
+
+// API: TASKS (GET)
+// Live tasks of an event, every status unless ?status= narrows it. Takes the
+// same filters as the event page, ?sort= (due by default) and ?cursor= from
+// the previous page's meta.
+func (s *Server) handleAPIListTasks(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	if _, ok := s.apiAuthorizeEvent(w, r, eventID, RoleViewer); !ok {
+		return
+	}
+	// Every status by default, like the event page with "Show Completed".
+	q, problems := parseTaskQuery(r.URL.Query(), nil, SortDue)
+	if len(problems) > 0 {
+		apiInvalid(w, problems)
+		return
+	}
+	user, _ := currentUser(r.Context())
+	page, err := s.listTasks(r.Context(), user.ID, uuid.NullUUID{UUID: eventID, Valid: true}, q)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch tasks: "+err.Error())
+		return
+	}
+	out := make([]apiTask, 0, len(page.Tasks))
+	for _, st := range page.Tasks {
+		t := st.Task.(db.ListTasksRow)
+		task := newAPITask(db.Task{
+			ID: t.ID, Title: t.Title, Description: t.Description, OwnerID: t.OwnerID,
+			Status: t.Status, Priority: t.Priority, DueDate: t.DueDate, Tags: t.Tags,
+			LastUpdateAt: t.LastUpdateAt, CreatedAt: t.CreatedAt, EventID: t.EventID,
+			Category: t.Category, CompletedAt: t.CompletedAt, AssigneeText: t.AssigneeText,
+			Subtasks: t.Subtasks,
+		})
+		task.OwnerName = nullStringPtr(t.OwnerName)
+		task.Risk = &apiRisk{Score: st.Score, Level: st.RiskLevel}
+		out = append(out, task)
+	}
+	apiPage(w, out, page.NextCursor, page.Partial)
+}
+
+// API: CREATE TASK (POST)
+func (s *Server) handleAPICreateTask(w http.ResponseWriter, r *http.Request) {
+	eventID, ok := apiURLID(w, r, "id", "event")
+	if !ok {
+		return
+	}
+	if _, ok := s.apiAuthorizeEvent(w, r, eventID, RoleEditor); !ok {
+		return
+	}
+	var req apiTaskCreate
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	problems := map[string]string{}
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		problems["title"] = "required"
+	}
+	var subtasks *[]logic.Subtask
+	if req.Subtasks != nil {
+		subtasks = &req.Subtasks
+	}
+	due, owner, subs := s.apiTaskFields(r, problems, req.Priority, req.DueDate, req.OwnerID, subtasks)
+	if len(problems) > 0 {
+		apiInvalid(w, problems)
+		return
+	}
+
+	params := db.CreateTaskParams{
+		Title:        title,
+		Description:  nullStringFrom(req.Description),
+		OwnerID:      owner,
+		AssigneeText: nullStringFrom(req.AssigneeText),
+		Subtasks:     subs,
+		Priority:     3,
+		DueDate:      due,
+		Tags:         req.Tags,
+		EventID:      eventID,
+		Category:     "general",
+	}
+	if req.Priority != nil {
+		params.Priority = *req.Priority
+	}
+	if req.Category != nil && strings.TrimSpace(*req.Category) != "" {
+		params.Category = strings.TrimSpace(*req.Category)
+	}
+	if params.Tags == nil {
+		params.Tags = []string{}
+	}
+
+	task, err := s.createTask(r.Context(), params)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Error creating task: "+err.Error())
+		return
+	}
+	apiJSON(w, http.StatusCreated, newAPITask(task))
+}
+
+// API: TASK (GET)
+func (s *Server) handleAPIGetTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := apiURLID(w, r, "id", "task")
+	if !ok {
+		return
+	}
+	task, _, ok := s.apiAuthorizeTask(w, r, taskID, RoleViewer)
+	if !ok {
+		return
+	}
+	apiJSON(w, http.StatusOK, newAPITask(task))
+}
+
+// API: UPDATE TASK (PATCH)
+// Marking a task done while its dependencies are open is a 409.
+func (s *Server) handleAPIUpdateTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := apiURLID(w, r, "id", "task")
+	if !ok {
+		return
+	}
+	if _, _, ok := s.apiAuthorizeTask(w, r, taskID, RoleEditor); !ok {
+		return
+	}
+	var req apiTaskUpdate
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	problems := map[string]string{}
+	params := db.UpdateTaskParams{
+		ID:           taskID,
+		Description:  nullStringFrom(req.Description),
+		AssigneeText: nullStringFrom(req.AssigneeText),
+	}
+	if req.Title != nil {
+		if title := strings.TrimSpace(*req.Title); title == "" {
+			problems["title"] = "can't be blank"
+		} else {
+			params.Title = sql.NullString{String: title, Valid: true}
+		}
+	}
+	if req.Status != nil {
+		if !validStatus(*req.Status) {
+			problems["status"] = "must be one of " + strings.Join(taskStatuses, ", ")
+		}
+		params.Status = nullStringFrom(req.Status)
+	}
+	if req.Priority != nil {
+		params.Priority = sql.NullInt32{Int32: *req.Priority, Valid: true}
+	}
+	if req.Category != nil && strings.TrimSpace(*req.Category) != "" {
+		params.Category = sql.NullString{String: strings.TrimSpace(*req.Category), Valid: true}
+	}
+	params.DueDate, params.OwnerID, params.Subtasks = s.apiTaskFields(r, problems, req.Priority, req.DueDate, req.OwnerID, req.Subtasks)
+	if len(problems) > 0 {
+		apiInvalid(w, problems)
+		return
+	}
+
+	task, err := s.updateTask(r.Context(), params)
+	var blocked *errBlocked
+	switch {
+	case errors.As(err, &blocked):
+		apiFail(w, http.StatusConflict, "Can't mark done: "+blocked.Error())
+		return
+	case errors.Is(err, sql.ErrNoRows):
+		apiFail(w, http.StatusNotFound, "Task not found")
+		return
+	case err != nil:
+		apiFail(w, http.StatusInternalServerError, "Update failed: "+err.Error())
+		return
+	}
+	apiJSON(w, http.StatusOK, newAPITask(task))
+}
+
+// API: DELETE TASK (DELETE)
+// Moves the task to the trash; POST .../restore brings it back.
+func (s *Server) handleAPIDeleteTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := apiURLID(w, r, "id", "task")
+	if !ok {
+		return
+	}
+	if _, _, ok := s.apiAuthorizeTask(w, r, taskID, RoleEditor); !ok {
+		return
+	}
+	_, err := s.deleteTask(r.Context(), taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		apiFail(w, http.StatusNotFound, "Task not found")
+		return
+	}
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Delete failed: "+err.Error())
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// API: RESTORE TASK (POST)
+func (s *Server) handleAPIRestoreTask(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := apiURLID(w, r, "id", "task")
+	if !ok {
+		return
+	}
+	task, err := s.Q.GetTaskIncludingDeleted(r.Context(), taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		apiFail(w, http.StatusNotFound, "Task not found")
+		return
+	}
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch task: "+err.Error())
+		return
+	}
+	if _, ok := s.apiAuthorizeEvent(w, r, task.EventID, RoleEditor); !ok {
+		return
+	}
+	if !task.DeletedAt.Valid {
+		apiFail(w, http.StatusConflict, "Task is not in the trash")
+		return
+	}
+
+	if _, err := s.restoreTasks(r, task.EventID, []uuid.UUID{taskID}); err != nil {
+		apiFail(w, http.StatusInternalServerError, "Restore failed: "+err.Error())
+		return
+	}
+	restored, err := s.Q.GetTask(r.Context(), taskID)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch task: "+err.Error())
+		return
+	}
+	apiJSON(w, http.StatusOK, newAPITask(restored))
+}
+
+// API: TASK HISTORY (GET)
+// The task's audit ledger, newest first.
+func (s *Server) handleAPITaskEvents(w http.ResponseWriter, r *http.Request) {
+	taskID, ok := apiURLID(w, r, "id", "task")
+	if !ok {
+		return
+	}
+	if _, _, ok := s.apiAuthorizeTask(w, r, taskID, RoleViewer); !ok {
+		return
+	}
+	events, err := s.Q.GetTaskEvents(r.Context(), taskID)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Failed to fetch history: "+err.Error())
+		return
+	}
+	out := make([]apiTaskEvent, 0, len(events))
+	for _, e := range events {
+		out = append(out, apiTaskEvent{
+			ID:        e.ID,
+			EventType: e.EventType,
+			Changes:   e.Changes,
+			ActorID:   nullUUIDPtr(e.ActorID),
+			ActorName: nullStringPtr(e.ActorName),
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	apiJSON(w, http.StatusOK, out)
+}
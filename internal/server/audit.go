@@ -0,0 +1,40 @@
+package server
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// actorID is the audit "who" for the current request (NULL for system work).
+func actorID(ctx context.Context) uuid.NullUUID {
+	if p, ok := currentUser(ctx); ok {
+		return uuid.NullUUID{UUID: p.ID, Valid: true}
+	}
+	return uuid.NullUUID{}
+}
+
+// recordTaskEvent appends a task_events row stamped with the acting user.
+// Pass the transaction's queries so the ledger commits or rolls back with the change.
+// Entries that bump last_update_at count as a touch, so open staleness alerts
+// on the task resolve too.
+func recordTaskEvent(ctx context.Context, qtx *db.Queries, taskID uuid.UUID, eventType string, changes []byte) error {
+	if err := qtx.CreateTaskEvent(ctx, db.CreateTaskEventParams{
+		TaskID:    taskID,
+		EventType: eventType,
+		Changes:   changes,
+		ActorID:   actorID(ctx),
+	}); err != nil {
+		return err
+	}
+	if !logic.TouchesTask(eventType) {
+		return nil
+	}
+	return qtx.ResolveTouchedTaskAlerts(ctx, db.ResolveTouchedTaskAlertsParams{
+		TaskID:  taskID,
+		Column2: logic.TouchResolvedRules,
+	})
+}
@@ -0,0 +1,205 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+const (
+	sessionPersonKey  = "personID"
+	minPasswordLength = 8
+)
+
+type contextKey string
+
+const userContextKey contextKey = "currentUser"
+
+// currentUser returns the person attached to the request by loadCurrentUser.
+func currentUser(ctx context.Context) (db.Person, bool) {
+	p, ok := ctx.Value(userContextKey).(db.Person)
+	return p, ok
+}
+
+// loadCurrentUser resolves the session's person (if any) and stores it in the
+// request context. Anonymous requests pass through untouched.
+func (s *Server) loadCurrentUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		idStr := s.Session.GetString(r.Context(), sessionPersonKey)
+		if idStr == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		personID, err := uuid.Parse(idStr)
+		if err != nil {
+			s.Session.Remove(r.Context(), sessionPersonKey)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		person, err := s.Q.GetPerson(r.Context(), personID)
+		if err != nil {
+			// Person was deleted (or DB hiccup): treat as logged out.
+			s.Session.Remove(r.Context(), sessionPersonKey)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), userContextKey, person)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// requireLogin redirects anonymous visitors to /login, remembering where
+// they were headed.
+func (s *Server) requireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := currentUser(r.Context()); !ok {
+			http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// safeRedirect only allows local paths so ?next= can't bounce users off-site.
+func safeRedirect(next string) string {
+	if next == "" || !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") {
+		return "/"
+	}
+	return next
+}
+
+// startSession rotates the session token (prevents fixation) and binds it to the person.
+func (s *Server) startSession(ctx context.Context, personID uuid.UUID) error {
+	if err := s.Session.RenewToken(ctx); err != nil {
+		return err
+	}
+	s.Session.Put(ctx, sessionPersonKey, personID.String())
+	return nil
+}
+
+type authForm struct {
+	Name  string
+	Email string
+	Next  string
+	Error string
+}
+
+// LOGIN
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	form := authForm{Next: r.URL.Query().Get("next")}
+
+	if r.Method == http.MethodGet {
+		if _, ok := currentUser(r.Context()); ok {
+			http.Redirect(w, r, safeRedirect(form.Next), http.StatusSeeOther)
+			return
+		}
+		s.render(w, r, "login.html", form)
+		return
+	}
+
+	form.Email = strings.ToLower(strings.TrimSpace(r.FormValue("email")))
+	form.Next = r.FormValue("next")
+	password := r.FormValue("password")
+
+	person, err := s.Q.GetPersonByEmail(r.Context(), sql.NullString{String: form.Email, Valid: form.Email != ""})
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Login failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Same message for unknown email, passwordless placeholder and bad password.
+	if err != nil || !person.PasswordHash.Valid ||
+		bcrypt.CompareHashAndPassword([]byte(person.PasswordHash.String), []byte(password)) != nil {
+		form.Error = "Invalid email or password."
+		w.WriteHeader(http.StatusUnauthorized)
+		s.render(w, r, "login.html", form)
+		return
+	}
+
+	if err := s.startSession(r.Context(), person.ID); err != nil {
+		http.Error(w, "Session error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, safeRedirect(form.Next), http.StatusSeeOther)
+}
+
+// SIGNUP
+func (s *Server) handleSignup(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		s.render(w, r, "signup.html", authForm{})
+		return
+	}
+
+	form := authForm{
+		Name:  strings.TrimSpace(r.FormValue("name")),
+		Email: strings.ToLower(strings.TrimSpace(r.FormValue("email"))),
+	}
+	password := r.FormValue("password")
+
+	switch {
+	case form.Name == "" || form.Email == "":
+		form.Error = "Name and email are required."
+	case !strings.Contains(form.Email, "@"):
+		form.Error = "Please enter a valid email address."
+	case len(password) < minPasswordLength:
+		form.Error = "Password must be at least 8 characters."
+	case password != r.FormValue("password_confirm"):
+		form.Error = "Passwords do not match."
+	}
+	if form.Error == "" {
+		_, err := s.Q.GetPersonByEmail(r.Context(), sql.NullString{String: form.Email, Valid: true})
+		if err == nil {
+			form.Error = "An account with that email already exists."
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Signup failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if form.Error != "" {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		s.render(w, r, "signup.html", form)
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		http.Error(w, "Signup failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	person, err := s.Q.CreatePerson(r.Context(), db.CreatePersonParams{
+		Name:         form.Name,
+		Email:        sql.NullString{String: form.Email, Valid: true},
+		PasswordHash: sql.NullString{String: string(hash), Valid: true},
+	})
+	if err != nil {
+		http.Error(w, "Signup failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.startSession(r.Context(), person.ID); err != nil {
+		http.Error(w, "Session error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// LOGOUT
+func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	if err := s.Session.Destroy(r.Context()); err != nil {
+		http.Error(w, "Logout failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// Event roles, matching the CHECK constraint on event_members.role.
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleOwner:  3,
+}
+
+func validRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// roleAtLeast reports whether role grants at least the permissions of min.
+func roleAtLeast(role, min string) bool {
+	return roleRank[role] >= roleRank[min]
+}
+
+// eventAccess resolves the caller's role on an event and checks it against
+// min. Non-members get a 404 so events they can't see stay hidden; members
+// without enough rights, or calling with a token limited to other events,
+// get a 403. On failure it returns the status to send.
+func (s *Server) eventAccess(ctx context.Context, eventID uuid.UUID, min string) (string, int, error) {
+	user, ok := currentUser(ctx)
+	if !ok {
+		return "", http.StatusUnauthorized, errors.New("Login required")
+	}
+	if token, ok := tokenFromContext(ctx); ok && !tokenAllowsEvent(token, eventID) {
+		return "", http.StatusForbidden, errors.New("Forbidden: this token is limited to other events")
+	}
+
+	role, err := s.Q.GetEventMembership(ctx, db.GetEventMembershipParams{
+		EventID:  eventID,
+		PersonID: user.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", http.StatusNotFound, errors.New("Event not found")
+	}
+	if err != nil {
+		return "", http.StatusInternalServerError, errors.New("Authorization failed: " + err.Error())
+	}
+
+	if !roleAtLeast(role, min) {
+		return role, http.StatusForbidden, errors.New("Forbidden: your role on this event is " + role)
+	}
+	return role, http.StatusOK, nil
+}
+
+// authorizeEvent is eventAccess for HTML handlers. On failure the response
+// is already written.
+func (s *Server) authorizeEvent(w http.ResponseWriter, r *http.Request, eventID uuid.UUID, min string) (string, bool) {
+	role, status, err := s.eventAccess(r.Context(), eventID, min)
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return role, false
+	}
+	return role, true
+}
+
+// authorizeTask loads a live task and authorizes the caller against its event.
+func (s *Server) authorizeTask(w http.ResponseWriter, r *http.Request, taskID uuid.UUID, min string) (db.Task, string, bool) {
+	task, err := s.Q.GetTask(r.Context(), taskID)
+	if err != nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return db.Task{}, "", false
+	}
+
+	role, ok := s.authorizeEvent(w, r, task.EventID, min)
+	return task, role, ok
+}
+
+// authorizeTasks checks every event touched by a set of task IDs.
+func (s *Server) authorizeTasks(w http.ResponseWriter, r *http.Request, taskIDs []uuid.UUID, min string) bool {
+	eventIDs, err := s.Q.ListTaskEventIDs(r.Context(), taskIDs)
+	if err != nil {
+		http.Error(w, "Authorization failed: "+err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	for _, eventID := range eventIDs {
+		if _, ok := s.authorizeEvent(w, r, eventID, min); !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,235 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// briefingWindows are the look-back choices offered on the briefing page.
+var briefingWindows = []int{1, 3, 7, 14}
+
+// briefingView is a stored briefing with its report decoded.
+type briefingView struct {
+	ID            uuid.UUID      `json:"id"`
+	Source        string         `json:"source"`
+	Days          int32          `json:"days"`
+	CreatedAt     time.Time      `json:"created_at"`
+	CreatedByName string         `json:"created_by,omitempty"`
+	Report        logic.Briefing `json:"report"`
+}
+
+// EVENT BRIEFINGS (GET)
+// Shows the latest briefing, or ?b=<id> for an older one, with the history.
+func (s *Server) handleEventBriefings(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	role, ok := s.authorizeEvent(w, r, eventID, RoleViewer)
+	if !ok {
+		return
+	}
+
+	event, err := s.Q.GetEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	history, err := s.Q.ListEventBriefings(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch briefings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var current *briefingView
+	selected := r.URL.Query().Get("b")
+	if selected == "" && len(history) > 0 {
+		selected = history[0].ID.String()
+	}
+	if selected != "" {
+		id, err := uuid.Parse(selected)
+		if err != nil {
+			http.Error(w, "Invalid briefing ID", http.StatusBadRequest)
+			return
+		}
+		row, err := s.Q.GetEventBriefing(r.Context(), id)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && row.EventID != eventID) {
+			http.Error(w, "Briefing not found", http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Failed to fetch briefing: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		v, err := newBriefingView(row)
+		if err != nil {
+			http.Error(w, "Failed to read briefing: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		current = &v
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, current)
+		return
+	}
+
+	data := struct {
+		Event    db.Event
+		Briefing *briefingView
+		History  []db.ListEventBriefingsRow
+		CanEdit  bool
+		Windows  []int
+		AIName   string
+	}{
+		Event:    event,
+		Briefing: current,
+		History:  history,
+		CanEdit:  roleAtLeast(role, RoleEditor),
+		Windows:  briefingWindows,
+	}
+	if s.AI != nil {
+		data.AIName = s.AI.Name()
+	}
+	s.render(w, r, "event_briefing.html", data)
+}
+
+// EVENT BRIEFINGS: GENERATE (POST)
+func (s *Server) handleGenerateBriefing(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleEditor); !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	days, _ := strconv.Atoi(r.FormValue("days"))
+	if days < 1 || days > 14 {
+		days = 1
+	}
+
+	in, err := s.briefingInput(r.Context(), eventID, days)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Failed to gather event activity: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	report, source := logic.GenerateBriefing(r.Context(), s.AI, in)
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		http.Error(w, "Failed to encode briefing: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	user, _ := currentUser(r.Context())
+	saved, err := s.Q.CreateEventBriefing(r.Context(), db.CreateEventBriefingParams{
+		EventID:   eventID,
+		CreatedBy: uuid.NullUUID{UUID: user.ID, Valid: true},
+		Source:    source,
+		Days:      int32(days),
+		Report:    reportJSON,
+	})
+	if err != nil {
+		http.Error(w, "Failed to save briefing: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusCreated, briefingView{
+			ID:            saved.ID,
+			Source:        saved.Source,
+			Days:          saved.Days,
+			CreatedAt:     saved.CreatedAt,
+			CreatedByName: user.Name,
+			Report:        report,
+		})
+		return
+	}
+
+	if report.Note != "" {
+		s.setFlash(r, report.Note)
+	} else {
+		s.setFlash(r, "Briefing generated.")
+	}
+	http.Redirect(w, r, "/events/"+eventID.String()+"/briefings?b="+saved.ID.String(), http.StatusSeeOther)
+}
+
+// briefingInput gathers the last days of activity, the open tasks scored
+// under the event's risk profile, what blocks them, and the team.
+func (s *Server) briefingInput(ctx context.Context, eventID uuid.UUID, days int) (logic.BriefingInput, error) {
+	now := time.Now()
+	event, err := s.Q.GetEvent(ctx, eventID)
+	if err != nil {
+		return logic.BriefingInput{}, err
+	}
+	// created_at is a zoneless UTC TIMESTAMP, so the window must be UTC too.
+	changes, err := s.Q.ListEventTaskChanges(ctx, db.ListEventTaskChangesParams{
+		EventID:   eventID,
+		CreatedAt: now.UTC().AddDate(0, 0, -days),
+	})
+	if err != nil {
+		return logic.BriefingInput{}, err
+	}
+	tasks, err := s.Q.GetEventTasks(ctx, db.GetEventTasksParams{EventID: eventID, Column2: false})
+	if err != nil {
+		return logic.BriefingInput{}, err
+	}
+	deps, err := s.Q.ListEventDependencies(ctx, eventID)
+	if err != nil {
+		return logic.BriefingInput{}, err
+	}
+	profile, _, err := logic.LoadRiskProfile(ctx, s.Q, eventID)
+	if err != nil {
+		return logic.BriefingInput{}, err
+	}
+	members, err := s.Q.ListEventMembers(ctx, eventID)
+	if err != nil {
+		return logic.BriefingInput{}, err
+	}
+
+	signals := logic.DependencySignals(tasks, deps, event.EventDate, now)
+	return logic.BriefingInput{
+		EventName: event.Name,
+		EventDate: event.EventDate,
+		Days:      days,
+		Now:       now,
+		Changes:   changes,
+		Tasks:     logic.ScoreEventTasks(tasks, signals, profile),
+		BlockedBy: logic.BlockedBy(deps),
+		Members:   members,
+	}, nil
+}
+
+func newBriefingView(row db.GetEventBriefingRow) (briefingView, error) {
+	v := briefingView{
+		ID:            row.ID,
+		Source:        row.Source,
+		Days:          row.Days,
+		CreatedAt:     row.CreatedAt,
+		CreatedByName: row.CreatedByName.String,
+	}
+	err := json.Unmarshal(row.Report, &v.Report)
+	return v, err
+}
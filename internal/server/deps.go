@@ -0,0 +1,175 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// errBlocked is returned when a task would be marked done while upstream
+// dependencies are still open.
+type errBlocked struct {
+	Titles []string
+}
+
+func (e *errBlocked) Error() string {
+	return "still waiting on " + strings.Join(e.Titles, ", ")
+}
+
+// ensureUnblocked refuses to let a task move to done before everything it
+// depends on is done. Soft-deleted upstream tasks don't count.
+func ensureUnblocked(ctx context.Context, qtx *db.Queries, taskID uuid.UUID) error {
+	deps, err := qtx.ListTaskDependencies(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	var open []string
+	for _, d := range deps {
+		if d.Status != "done" {
+			open = append(open, d.Title)
+		}
+	}
+	if len(open) > 0 {
+		return &errBlocked{Titles: open}
+	}
+	return nil
+}
+
+// DEPENDENCIES: ADD (POST)
+func (s *Server) handleAddDependency(w http.ResponseWriter, r *http.Request) {
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	task, _, ok := s.authorizeTask(w, r, taskID, RoleEditor)
+	if !ok {
+		return
+	}
+	depID, err := uuid.Parse(r.FormValue("dependency_id"))
+	if err != nil {
+		http.Error(w, "Invalid dependency ID", http.StatusBadRequest)
+		return
+	}
+
+	back := "/tasks/" + taskID.String() + "/edit"
+	var dep db.Task
+	var cycle string
+	err = s.Q.RunTx(r.Context(), s.DB, func(qtx *db.Queries) error {
+		// Serialize graph edits per event so two inserts can't race into a cycle.
+		if err := qtx.LockEvent(r.Context(), task.EventID); err != nil {
+			return err
+		}
+
+		dep, err = qtx.GetTask(r.Context(), depID)
+		if err != nil {
+			return err
+		}
+		if dep.EventID != task.EventID {
+			return sql.ErrNoRows
+		}
+
+		// Trashed tasks keep their edges and can be restored, so they count
+		// towards cycles too.
+		edges, err := qtx.ListEventDependencyEdges(r.Context(), task.EventID)
+		if err != nil {
+			return err
+		}
+		rows := make([]db.ListEventDependenciesRow, len(edges))
+		for i, e := range edges {
+			rows[i] = db.ListEventDependenciesRow(e)
+		}
+		if path := logic.BuildDepGraph(rows).CyclePath(taskID, depID); path != nil {
+			titles := map[uuid.UUID]string{task.ID: task.Title, dep.ID: dep.Title}
+			for _, row := range rows {
+				titles[row.DependencyID] = row.DependencyTitle
+			}
+			names := make([]string, len(path))
+			for i, id := range path {
+				names[i] = titles[id]
+			}
+			cycle = strings.Join(names, " → ")
+			return nil
+		}
+
+		n, err := qtx.AddTaskDependency(r.Context(), db.AddTaskDependencyParams{
+			TaskID:       taskID,
+			DependencyID: depID,
+		})
+		if err != nil || n == 0 {
+			// Already there: nothing changed, so nothing to record.
+			return err
+		}
+		return recordTaskEvent(r.Context(), qtx, taskID, logic.EventDependencyAdded, logic.DependencyChanges(dep, true))
+	})
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Dependency must be a live task in the same event", http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, "Adding dependency failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	case cycle != "":
+		s.setFlash(r, "Can't add that dependency, it would create a cycle: "+cycle+".")
+	default:
+		s.setFlash(r, fmt.Sprintf("“%s” now waits on “%s”.", task.Title, dep.Title))
+	}
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// DEPENDENCIES: REMOVE (POST)
+func (s *Server) handleRemoveDependency(w http.ResponseWriter, r *http.Request) {
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	depID, err := uuid.Parse(chi.URLParam(r, "depID"))
+	if err != nil {
+		http.Error(w, "Invalid dependency ID", http.StatusBadRequest)
+		return
+	}
+	if _, _, ok := s.authorizeTask(w, r, taskID, RoleEditor); !ok {
+		return
+	}
+
+	err = s.Q.RunTx(r.Context(), s.DB, func(qtx *db.Queries) error {
+		n, err := qtx.RemoveTaskDependency(r.Context(), db.RemoveTaskDependencyParams{
+			TaskID:       taskID,
+			DependencyID: depID,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+		// The upstream task may be in the trash; the ledger still wants its title.
+		dep, err := qtx.GetTaskIncludingDeleted(r.Context(), depID)
+		if err != nil {
+			return err
+		}
+		return recordTaskEvent(r.Context(), qtx, taskID, logic.EventDependencyRemoved, logic.DependencyChanges(dep, false))
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Dependency not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Removing dependency failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.setFlash(r, "Dependency removed.")
+	http.Redirect(w, r, "/tasks/"+taskID.String()+"/edit", http.StatusSeeOther)
+}
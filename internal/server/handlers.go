@@ -1,10 +1,11 @@
 package server
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json" // <--- ADDED
+	"errors"
 	"fmt"
-	"html/template"
 	"math"
 	"net/http"
 	"net/url"
@@ -25,13 +26,15 @@ type EventDisplay struct {
 	Name           string
 	Info           string
 	Countdown      string
+	Role           string
 	CompletedTasks int64
 	TotalTasks     int64
 }
 
 // 1) DASHBOARD
 func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
-	events, err := s.Q.ListEvents(r.Context())
+	user, _ := currentUser(r.Context())
+	events, err := s.Q.ListUserEvents(r.Context(), user.ID)
 	if err != nil {
 		http.Error(w, "Failed to fetch events: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -59,43 +62,32 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 			Name:           e.Name,
 			Info:           info,
 			Countdown:      countdown,
+			Role:           e.UserRole,
 			CompletedTasks: e.CompletedTasks,
 			TotalTasks:     e.TotalTasks,
 		})
 	}
 
-	var briefingHTML template.HTML
-	if r.URL.Query().Get("briefing") == "true" {
-		tasks, err := s.Q.GetTasksForFollowUp(r.Context())
+	briefing := r.URL.Query().Get("briefing") == "true"
+	var followUps []logic.FollowUp
+	if briefing {
+		tasks, err := s.Q.GetTasksForFollowUp(r.Context(), user.ID)
 		if err == nil {
-			reminders := logic.CheckFollowUps(tasks)
-			if len(reminders) > 0 {
-				html := "<strong>🔔 Suggested Follow-ups:</strong><ul>"
-				for _, r := range reminders {
-					html += "<li>" + r + "</li>"
-				}
-				html += "</ul>"
-				briefingHTML = template.HTML(html)
-			} else {
-				briefingHTML = template.HTML("✅ No urgent follow-ups needed.")
-			}
+			followUps = logic.CheckFollowUps(tasks)
 		}
 	}
 
 	data := struct {
-		Events   []EventDisplay
-		Briefing template.HTML
+		Events    []EventDisplay
+		Briefing  bool
+		FollowUps []logic.FollowUp
 	}{
-		Events:   displayEvents,
-		Briefing: briefingHTML,
+		Events:    displayEvents,
+		Briefing:  briefing,
+		FollowUps: followUps,
 	}
 
-	tmpl, err := template.ParseFiles("templates/base.layout.html", "templates/dashboard.html")
-	if err != nil {
-		http.Error(w, "Template parse error: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	tmpl.ExecuteTemplate(w, "base", data)
+	s.render(w, r, "dashboard.html", data)
 }
 
 // 2) EVENT DETAIL
@@ -105,47 +97,89 @@ func (s *Server) handleEventDetail(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid event ID", http.StatusBadRequest)
 		return
 	}
+	role, ok := s.authorizeEvent(w, r, eventID, RoleViewer)
+	if !ok {
+		return
+	}
 
-	showAll := r.URL.Query().Get("show_all") == "on"
+	// Open tasks by default; "Show Completed" lifts the status filter.
+	v := r.URL.Query()
+	showAll := v.Get("show_all") == "on"
+	defaultStatuses := openStatuses
+	if showAll {
+		defaultStatuses = nil
+	}
+	q, problems := parseTaskQuery(v, defaultStatuses, SortDue)
+	if len(problems) > 0 {
+		http.Error(w, problemText(problems), http.StatusBadRequest)
+		return
+	}
 
-	tasks, err := s.Q.GetEventTasks(r.Context(), db.GetEventTasksParams{
-		EventID: eventID,
-		Column2: showAll,
-	})
+	event, err := s.Q.GetEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+
+	user, _ := currentUser(r.Context())
+	scope := uuid.NullUUID{UUID: eventID, Valid: true}
+	page, err := s.listTasks(r.Context(), user.ID, scope, q)
 	if err != nil {
 		http.Error(w, "Failed to fetch tasks: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	categories, owners, err := s.taskFilterOptions(r.Context(), user.ID, scope)
+	if err != nil {
+		http.Error(w, "Failed to fetch filters: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	yesterday, err := s.yesterdayRiskScores(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Failed to load risk history: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
 
+	// Grouped within the page, so a category can continue on the next one.
 	grouped := make(map[string][]logic.ScoredTask)
-	for _, t := range tasks {
-		scored := logic.ScoreTaskRow(t)
-		grouped[t.Category] = append(grouped[t.Category], scored)
+	for _, st := range page.Tasks {
+		t := st.Task.(db.ListTasksRow)
+		grouped[t.Category] = append(grouped[t.Category], st)
 	}
 
 	data := struct {
 		EventName       string
 		EventID         string
 		TasksByCategory map[string][]logic.ScoredTask
+		BlockedBy       map[uuid.UUID][]string
+		Rising          map[uuid.UUID]int
 		ShowAll         bool
+		Filter          taskFilterForm
+		Categories      []string
+		Owners          []pulseOption
+		NextPage        string
+		Partial         bool
+		ScanLimit       int
+		Role            string
+		CanEdit         bool
 	}{
-		EventName:       "Event Tasks",
+		EventName:       event.Name,
 		EventID:         eventID.String(),
 		TasksByCategory: grouped,
+		BlockedBy:       page.BlockedBy,
+		Rising:          logic.RisingRisk(page.Tasks, yesterday),
 		ShowAll:         showAll,
+		Filter:          newTaskFilterForm(v, q),
+		Categories:      categories,
+		Owners:          owners,
+		NextPage:        nextPageURL(r.URL, page.NextCursor),
+		Partial:         page.Partial,
+		ScanLimit:       riskScanLimit,
+		Role:            role,
+		CanEdit:         roleAtLeast(role, RoleEditor),
 	}
 
-	event, err := s.Q.GetEvent(r.Context(), eventID)
-	if err == nil {
-		data.EventName = event.Name
-	}
-
-	tmpl, err := template.ParseFiles("templates/base.layout.html", "templates/list_tasks.html")
-	if err != nil {
-		http.Error(w, "Template parse error: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-	tmpl.ExecuteTemplate(w, "base", data)
+	s.render(w, r, "list_tasks.html", data)
 }
 
 // 3) CREATE TASK
@@ -154,25 +188,28 @@ func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case http.MethodGet:
-		events, _ := s.Q.ListEvents(r.Context())
+		user, _ := currentUser(r.Context())
+		memberships, _ := s.Q.ListUserEvents(r.Context(), user.ID)
 		people, _ := s.Q.ListPeople(r.Context())
 
-		tmpl, err := template.ParseFiles("templates/base.layout.html", "templates/create_task.html")
-		if err != nil {
-			http.Error(w, "Template parse error: "+err.Error(), http.StatusInternalServerError)
-			return
+		// Only offer events the caller can actually write to.
+		var events []db.ListUserEventsRow
+		for _, e := range memberships {
+			if roleAtLeast(e.UserRole, RoleEditor) {
+				events = append(events, e)
+			}
 		}
 
 		data := struct {
 			EventID string
-			Events  []db.ListEventsRow
+			Events  []db.ListUserEventsRow
 			People  []db.Person
 		}{
 			EventID: prefillEventID,
 			Events:  events,
 			People:  people,
 		}
-		tmpl.ExecuteTemplate(w, "base", data)
+		s.render(w, r, "create_task.html", data)
 		return
 
 	case http.MethodPost:
@@ -222,17 +259,24 @@ func (s *Server) handleCreateTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Error: You must select an Event.", http.StatusBadRequest)
 		return
 	}
+	if _, ok := s.authorizeEvent(w, r, eventUUID, RoleEditor); !ok {
+		return
+	}
 
 	// Conditional AI Logic
 	var subtasksParam pqtype.NullRawMessage
 	if r.FormValue("use_ai") == "true" {
-		subtasksJSON := logic.GenerateSubtasks(title, descRaw)
-		subtasksParam = pqtype.NullRawMessage{RawMessage: subtasksJSON, Valid: true}
-	} else {
-		subtasksParam = pqtype.NullRawMessage{Valid: false}
+		steps, notice := s.aiSubtasks(r.Context(), title, descRaw, nil)
+		if steps, _ = logic.MergeSubtasks(nil, steps); len(steps) > 0 {
+			b, _ := json.Marshal(steps)
+			subtasksParam = pqtype.NullRawMessage{RawMessage: b, Valid: true}
+		}
+		if notice != "" {
+			s.setFlash(r, notice)
+		}
 	}
 
-	_, err = s.Q.CreateTask(r.Context(), db.CreateTaskParams{
+	_, err = s.createTask(r.Context(), db.CreateTaskParams{
 		Title:        title,
 		Description:  descParam,
 		OwnerID:      ownerIDParam,
@@ -261,9 +305,8 @@ func (s *Server) handleEditTask(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := s.Q.GetTask(r.Context(), taskID)
-	if err != nil {
-		http.Error(w, "Task not found", 404)
+	task, role, ok := s.authorizeTask(w, r, taskID, RoleViewer)
+	if !ok {
 		return
 	}
 
@@ -301,25 +344,51 @@ func (s *Server) handleEditTask(w http.ResponseWriter, r *http.Request) {
 	}
 	// ------------------------------------------
 
-	tmpl, err := template.ParseFiles("templates/base.layout.html", "templates/edit_task.html")
+	upstream, err := s.Q.ListTaskDependencies(r.Context(), taskID)
 	if err != nil {
-		http.Error(w, err.Error(), 500)
+		http.Error(w, "Failed to fetch dependencies: "+err.Error(), 500)
 		return
 	}
+	downstream, err := s.Q.ListTaskDependents(r.Context(), taskID)
+	if err != nil {
+		http.Error(w, "Failed to fetch dependencies: "+err.Error(), 500)
+		return
+	}
+
+	// Anything else in the same event that isn't already upstream can be added.
+	siblings, _ := s.Q.GetEventTasks(r.Context(), db.GetEventTasksParams{EventID: task.EventID, Column2: true})
+	linked := map[uuid.UUID]bool{taskID: true}
+	for _, d := range upstream {
+		linked[d.ID] = true
+	}
+	var candidates []db.GetEventTasksRow
+	for _, t := range siblings {
+		if !linked[t.ID] {
+			candidates = append(candidates, t)
+		}
+	}
 
 	data := struct {
-		Task     db.Task
-		People   []db.Person
-		Subtasks []logic.Subtask
-		GCalLink string
+		Task         db.Task
+		People       []db.Person
+		Subtasks     []logic.Subtask
+		GCalLink     string
+		CanEdit      bool
+		Dependencies []db.ListTaskDependenciesRow
+		Dependents   []db.ListTaskDependentsRow
+		Candidates   []db.GetEventTasksRow
 	}{
-		Task:     task,
-		People:   people,
-		Subtasks: parsedSubtasks,
-		GCalLink: calLink,
+		Task:         task,
+		People:       people,
+		Subtasks:     parsedSubtasks,
+		GCalLink:     calLink,
+		CanEdit:      roleAtLeast(role, RoleEditor),
+		Dependencies: upstream,
+		Dependents:   downstream,
+		Candidates:   candidates,
 	}
 
-	tmpl.ExecuteTemplate(w, "base", data)
+	s.render(w, r, "edit_task.html", data)
 }
 
 // 5) UPDATE TASK (POST)
@@ -335,6 +404,9 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid task id", 400)
 		return
 	}
+	if _, _, ok := s.authorizeTask(w, r, taskID, RoleEditor); !ok {
+		return
+	}
 
 	// 1. Gather Basic Fields
 	title := r.FormValue("title")
@@ -401,17 +473,23 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
-	// 3. CHECK FOR AI TRIGGER (Updated with Debugging)
+	// 3. CHECK FOR AI TRIGGER
+	// The rest of the form is saved either way; the flash says what the AI
+	// step did or why it didn't.
+	var aiNotice string
 	if r.FormValue("action") == "generate_ai" {
-		aiJSON := logic.GenerateSubtasks(title, desc)
-		var aiSteps []logic.Subtask
-		if err := json.Unmarshal(aiJSON, &aiSteps); err == nil {
-			// Append AI steps to whatever is currently in the list
-			currentSubtasks = append(currentSubtasks, aiSteps...)
-		} else {
-			// PRINT ERROR TO TERMINAL
-			fmt.Println("❌ Handler JSON Error: Could not unmarshal AI response:", err)
-			fmt.Println("   Raw JSON was:", string(aiJSON))
+		steps, notice := s.aiSubtasks(ctx, title, desc, currentSubtasks)
+		var added int
+		currentSubtasks, added = logic.MergeSubtasks(currentSubtasks, steps)
+		switch {
+		case notice != "":
+			aiNotice = notice
+		case added == 0:
+			aiNotice = "The AI suggested nothing new: every step was already on the checklist, or it's full."
+		case added < len(steps):
+			aiNotice = fmt.Sprintf("Added %d AI step(s); %d duplicate or over-the-limit step(s) were skipped.", added, len(steps)-added)
+		default:
+			aiNotice = fmt.Sprintf("Added %d AI step(s).", added)
 		}
 	}
 	// 4. Serialize Subtasks
@@ -424,36 +502,25 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 5. Database Transaction
-	txErr := s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
-		oldTask, err := qtx.GetTask(ctx, taskID)
-		if err != nil {
-			return err
-		}
-
-		newTask, err := qtx.UpdateTask(ctx, db.UpdateTaskParams{
-			ID:           taskID,
-			Title:        titleParam,
-			Description:  descParam,
-			Status:       statusParam,
-			Priority:     priorityParam,
-			DueDate:      dateParam,
-			Category:     categoryParam,
-			OwnerID:      ownerIDParam,
-			AssigneeText: assigneeParam,
-			Subtasks:     subtasksParam,
-		})
-		if err != nil {
-			return err
-		}
-
-		diff := logic.CalculateChanges(oldTask, newTask)
-		return qtx.CreateTaskEvent(ctx, db.CreateTaskEventParams{
-			TaskID:    taskID,
-			EventType: "UPDATED",
-			Changes:   diff,
-		})
+	_, txErr := s.updateTask(ctx, db.UpdateTaskParams{
+		ID:           taskID,
+		Title:        titleParam,
+		Description:  descParam,
+		Status:       statusParam,
+		Priority:     priorityParam,
+		DueDate:      dateParam,
+		Category:     categoryParam,
+		OwnerID:      ownerIDParam,
+		AssigneeText: assigneeParam,
+		Subtasks:     subtasksParam,
 	})
 
+	var blocked *errBlocked
+	if errors.As(txErr, &blocked) {
+		s.setFlash(r, "Can't mark this task done: "+blocked.Error()+".")
+		http.Redirect(w, r, r.Header.Get("Referer"), http.StatusSeeOther)
+		return
+	}
 	if txErr != nil {
 		http.Error(w, "Update failed: "+txErr.Error(), 500)
 		return
@@ -462,12 +529,148 @@ func (s *Server) handleUpdateTask(w http.ResponseWriter, r *http.Request) {
 	// If we just generated AI, go back to Edit page to show them.
 	// Otherwise, go to where they came from (Dashboard/Event View).
 	if r.FormValue("action") == "generate_ai" {
+		s.setFlash(r, aiNotice)
 		http.Redirect(w, r, fmt.Sprintf("/tasks/%s/edit", taskID), http.StatusSeeOther)
 	} else {
 		http.Redirect(w, r, r.Header.Get("Referer"), http.StatusSeeOther)
 	}
 }
 
+// aiSubtasks generates checklist steps for the create and edit forms. The
+// notice is for the flash: why nothing was generated, or that placeholder
+// steps stand in because AI is off.
+func (s *Server) aiSubtasks(ctx context.Context, title, desc string, existing []logic.Subtask) ([]logic.Subtask, string) {
+	if s.AI == nil {
+		return logic.SimulatedSubtasks(title), "No AI provider is configured, so placeholder steps were added."
+	}
+	steps, err := logic.GenerateSubtasks(ctx, s.AI, title, desc, existing)
+	if err != nil {
+		return nil, "Couldn't generate subtasks: " + err.Error() + "."
+	}
+	return steps, ""
+}
+
+// createTask inserts a task and its CREATED ledger entry in one transaction.
+func (s *Server) createTask(ctx context.Context, params db.CreateTaskParams) (db.Task, error) {
+	var task db.Task
+	err := s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
+		var err error
+		task, err = qtx.CreateTask(ctx, params)
+		if err != nil {
+			return err
+		}
+		return recordTaskEvent(ctx, qtx, task.ID, logic.EventCreated, logic.SnapshotChanges(task))
+	})
+	return task, err
+}
+
+// updateTask applies a partial update (NULL params keep the current value)
+// and records the diff. Moving a task to done fails with *errBlocked while
+// its dependencies are open.
+func (s *Server) updateTask(ctx context.Context, params db.UpdateTaskParams) (db.Task, error) {
+	return s.editTask(ctx, params.ID, func(db.Task) (db.UpdateTaskParams, error) {
+		return params, nil
+	})
+}
+
+// editTask is updateTask for changes that depend on the current row, like
+// toggling one subtask: edit gets the task locked FOR UPDATE, so concurrent
+// edits queue up instead of overwriting each other, and returns the update.
+func (s *Server) editTask(ctx context.Context, taskID uuid.UUID, edit func(db.Task) (db.UpdateTaskParams, error)) (db.Task, error) {
+	var newTask db.Task
+	err := s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
+		oldTask, err := qtx.GetTaskForUpdate(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		params, err := edit(oldTask)
+		if err != nil {
+			return err
+		}
+		params.ID = taskID
+		if params.Status.String == "done" && oldTask.Status != "done" {
+			if err := ensureUnblocked(ctx, qtx, taskID); err != nil {
+				return err
+			}
+		}
+
+		newTask, err = qtx.UpdateTask(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		diff := logic.CalculateChanges(oldTask, newTask)
+		return recordTaskEvent(ctx, qtx, taskID, logic.EventUpdated, diff)
+	})
+	return newTask, err
+}
+
+// deleteTask moves a live task to the trash. sql.ErrNoRows means it was
+// already deleted.
+func (s *Server) deleteTask(ctx context.Context, taskID uuid.UUID) (db.Task, error) {
+	var deleted db.Task
+	err := s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
+		var err error
+		deleted, err = qtx.SoftDeleteTask(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		return recordTaskEvent(ctx, qtx, taskID, logic.EventDeleted, logic.DeletionChanges(deleted))
+	})
+	return deleted, err
+}
+
+// createEvent creates an event owned by ownerID and, given a template, seeds
+// it with the template's tasks due relative to the event date.
+func (s *Server) createEvent(ctx context.Context, ownerID uuid.UUID, params db.CreateEventParams, templateID uuid.NullUUID) (db.Event, error) {
+	var event db.Event
+	err := s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
+		var err error
+		event, err = qtx.CreateEvent(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		// The creator owns the event; otherwise nobody could see it.
+		err = qtx.AddEventMember(ctx, db.AddEventMemberParams{
+			EventID:  event.ID,
+			PersonID: ownerID,
+			Role:     RoleOwner,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !templateID.Valid {
+			return nil
+		}
+		tmplTasks, err := qtx.GetTemplateTasks(ctx, templateID.UUID)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range tmplTasks {
+			dueDate := event.EventDate.AddDate(0, 0, -int(t.RelativeDueDays.Int32))
+			task, err := qtx.CreateTask(ctx, db.CreateTaskParams{
+				Title:       t.Title,
+				Priority:    t.Priority,
+				Category:    t.Category,
+				EventID:     event.ID,
+				DueDate:     sql.NullTime{Time: dueDate, Valid: true},
+				Description: t.Description,
+			})
+			if err != nil {
+				return err
+			}
+			if err := recordTaskEvent(ctx, qtx, task.ID, logic.EventCreated, logic.SnapshotChanges(task)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return event, err
+}
+
 // 6) VIEW HISTORY
 func (s *Server) handleTaskEvents(w http.ResponseWriter, r *http.Request) {
 	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
@@ -475,27 +678,67 @@ func (s *Server) handleTaskEvents(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid task id", 400)
 		return
 	}
+	task, _, ok := s.authorizeTask(w, r, taskID, RoleViewer)
+	if !ok {
+		return
+	}
 	events, err := s.Q.GetTaskEvents(r.Context(), taskID)
 	if err != nil {
 		http.Error(w, "Failed to fetch events", 500)
 		return
 	}
 
+	// ?actor=<person id> narrows the ledger to one person; "system" = no actor.
+	actorFilter := r.URL.Query().Get("actor")
+
 	type EventView struct {
 		EventType string
 		CreatedAt string
+		Actor     string
 		Changes   string
 	}
+	type ActorOption struct {
+		Key  string
+		Name string
+	}
 	var eventViews []EventView
+	var actors []ActorOption
+	seen := map[string]bool{}
 	for _, e := range events {
+		key, name := "system", "System"
+		if e.ActorID.Valid {
+			key, name = e.ActorID.UUID.String(), "Unknown user"
+			if e.ActorName.Valid {
+				name = e.ActorName.String
+			}
+		}
+		if !seen[key] {
+			seen[key] = true
+			actors = append(actors, ActorOption{Key: key, Name: name})
+		}
+		if actorFilter != "" && actorFilter != key {
+			continue
+		}
+
 		eventViews = append(eventViews, EventView{
 			EventType: e.EventType,
 			CreatedAt: e.CreatedAt.Format("2006-01-02 15:04:05"),
+			Actor:     name,
 			Changes:   string(e.Changes),
 		})
 	}
-	tmpl, _ := template.ParseFiles("templates/base.layout.html", "templates/task_events.html")
-	tmpl.ExecuteTemplate(w, "base", struct{ Events []EventView }{Events: eventViews})
+
+	s.render(w, r, "task_events.html", struct {
+		Task   db.Task
+		Events []EventView
+		Actors []ActorOption
+		Actor  string
+	}{
+		Task:   task,
+		Events: eventViews,
+		Actors: actors,
+		Actor:  actorFilter,
+	})
 }
 
 // 7) DELETE TASK
@@ -505,11 +748,21 @@ func (s *Server) handleDeleteTask(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid ID", 400)
 		return
 	}
-	if err := s.Q.SoftDeleteTask(r.Context(), taskID); err != nil {
+	task, _, ok := s.authorizeTask(w, r, taskID, RoleEditor)
+	if !ok {
+		return
+	}
+	_, err = s.deleteTask(r.Context(), taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Task already deleted", http.StatusNotFound)
+		return
+	}
+	if err != nil {
 		http.Error(w, "Failed to delete: "+err.Error(), 500)
 		return
 	}
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	s.setFlash(r, fmt.Sprintf("Moved “%s” to the trash.", task.Title))
+	http.Redirect(w, r, "/events/"+task.EventID.String(), http.StatusSeeOther)
 }
 
 // 8) CREATE EVENT
@@ -517,41 +770,35 @@ func (s *Server) handleCreateEvent(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodGet {
 		templates, _ := s.Q.ListTemplates(r.Context())
 		data := struct{ Templates []db.Template }{Templates: templates}
-		tmpl, _ := template.ParseFiles("templates/base.layout.html", "templates/create_event.html")
-		tmpl.ExecuteTemplate(w, "base", data)
+		s.render(w, r, "create_event.html", data)
 		return
 	}
 	name := r.FormValue("name")
 	dateStr := r.FormValue("event_date")
 	templateIDStr := r.FormValue("template_id")
 	eventDate, _ := time.Parse("2006-01-02", dateStr)
+	user, _ := currentUser(r.Context())
 
-	event, err := s.Q.CreateEvent(r.Context(), db.CreateEventParams{
+	var templateID uuid.NullUUID
+	if templateIDStr != "" {
+		id, err := uuid.Parse(templateIDStr)
+		if err != nil {
+			http.Error(w, "Invalid template ID", http.StatusBadRequest)
+			return
+		}
+		templateID = uuid.NullUUID{UUID: id, Valid: true}
+	}
+
+	event, err := s.createEvent(r.Context(), user.ID, db.CreateEventParams{
 		Name:      name,
 		EventDate: eventDate,
-	})
+	}, templateID)
 	if err != nil {
-		http.Error(w, "Failed to create event", 500)
+		http.Error(w, "Failed to create event: "+err.Error(), 500)
 		return
 	}
 
-	if templateIDStr != "" {
-		tmplID, _ := uuid.Parse(templateIDStr)
-		tmplTasks, _ := s.Q.GetTemplateTasks(r.Context(), tmplID)
-
-		for _, t := range tmplTasks {
-			dueDate := event.EventDate.AddDate(0, 0, -int(t.RelativeDueDays.Int32))
-			s.Q.CreateTask(r.Context(), db.CreateTaskParams{
-				Title:       t.Title,
-				Priority:    t.Priority,
-				Category:    t.Category,
-				EventID:     event.ID,
-				DueDate:     sql.NullTime{Time: dueDate, Valid: true},
-				Description: t.Description,
-			})
-		}
-	}
-	http.Redirect(w, r, "/", http.StatusSeeOther)
+	http.Redirect(w, r, "/events/"+event.ID.String(), http.StatusSeeOther)
 }
 
 // 9) EDIT EVENT
@@ -561,13 +808,15 @@ func (s *Server) handleEditEvent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid ID", 400)
 		return
 	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleEditor); !ok {
+		return
+	}
 	event, err := s.Q.GetEvent(r.Context(), eventID)
 	if err != nil {
 		http.Error(w, "Event not found", 404)
 		return
 	}
-	tmpl, _ := template.ParseFiles("templates/base.layout.html", "templates/edit_event.html")
-	tmpl.ExecuteTemplate(w, "base", struct{ Event db.Event }{Event: event})
+	s.render(w, r, "edit_event.html", struct{ Event db.Event }{Event: event})
 }
 
 // 10) UPDATE EVENT
@@ -577,6 +826,9 @@ func (s *Server) handleUpdateEvent(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid ID", 400)
 		return
 	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleEditor); !ok {
+		return
+	}
 	name := r.FormValue("name")
 	dateStr := r.FormValue("event_date")
 	loc := r.FormValue("location")
@@ -628,7 +880,23 @@ func (s *Server) handleBatchDelete(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if len(ids) > 0 {
-		if err := s.Q.BatchSoftDeleteTasks(r.Context(), ids); err != nil {
+		if !s.authorizeTasks(w, r, ids, RoleEditor) {
+			return
+		}
+		err := s.Q.RunTx(r.Context(), s.DB, func(qtx *db.Queries) error {
+			// Only rows that were actually live come back, so re-deletes don't pollute the ledger.
+			deleted, err := qtx.BatchSoftDeleteTasks(r.Context(), ids)
+			if err != nil {
+				return err
+			}
+			for _, t := range deleted {
+				if err := recordTaskEvent(r.Context(), qtx, t.ID, logic.EventBatchDeleted, logic.DeletionChanges(t)); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
 			http.Error(w, "Batch delete failed: "+err.Error(), 500)
 			return
 		}
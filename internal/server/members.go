@@ -0,0 +1,236 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// MEMBERS (GET)
+func (s *Server) handleEventMembers(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	role, ok := s.authorizeEvent(w, r, eventID, RoleViewer)
+	if !ok {
+		return
+	}
+
+	event, err := s.Q.GetEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	members, err := s.Q.ListEventMembers(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch members: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	user, _ := currentUser(r.Context())
+
+	data := struct {
+		Event    db.Event
+		Members  []db.ListEventMembersRow
+		IsOwner  bool
+		MyID     uuid.UUID
+		AllRoles []string
+	}{
+		Event:    event,
+		Members:  members,
+		IsOwner:  role == RoleOwner,
+		MyID:     user.ID,
+		AllRoles: []string{RoleOwner, RoleEditor, RoleViewer},
+	}
+	s.render(w, r, "members.html", data)
+}
+
+// MEMBERS: INVITE (POST)
+func (s *Server) handleAddMember(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleOwner); !ok {
+		return
+	}
+
+	back := "/events/" + eventID.String() + "/members"
+	email := strings.ToLower(strings.TrimSpace(r.FormValue("email")))
+	role := r.FormValue("role")
+	if !validRole(role) {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+
+	person, err := s.Q.GetPersonByEmail(r.Context(), sql.NullString{String: email, Valid: email != ""})
+	if errors.Is(err, sql.ErrNoRows) {
+		s.setFlash(r, "No account uses "+email+". Ask them to sign up first, then invite them again.")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Invite failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = s.Q.GetEventMembership(r.Context(), db.GetEventMembershipParams{EventID: eventID, PersonID: person.ID})
+	if err == nil {
+		s.setFlash(r, person.Name+" is already a member. Change their role below instead.")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Invite failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := s.Q.AddEventMember(r.Context(), db.AddEventMemberParams{
+		EventID:  eventID,
+		PersonID: person.ID,
+		Role:     role,
+	}); err != nil {
+		http.Error(w, "Invite failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.setFlash(r, person.Name+" added as "+role+".")
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// MEMBERS: CHANGE ROLE (POST)
+func (s *Server) handleUpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	eventID, personID, ok := memberRouteIDs(w, r)
+	if !ok {
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleOwner); !ok {
+		return
+	}
+
+	role := r.FormValue("role")
+	if !validRole(role) {
+		http.Error(w, "Invalid role", http.StatusBadRequest)
+		return
+	}
+
+	err := s.setMemberRole(r.Context(), eventID, personID, role)
+	s.finishMemberChange(w, r, eventID, err, "Role updated.")
+}
+
+// MEMBERS: REMOVE (POST)
+func (s *Server) handleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	eventID, personID, ok := memberRouteIDs(w, r)
+	if !ok {
+		return
+	}
+
+	// Owners can remove anyone; everyone else can only leave.
+	user, _ := currentUser(r.Context())
+	min := RoleOwner
+	if user.ID == personID {
+		min = RoleViewer
+	}
+	if _, ok := s.authorizeEvent(w, r, eventID, min); !ok {
+		return
+	}
+
+	err := s.removeMember(r.Context(), eventID, personID)
+
+	if err == nil && user.ID == personID {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	s.finishMemberChange(w, r, eventID, err, "Member removed.")
+}
+
+// setMemberRole changes a member's role, refusing to demote the last owner.
+// sql.ErrNoRows means the person isn't a member.
+func (s *Server) setMemberRole(ctx context.Context, eventID, personID uuid.UUID, role string) error {
+	return s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
+		current, err := qtx.GetEventMembership(ctx, db.GetEventMembershipParams{EventID: eventID, PersonID: personID})
+		if err != nil {
+			return err
+		}
+		if current == RoleOwner && role != RoleOwner {
+			if err := ensureAnotherOwner(ctx, qtx, eventID); err != nil {
+				return err
+			}
+		}
+		return qtx.UpdateEventMemberRole(ctx, db.UpdateEventMemberRoleParams{
+			EventID:  eventID,
+			PersonID: personID,
+			Role:     role,
+		})
+	})
+}
+
+// removeMember takes a person off an event, refusing to remove the last owner.
+func (s *Server) removeMember(ctx context.Context, eventID, personID uuid.UUID) error {
+	return s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
+		current, err := qtx.GetEventMembership(ctx, db.GetEventMembershipParams{EventID: eventID, PersonID: personID})
+		if err != nil {
+			return err
+		}
+		if current == RoleOwner {
+			if err := ensureAnotherOwner(ctx, qtx, eventID); err != nil {
+				return err
+			}
+		}
+		return qtx.RemoveEventMember(ctx, db.RemoveEventMemberParams{EventID: eventID, PersonID: personID})
+	})
+}
+
+var errLastOwner = errors.New("an event must keep at least one owner")
+
+// ensureAnotherOwner locks the event's owner rows, so two owners demoting or
+// removing each other at once can't both pass the check.
+func ensureAnotherOwner(ctx context.Context, qtx *db.Queries, eventID uuid.UUID) error {
+	owners, err := qtx.LockEventOwners(ctx, eventID)
+	if err != nil {
+		return err
+	}
+	if len(owners) <= 1 {
+		return errLastOwner
+	}
+	return nil
+}
+
+func memberRouteIDs(w http.ResponseWriter, r *http.Request) (uuid.UUID, uuid.UUID, bool) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	personID, err := uuid.Parse(chi.URLParam(r, "personID"))
+	if err != nil {
+		http.Error(w, "Invalid person ID", http.StatusBadRequest)
+		return uuid.Nil, uuid.Nil, false
+	}
+	return eventID, personID, true
+}
+
+func (s *Server) finishMemberChange(w http.ResponseWriter, r *http.Request, eventID uuid.UUID, err error, okMsg string) {
+	switch {
+	case errors.Is(err, errLastOwner):
+		s.setFlash(r, "Can't do that: "+err.Error()+". Promote someone else first.")
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Member not found", http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, "Membership update failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	default:
+		s.setFlash(r, okMsg)
+	}
+	http.Redirect(w, r, "/events/"+eventID.String()+"/members", http.StatusSeeOther)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// errSubtaskRange means the posted index no longer exists on the task.
+var errSubtaskRange = errors.New("subtask out of range")
+
+// MY TASKS (GET)
+func (s *Server) handleMyTasks(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+
+	// ?assignee=on also picks up tasks handed out by name before the person
+	// had an account (assignee_text), which owner_id alone would miss.
+	byName := r.URL.Query().Get("assignee") == "on"
+	name := ""
+	if byName {
+		name = user.Name
+	}
+
+	rows, err := s.Q.ListMyTasks(r.Context(), db.ListMyTasksParams{PersonID: user.ID, Column2: name})
+	if err != nil {
+		http.Error(w, "Failed to fetch tasks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	editable := map[uuid.UUID]bool{}
+	blockedBy := map[uuid.UUID][]string{}
+	for _, t := range rows {
+		if _, done := editable[t.EventID]; done {
+			continue
+		}
+		editable[t.EventID] = roleAtLeast(t.UserRole, RoleEditor)
+		deps, err := s.Q.ListEventDependencies(r.Context(), t.EventID)
+		if err != nil {
+			http.Error(w, "Failed to fetch dependencies: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		for id, titles := range logic.BlockedBy(deps) {
+			blockedBy[id] = titles
+		}
+	}
+
+	data := struct {
+		Buckets   []logic.TaskBucket
+		Total     int
+		ByName    bool
+		Editable  map[uuid.UUID]bool
+		BlockedBy map[uuid.UUID][]string
+	}{
+		Buckets:   logic.GroupMyTasks(rows, time.Now()),
+		Total:     len(rows),
+		ByName:    byName,
+		Editable:  editable,
+		BlockedBy: blockedBy,
+	}
+	s.render(w, r, "my_tasks.html", data)
+}
+
+// SUBTASKS: TOGGLE (POST)
+func (s *Server) handleToggleSubtask(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task id", http.StatusBadRequest)
+		return
+	}
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil || index < 0 {
+		http.Error(w, "Invalid subtask", http.StatusBadRequest)
+		return
+	}
+	if _, _, ok := s.authorizeTask(w, r, taskID, RoleEditor); !ok {
+		return
+	}
+
+	_, txErr := s.editTask(ctx, taskID, toggleSubtask(index))
+	if errors.Is(txErr, errSubtaskRange) {
+		// The checklist changed under a stale page; just show the fresh one.
+		s.setFlash(r, "That checklist changed since you loaded the page.")
+		http.Redirect(w, r, r.Header.Get("Referer"), http.StatusSeeOther)
+		return
+	}
+	if txErr != nil {
+		http.Error(w, "Update failed: "+txErr.Error(), http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, r.Header.Get("Referer"), http.StatusSeeOther)
+}
+
+// toggleSubtask is an editTask edit that flips one checklist step, failing
+// with errSubtaskRange when the checklist no longer has that step.
+func toggleSubtask(index int) func(db.Task) (db.UpdateTaskParams, error) {
+	return func(oldTask db.Task) (db.UpdateTaskParams, error) {
+		var subtasks []logic.Subtask
+		if oldTask.Subtasks.Valid {
+			if err := json.Unmarshal(oldTask.Subtasks.RawMessage, &subtasks); err != nil {
+				return db.UpdateTaskParams{}, err
+			}
+		}
+		if index >= len(subtasks) {
+			return db.UpdateTaskParams{}, errSubtaskRange
+		}
+		subtasks[index].IsDone = !subtasks[index].IsDone
+
+		b, err := json.Marshal(subtasks)
+		if err != nil {
+			return db.UpdateTaskParams{}, err
+		}
+		return db.UpdateTaskParams{Subtasks: pqtype.NullRawMessage{RawMessage: b, Valid: true}}, nil
+	}
+}
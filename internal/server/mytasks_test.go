@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sqlc-dev/pqtype"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+func checklist(t *testing.T, steps ...logic.Subtask) pqtype.NullRawMessage {
+	t.Helper()
+	b, err := json.Marshal(steps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pqtype.NullRawMessage{RawMessage: b, Valid: true}
+}
+
+func TestToggleSubtask(t *testing.T) {
+	task := db.Task{Subtasks: checklist(t, logic.Subtask{Title: "Call venues"}, logic.Subtask{Title: "Sign", IsDone: true})}
+	tests := []struct {
+		name    string
+		task    db.Task
+		index   int
+		want    []logic.Subtask
+		wantErr error
+	}{
+		{name: "tick", task: task, index: 0, want: []logic.Subtask{{Title: "Call venues", IsDone: true}, {Title: "Sign", IsDone: true}}},
+		{name: "untick", task: task, index: 1, want: []logic.Subtask{{Title: "Call venues"}, {Title: "Sign"}}},
+		{name: "past the end", task: task, index: 2, wantErr: errSubtaskRange},
+		{name: "no checklist", task: db.Task{}, index: 0, wantErr: errSubtaskRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := toggleSubtask(tt.index)(tt.task)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []logic.Subtask
+			if err := json.Unmarshal(params.Subtasks.RawMessage, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("subtasks = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestConcurrentSubtaskToggles needs a migrated Postgres database in
+// TEST_DATABASE_URL. Without the row lock, concurrent toggles read the same
+// checklist and the last write wins, losing the others' ticks.
+func TestConcurrentSubtaskToggles(t *testing.T) {
+	_, s := newAPIContract(t)
+	ctx := context.Background()
+
+	event, err := s.Q.CreateEvent(ctx, db.CreateEventParams{Name: "Toggle fair " + uuid.NewString()[:8], EventDate: time.Now().AddDate(0, 1, 0)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const n = 8
+	steps := make([]logic.Subtask, n)
+	for i := range steps {
+		steps[i] = logic.Subtask{Title: "Step " + string(rune('A'+i))}
+	}
+	task, err := s.createTask(ctx, db.CreateTaskParams{Title: "Checklist", EventID: event.ID, Priority: 1, Category: "general", Tags: []string{}, Subtasks: checklist(t, steps...)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := s.editTask(ctx, task.ID, toggleSubtask(i)); err != nil {
+				t.Errorf("toggle %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	got, err := s.Q.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var final []logic.Subtask
+	if err := json.Unmarshal(got.Subtasks.RawMessage, &final); err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range final {
+		if !st.IsDone {
+			t.Errorf("%s was lost: %+v", st.Title, final)
+		}
+	}
+
+	// Every toggle is in the ledger, and replaying it matches the row.
+	events, err := s.Q.GetTaskEvents(ctx, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != n+1 {
+		t.Errorf("%d ledger entries, want CREATED plus %d updates", len(events), n)
+	}
+	res, err := logic.ReplayTask(events, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if drift := logic.CheckDrift(res.State, got); len(drift) != 0 {
+		t.Errorf("ledger drifts from the row: %+v", drift)
+	}
+}
@@ -0,0 +1,382 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// apiEndpoint describes one JSON API route. The same table registers the
+// routes and generates the OpenAPI document, so the two can't drift.
+type apiEndpoint struct {
+	ID      string // operationId
+	Method  string
+	Path    string // relative to /api/v1, in chi syntax
+	Tag     string
+	Summary string
+	// Public endpoints skip apiAuth.
+	Public bool
+	// AnyScope lets read-only tokens call a non-GET endpoint; only revoking
+	// the token itself needs it.
+	AnyScope bool
+	Query    []apiQueryParam
+	// Request and Response are zero values of the body types; the response
+	// is wrapped in the {"data": ...} envelope. A nil Response means no body.
+	Request  interface{}
+	Response interface{}
+	// Paged lists also carry {"meta": {"next_cursor": ...}}.
+	Paged   bool
+	Status  int
+	Handler http.HandlerFunc
+}
+
+type apiQueryParam struct {
+	Name        string
+	Type        string
+	Format      string
+	Enum        []string
+	Description string
+}
+
+// taskListQuery documents parseTaskQuery. List parameters take repeats or
+// commas.
+var taskListQuery = []apiQueryParam{
+	{Name: "status", Type: "string", Description: "Comma-separated statuses; all by default"},
+	{Name: "category", Type: "string"},
+	{Name: "owner", Type: "string", Description: "A person ID, or unassigned"},
+	{Name: "tag", Type: "string", Description: "Comma-separated tags; tasks must have all of them"},
+	{Name: "due_from", Type: "string", Format: "date"},
+	{Name: "due_to", Type: "string", Format: "date"},
+	{Name: "risk", Type: "string", Enum: riskLevels},
+	{Name: "q", Type: "string", Description: "Matches title or description"},
+	{Name: "sort", Type: "string", Enum: taskSorts, Description: "due (default), risk, priority or updated"},
+	{Name: "cursor", Type: "string", Description: "meta.next_cursor from the previous page"},
+	{Name: "limit", Type: "integer", Description: "Page size, 1-200 (default 50)"},
+}
+
+func (s *Server) apiEndpoints() []apiEndpoint {
+	return []apiEndpoint{
+		{ID: "issueToken", Method: "POST", Path: "/auth/token", Tag: "Auth", Summary: "Trade email and password for a bearer token",
+			Public: true, Request: apiTokenRequest{}, Response: apiToken{}, Status: http.StatusCreated, Handler: s.handleAPIIssueToken},
+		{ID: "revokeToken", Method: "DELETE", Path: "/auth/token", Tag: "Auth", Summary: "Revoke the bearer token sent with the request",
+			AnyScope: true, Status: http.StatusNoContent, Handler: s.handleAPIRevokeToken},
+		{ID: "getMe", Method: "GET", Path: "/me", Tag: "People", Summary: "The authenticated person",
+			Response: apiMe{}, Status: http.StatusOK, Handler: s.handleAPIMe},
+		{ID: "listPeople", Method: "GET", Path: "/people", Tag: "People", Summary: "Everyone with an account",
+			Response: []apiPerson{}, Status: http.StatusOK, Handler: s.handleAPIListPeople},
+
+		{ID: "listTemplates", Method: "GET", Path: "/templates", Tag: "Templates", Summary: "Event templates",
+			Response: []apiTemplate{}, Status: http.StatusOK, Handler: s.handleAPIListTemplates},
+		{ID: "getTemplate", Method: "GET", Path: "/templates/{id}", Tag: "Templates", Summary: "A template with the tasks it seeds",
+			Response: apiTemplate{}, Status: http.StatusOK, Handler: s.handleAPIGetTemplate},
+
+		{ID: "listEvents", Method: "GET", Path: "/events", Tag: "Events", Summary: "Events the caller is a member of",
+			Response: []apiEventSummary{}, Status: http.StatusOK, Handler: s.handleAPIListEvents},
+		{ID: "createEvent", Method: "POST", Path: "/events", Tag: "Events", Summary: "Create an event, optionally from a template",
+			Request: apiEventCreate{}, Response: apiEvent{}, Status: http.StatusCreated, Handler: s.handleAPICreateEvent},
+		{ID: "getEvent", Method: "GET", Path: "/events/{id}", Tag: "Events", Summary: "An event",
+			Response: apiEvent{}, Status: http.StatusOK, Handler: s.handleAPIGetEvent},
+		{ID: "updateEvent", Method: "PATCH", Path: "/events/{id}", Tag: "Events", Summary: "Update an event (editor)",
+			Request: apiEventUpdate{}, Response: apiEvent{}, Status: http.StatusOK, Handler: s.handleAPIUpdateEvent},
+
+		{ID: "listMembers", Method: "GET", Path: "/events/{id}/members", Tag: "Members", Summary: "Members of an event",
+			Response: []apiMember{}, Status: http.StatusOK, Handler: s.handleAPIListMembers},
+		{ID: "addMember", Method: "POST", Path: "/events/{id}/members", Tag: "Members", Summary: "Invite an existing account (owner)",
+			Request: apiMemberCreate{}, Response: apiMember{}, Status: http.StatusCreated, Handler: s.handleAPIAddMember},
+		{ID: "updateMember", Method: "PATCH", Path: "/events/{id}/members/{personID}", Tag: "Members", Summary: "Change a member's role (owner)",
+			Request: apiMemberUpdate{}, Status: http.StatusNoContent, Handler: s.handleAPIUpdateMember},
+		{ID: "removeMember", Method: "DELETE", Path: "/events/{id}/members/{personID}", Tag: "Members", Summary: "Remove a member, or leave the event",
+			Status: http.StatusNoContent, Handler: s.handleAPIRemoveMember},
+
+		{ID: "listTasks", Method: "GET", Path: "/events/{id}/tasks", Tag: "Tasks", Summary: "Filter and page through an event's tasks",
+			Query:    taskListQuery,
+			Response: []apiTask{}, Paged: true, Status: http.StatusOK, Handler: s.handleAPIListTasks},
+		{ID: "createTask", Method: "POST", Path: "/events/{id}/tasks", Tag: "Tasks", Summary: "Create a task (editor)",
+			Request: apiTaskCreate{}, Response: apiTask{}, Status: http.StatusCreated, Handler: s.handleAPICreateTask},
+		{ID: "getTask", Method: "GET", Path: "/tasks/{id}", Tag: "Tasks", Summary: "A task",
+			Response: apiTask{}, Status: http.StatusOK, Handler: s.handleAPIGetTask},
+		{ID: "updateTask", Method: "PATCH", Path: "/tasks/{id}", Tag: "Tasks", Summary: "Update a task (editor)",
+			Request: apiTaskUpdate{}, Response: apiTask{}, Status: http.StatusOK, Handler: s.handleAPIUpdateTask},
+		{ID: "deleteTask", Method: "DELETE", Path: "/tasks/{id}", Tag: "Tasks", Summary: "Move a task to the trash (editor)",
+			Status: http.StatusNoContent, Handler: s.handleAPIDeleteTask},
+		{ID: "restoreTask", Method: "POST", Path: "/tasks/{id}/restore", Tag: "Tasks", Summary: "Restore a task from the trash (editor)",
+			Response: apiTask{}, Status: http.StatusOK, Handler: s.handleAPIRestoreTask},
+		{ID: "listTaskEvents", Method: "GET", Path: "/tasks/{id}/events", Tag: "Tasks", Summary: "A task's audit history, newest first",
+			Response: []apiTaskEvent{}, Status: http.StatusOK, Handler: s.handleAPITaskEvents},
+		{ID: "searchTasks", Method: "GET", Path: "/search", Tag: "Tasks", Summary: "Full-text search over your events' tasks, best match first",
+			Query: []apiQueryParam{
+				{Name: "q", Type: "string", Description: "Web search syntax: words, \"quoted phrases\", -exclusions, or"},
+				{Name: "event_id", Type: "string", Format: "uuid"},
+			},
+			Response: []apiSearchHit{}, Status: http.StatusOK, Handler: s.handleAPISearch},
+	}
+}
+
+// OPENAPI SPEC (GET)
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, newOpenAPIDoc(s.apiEndpoints()))
+}
+
+type openAPIDoc struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       openAPIInfo                             `json:"info"`
+	Servers    []openAPIServer                         `json:"servers"`
+	Security   []map[string][]string                   `json:"security"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIComponents struct {
+	Schemas         map[string]*apiSchema            `json:"schemas"`
+	SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	In     string `json:"in,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+type openAPIOperation struct {
+	Summary     string                     `json:"summary"`
+	OperationID string                     `json:"operationId"`
+	Tags        []string                   `json:"tags"`
+	Security    *[]map[string][]string     `json:"security,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIBody               `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string     `json:"name"`
+	In          string     `json:"in"`
+	Required    bool       `json:"required"`
+	Description string     `json:"description,omitempty"`
+	Schema      *apiSchema `json:"schema"`
+}
+
+type openAPIBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *apiSchema `json:"schema"`
+}
+
+// apiSchema is the subset of OpenAPI 3.0 schema objects we generate.
+type apiSchema struct {
+	Ref         string                `json:"$ref,omitempty"`
+	Type        string                `json:"type,omitempty"`
+	Format      string                `json:"format,omitempty"`
+	Nullable    bool                  `json:"nullable,omitempty"`
+	Enum        []string              `json:"enum,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Items       *apiSchema            `json:"items,omitempty"`
+	Properties  map[string]*apiSchema `json:"properties,omitempty"`
+	Required    []string              `json:"required,omitempty"`
+	// AdditionalProperties is false for our structs and a schema for maps.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+}
+
+var chiParam = regexp.MustCompile(`\{(\w+)\}`)
+
+func newOpenAPIDoc(endpoints []apiEndpoint) *openAPIDoc {
+	g := &schemaGen{schemas: map[string]*apiSchema{}}
+	doc := &openAPIDoc{
+		OpenAPI:  "3.0.3",
+		Info:     openAPIInfo{Title: "SBF-OS API", Version: "1"},
+		Servers:  []openAPIServer{{URL: "/api/v1"}},
+		Security: []map[string][]string{{"bearerAuth": {}}, {"sessionCookie": {}}},
+		Paths:    map[string]map[string]*openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"bearerAuth":    {Type: "http", Scheme: "bearer"},
+				"sessionCookie": {Type: "apiKey", In: "cookie", Name: "session"},
+			},
+		},
+	}
+	errorSchema := g.schema(reflect.TypeOf(apiErrorResponse{}), false)
+
+	for _, e := range endpoints {
+		op := &openAPIOperation{
+			Summary:     e.Summary,
+			OperationID: e.ID,
+			Tags:        []string{e.Tag},
+			Responses: map[string]openAPIResponse{
+				"default": {Description: "Error", Content: jsonContent(errorSchema)},
+			},
+		}
+		if e.Public {
+			op.Security = &[]map[string][]string{}
+		}
+		for _, m := range chiParam.FindAllStringSubmatch(e.Path, -1) {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name: m[1], In: "path", Required: true,
+				Schema: &apiSchema{Type: "string", Format: "uuid"},
+			})
+		}
+		for _, q := range e.Query {
+			op.Parameters = append(op.Parameters, openAPIParameter{
+				Name: q.Name, In: "query", Description: q.Description,
+				Schema: &apiSchema{Type: q.Type, Format: q.Format, Enum: q.Enum},
+			})
+		}
+		if e.Request != nil {
+			op.RequestBody = &openAPIBody{
+				Required: true,
+				Content:  jsonContent(g.schema(reflect.TypeOf(e.Request), true)),
+			}
+		}
+		ok := openAPIResponse{Description: http.StatusText(e.Status)}
+		if e.Response != nil {
+			envelope := &apiSchema{
+				Type:                 "object",
+				Properties:           map[string]*apiSchema{"data": g.schema(reflect.TypeOf(e.Response), false)},
+				Required:             []string{"data"},
+				AdditionalProperties: false,
+			}
+			if e.Paged {
+				envelope.Properties["meta"] = g.schema(reflect.TypeOf(apiMeta{}), false)
+				envelope.Required = append(envelope.Required, "meta")
+			}
+			ok.Content = jsonContent(envelope)
+		}
+		op.Responses[strconv.Itoa(e.Status)] = ok
+
+		if doc.Paths[e.Path] == nil {
+			doc.Paths[e.Path] = map[string]*openAPIOperation{}
+		}
+		doc.Paths[e.Path][strings.ToLower(e.Method)] = op
+	}
+	return doc
+}
+
+func jsonContent(s *apiSchema) map[string]openAPIMediaType {
+	return map[string]openAPIMediaType{"application/json": {Schema: s}}
+}
+
+// schemaGen builds schemas by reflecting over the API types, which mirror
+// db.Task, db.Event, db.Person and friends with wire-friendly fields. Struct
+// types become components named after the type minus its "api" prefix.
+//
+// Fields follow their json tags; omitempty fields aren't required and
+// pointers are nullable. In request bodies pointers and slices are also
+// optional. `format` and `enum` struct tags refine string fields.
+type schemaGen struct {
+	schemas map[string]*apiSchema
+}
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+	uuidType = reflect.TypeOf(uuid.UUID{})
+	rawType  = reflect.TypeOf(json.RawMessage{})
+)
+
+func (g *schemaGen) schema(t reflect.Type, request bool) *apiSchema {
+	switch t {
+	case timeType:
+		return &apiSchema{Type: "string", Format: "date-time"}
+	case uuidType:
+		return &apiSchema{Type: "string", Format: "uuid"}
+	case rawType:
+		return &apiSchema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := g.schema(t.Elem(), request)
+		if s.Ref != "" {
+			// $ref siblings are ignored in 3.0, so nullable refs are left as is.
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.String:
+		return &apiSchema{Type: "string"}
+	case reflect.Bool:
+		return &apiSchema{Type: "boolean"}
+	case reflect.Int32:
+		return &apiSchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &apiSchema{Type: "integer", Format: "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &apiSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &apiSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &apiSchema{Type: "array", Items: g.schema(t.Elem(), request)}
+	case reflect.Map:
+		return &apiSchema{Type: "object", AdditionalProperties: g.schema(t.Elem(), request)}
+	case reflect.Struct:
+		name := strings.TrimPrefix(t.Name(), "api")
+		if _, seen := g.schemas[name]; !seen {
+			obj := &apiSchema{Type: "object", Properties: map[string]*apiSchema{}, AdditionalProperties: false}
+			g.schemas[name] = obj // placeholder first, for recursive types
+			g.fields(obj, t, request)
+			sort.Strings(obj.Required)
+		}
+		return &apiSchema{Ref: "#/components/schemas/" + name}
+	}
+	// interface{} and anything else: any value.
+	return &apiSchema{}
+}
+
+func (g *schemaGen) fields(obj *apiSchema, t reflect.Type, request bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			g.fields(obj, f.Type, request)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+
+		s := g.schema(f.Type, request)
+		if format := f.Tag.Get("format"); format != "" {
+			s.Format = format
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			s.Enum = strings.Split(enum, ",")
+		}
+		obj.Properties[name] = s
+
+		optional := strings.Contains(opts, "omitempty") ||
+			(request && (f.Type.Kind() == reflect.Ptr || f.Type.Kind() == reflect.Slice))
+		if !optional {
+			obj.Required = append(obj.Required, name)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/alexedwards/scs/v2"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// apiContract drives real requests through the router and checks each
+// response against the OpenAPI document built from the same endpoint table.
+type apiContract struct {
+	t       *testing.T
+	handler http.Handler
+	doc     *openAPIDoc
+	byID    map[string]apiEndpoint
+	covered map[string]bool
+	token   string
+}
+
+// call sends one request to the endpoint with the given operationId and
+// fails the test if the status differs from want or the response breaks
+// the spec. It returns the response's "data".
+func (c *apiContract) call(id, path string, body interface{}, want int) map[string]interface{} {
+	c.t.Helper()
+	raw := c.send(id, path, body, want)
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		// Lists come back as arrays; hand back the first item.
+		var list []map[string]interface{}
+		if json.Unmarshal(raw, &list) == nil && len(list) > 0 {
+			data = list[0]
+		}
+	}
+	return data
+}
+
+// list is call for endpoints that return an array, returning all of it.
+func (c *apiContract) list(id, path string) []map[string]interface{} {
+	c.t.Helper()
+	var list []map[string]interface{}
+	if err := json.Unmarshal(c.send(id, path, nil, http.StatusOK), &list); err != nil {
+		c.t.Fatalf("%s: data is not a list: %v", path, err)
+	}
+	return list
+}
+
+func (c *apiContract) send(id, path string, body interface{}, want int) json.RawMessage {
+	c.t.Helper()
+	e, ok := c.byID[id]
+	if !ok {
+		c.t.Fatalf("no endpoint %q", id)
+	}
+	c.covered[id] = true
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(e.Method, "/api/v1"+path, &reqBody)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	rec := httptest.NewRecorder()
+	c.handler.ServeHTTP(rec, req)
+
+	if rec.Code != want {
+		c.t.Fatalf("%s %s: status %d, want %d: %s", e.Method, path, rec.Code, want, rec.Body.String())
+	}
+	op := c.doc.Paths[e.Path][strings.ToLower(e.Method)]
+	for _, problem := range checkAPIResponse(c.doc, e, op, rec.Code, rec.Body.Bytes()) {
+		c.t.Errorf("%s %s -> %d: %s", e.Method, path, rec.Code, problem)
+	}
+
+	var out struct {
+		Data json.RawMessage `json:"data"`
+	}
+	_ = json.Unmarshal(rec.Body.Bytes(), &out)
+	return out.Data
+}
+
+// newAPIContract wires a server to TEST_DATABASE_URL, skipping the test when
+// it isn't set. The database must be migrated (e.g.
+// `goose -dir sql/migrations postgres "$TEST_DATABASE_URL" up`).
+func newAPIContract(t *testing.T) (*apiContract, *Server) {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	dbConn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { dbConn.Close() })
+
+	session := scs.New()
+	s := NewServer(dbConn, session, Config{TrashRetentionDays: 30})
+	endpoints := s.apiEndpoints()
+	c := &apiContract{
+		t:       t,
+		handler: session.LoadAndSave(s.Router),
+		doc:     newOpenAPIDoc(endpoints),
+		byID:    map[string]apiEndpoint{},
+		covered: map[string]bool{},
+	}
+	for _, e := range endpoints {
+		c.byID[e.ID] = e
+	}
+	return c, s
+}
+
+// contractPerson creates a person who can log in with the given password.
+func contractPerson(t *testing.T, s *Server, name, email, password string) db.Person {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := s.Q.CreatePerson(context.Background(), db.CreatePersonParams{
+		Name:         name,
+		Email:        sql.NullString{String: email, Valid: true},
+		PasswordHash: sql.NullString{String: string(hash), Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// TestAPIMatchesOpenAPI needs a migrated Postgres database in
+// TEST_DATABASE_URL. It creates its own people and event, so a shared
+// scratch database is fine.
+func TestAPIMatchesOpenAPI(t *testing.T) {
+	c, s := newAPIContract(t)
+	endpoints := s.apiEndpoints()
+
+	ctx := context.Background()
+	suffix := uuid.NewString()[:8]
+	owner := contractPerson(t, s, "Contract Owner "+suffix, "owner-"+suffix+"@example.com", "contract-test")
+	helper, err := s.Q.CreatePerson(ctx, db.CreatePersonParams{
+		Name:  "Contract Helper " + suffix,
+		Email: sql.NullString{String: "helper-" + suffix + "@example.com", Valid: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Errors are part of the contract too.
+	c.call("getMe", "/me", nil, http.StatusUnauthorized)
+	c.call("issueToken", "/auth/token", apiTokenRequest{Email: owner.Email.String, Password: "wrong"}, http.StatusUnauthorized)
+
+	issued := c.call("issueToken", "/auth/token", apiTokenRequest{Email: owner.Email.String, Password: "contract-test"}, http.StatusCreated)
+	c.token, _ = issued["token"].(string)
+
+	c.call("getMe", "/me", nil, http.StatusOK)
+	c.call("listPeople", "/people", nil, http.StatusOK)
+
+	tmpl := c.call("listTemplates", "/templates", nil, http.StatusOK)
+	tmplID, _ := tmpl["id"].(string)
+	if tmplID == "" {
+		t.Fatal("no templates; is the database migrated?")
+	}
+	c.call("getTemplate", "/templates/"+tmplID, nil, http.StatusOK)
+
+	templateID := uuid.MustParse(tmplID)
+	event := c.call("createEvent", "/events", apiEventCreate{Name: "Contract Fair " + suffix, EventDate: "2030-06-01", TemplateID: &templateID}, http.StatusCreated)
+	eventID, _ := event["id"].(string)
+	c.call("createEvent", "/events", apiEventCreate{Name: ""}, http.StatusUnprocessableEntity)
+	c.call("listEvents", "/events", nil, http.StatusOK)
+	c.call("getEvent", "/events/"+eventID, nil, http.StatusOK)
+	c.call("getEvent", "/events/"+uuid.NewString(), nil, http.StatusNotFound)
+	location := "Main hall"
+	c.call("updateEvent", "/events/"+eventID, apiEventUpdate{Location: &location}, http.StatusOK)
+
+	c.call("listMembers", "/events/"+eventID+"/members", nil, http.StatusOK)
+	c.call("addMember", "/events/"+eventID+"/members", apiMemberCreate{Email: helper.Email.String, Role: RoleEditor}, http.StatusCreated)
+	c.call("updateMember", "/events/"+eventID+"/members/"+helper.ID.String(), apiMemberUpdate{Role: RoleViewer}, http.StatusNoContent)
+
+	desc, due := "Contract task", "2030-05-01"
+	task := c.call("createTask", "/events/"+eventID+"/tasks", apiTaskCreate{
+		Title:       "Book the stage " + suffix,
+		Description: &desc,
+		DueDate:     &due,
+		OwnerID:     &owner.ID,
+		Tags:        []string{"contract"},
+		Subtasks:    []logic.Subtask{{Title: "Call venues"}},
+	}, http.StatusCreated)
+	taskID, _ := task["id"].(string)
+	upstream := c.call("createTask", "/events/"+eventID+"/tasks", apiTaskCreate{Title: "Pick a date " + suffix}, http.StatusCreated)
+	upstreamID, _ := upstream["id"].(string)
+
+	c.call("listTasks", "/events/"+eventID+"/tasks?sort=due&limit=1", nil, http.StatusOK)
+	c.call("listTasks", "/events/"+eventID+"/tasks?sort=risk&status=backlog,in_progress", nil, http.StatusOK)
+	c.call("listTasks", "/events/"+eventID+"/tasks?sort=nope", nil, http.StatusUnprocessableEntity)
+	c.call("getTask", "/tasks/"+taskID, nil, http.StatusOK)
+	status := "in_progress"
+	c.call("updateTask", "/tasks/"+taskID, apiTaskUpdate{Status: &status}, http.StatusOK)
+
+	// Dependencies have no API yet, but their ledger entries show up in the history.
+	dep, err := s.Q.GetTask(ctx, uuid.MustParse(upstreamID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Q.AddTaskDependency(ctx, db.AddTaskDependencyParams{TaskID: uuid.MustParse(taskID), DependencyID: dep.ID}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordTaskEvent(ctx, s.Q, uuid.MustParse(taskID), logic.EventDependencyAdded, logic.DependencyChanges(dep, true)); err != nil {
+		t.Fatal(err)
+	}
+	c.call("listTaskEvents", "/tasks/"+taskID+"/events", nil, http.StatusOK)
+	c.call("searchTasks", "/search?q=stage+"+suffix+"&event_id="+eventID, nil, http.StatusOK)
+	c.call("searchTasks", "/search", nil, http.StatusUnprocessableEntity)
+
+	c.call("deleteTask", "/tasks/"+taskID, nil, http.StatusNoContent)
+	c.call("getTask", "/tasks/"+taskID, nil, http.StatusNotFound)
+	c.call("restoreTask", "/tasks/"+taskID+"/restore", nil, http.StatusOK)
+
+	c.call("removeMember", "/events/"+eventID+"/members/"+helper.ID.String(), nil, http.StatusNoContent)
+
+	// A read-only token can't write, but can still revoke itself.
+	readToken, _, err := s.mintAPIToken(ctx, db.CreateApiTokenParams{PersonID: owner.ID, Name: "Contract read", Scope: TokenScopeRead})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.token, readToken = readToken, c.token
+	c.call("updateEvent", "/events/"+eventID, apiEventUpdate{Location: &location}, http.StatusForbidden)
+	c.call("revokeToken", "/auth/token", nil, http.StatusNoContent)
+	c.token = readToken
+
+	c.call("revokeToken", "/auth/token", nil, http.StatusNoContent)
+	c.call("getMe", "/me", nil, http.StatusUnauthorized)
+
+	for _, e := range endpoints {
+		if !c.covered[e.ID] {
+			t.Errorf("%s %s (%s) is not exercised by this test", e.Method, e.Path, e.ID)
+		}
+	}
+}
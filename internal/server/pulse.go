@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// pulseOption is one entry of the event or owner filter.
+type pulseOption struct {
+	ID   string
+	Name string
+}
+
+// PULSE (GET)
+func (s *Server) handlePulse(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+	v := r.URL.Query()
+	q, problems := parseTaskQuery(v, openStatuses, SortRisk)
+	var eventFilter uuid.NullUUID
+	if raw := v.Get("event"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			problems["event"] = "must be an event ID"
+		}
+		eventFilter = uuid.NullUUID{UUID: id, Valid: err == nil}
+	}
+	if len(problems) > 0 {
+		http.Error(w, problemText(problems), http.StatusBadRequest)
+		return
+	}
+
+	page, err := s.listTasks(r.Context(), user.ID, eventFilter, q)
+	if err != nil {
+		http.Error(w, "Failed to fetch tasks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Options cover every event the caller can see, so narrowing never hides them.
+	memberships, err := s.Q.ListUserEvents(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var events []pulseOption
+	for _, e := range memberships {
+		events = append(events, pulseOption{ID: e.ID.String(), Name: e.Name})
+	}
+	categories, owners, err := s.taskFilterOptions(r.Context(), user.ID, uuid.NullUUID{})
+	if err != nil {
+		http.Error(w, "Failed to fetch filters: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	editable := map[uuid.UUID]bool{}
+	for _, st := range page.Tasks {
+		t := st.Task.(db.ListTasksRow)
+		editable[t.EventID] = roleAtLeast(t.UserRole, RoleEditor)
+	}
+
+	data := struct {
+		Tasks      []logic.ScoredTask
+		Events     []pulseOption
+		Categories []string
+		Owners     []pulseOption
+		Event      string
+		Filter     taskFilterForm
+		Editable   map[uuid.UUID]bool
+		BlockedBy  map[uuid.UUID][]string
+		NextPage   string
+		Partial    bool
+		ScanLimit  int
+	}{
+		Tasks:      page.Tasks,
+		Events:     events,
+		Categories: categories,
+		Owners:     owners,
+		Event:      v.Get("event"),
+		Filter:     newTaskFilterForm(v, q),
+		Editable:   editable,
+		BlockedBy:  page.BlockedBy,
+		NextPage:   nextPageURL(r.URL, page.NextCursor),
+		Partial:    page.Partial,
+		ScanLimit:  riskScanLimit,
+	}
+	s.render(w, r, "pulse.html", data)
+}
+
+// taskFilterForm echoes a listing's filters back into its form.
+type taskFilterForm struct {
+	Status   string
+	Category string
+	Owner    string
+	Tag      string
+	DueFrom  string
+	DueTo    string
+	Risk     string
+	Text     string
+	Sort     string
+	// Filtered is set when anything beyond the defaults is applied.
+	Filtered bool
+}
+
+func newTaskFilterForm(v url.Values, q taskQuery) taskFilterForm {
+	f := taskFilterForm{
+		Status:   v.Get("status"),
+		Category: q.Category,
+		Owner:    v.Get("owner"),
+		Tag:      strings.Join(q.Tags, ", "),
+		DueFrom:  v.Get("due_from"),
+		DueTo:    v.Get("due_to"),
+		Risk:     q.Risk,
+		Text:     q.Text,
+		Sort:     q.Sort,
+	}
+	f.Filtered = f.Status != "" || f.Category != "" || f.Owner != "" || f.Tag != "" ||
+		f.DueFrom != "" || f.DueTo != "" || f.Risk != "" || f.Text != ""
+	return f
+}
+
+// taskFilterOptions lists the categories and owners a filter form offers.
+func (s *Server) taskFilterOptions(ctx context.Context, personID uuid.UUID, eventID uuid.NullUUID) ([]string, []pulseOption, error) {
+	categories, err := s.Q.ListTaskCategories(ctx, db.ListTaskCategoriesParams{PersonID: personID, EventID: eventID})
+	if err != nil {
+		return nil, nil, err
+	}
+	rows, err := s.Q.ListTaskOwners(ctx, db.ListTaskOwnersParams{PersonID: personID, EventID: eventID})
+	if err != nil {
+		return nil, nil, err
+	}
+	owners := make([]pulseOption, 0, len(rows))
+	for _, o := range rows {
+		owners = append(owners, pulseOption{ID: o.ID.String(), Name: o.Name})
+	}
+	return categories, owners, nil
+}
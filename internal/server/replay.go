@@ -0,0 +1,102 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// parseReplayTime accepts RFC3339 or the browser's datetime-local format
+// (interpreted as UTC, like the TIMESTAMP columns). Empty means now.
+func parseReplayTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Now().UTC(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t.UTC(), nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, raw, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("ts must be RFC3339 or YYYY-MM-DDTHH:MM")
+}
+
+// TASK AS OF (GET) — HTML, or JSON with ?format=json
+func (s *Server) handleTaskAt(w http.ResponseWriter, r *http.Request) {
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid task id", http.StatusBadRequest)
+		return
+	}
+	at, err := parseReplayTime(r.URL.Query().Get("ts"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Deleted tasks have history too, so don't filter on deleted_at here.
+	task, err := s.Q.GetTaskIncludingDeleted(r.Context(), taskID)
+	if err != nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, task.EventID, RoleViewer); !ok {
+		return
+	}
+
+	events, err := s.Q.GetTaskEvents(r.Context(), taskID)
+	if err != nil {
+		http.Error(w, "Failed to fetch events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	replay, replayErr := logic.ReplayTask(events, at)
+	head, headErr := logic.ReplayTask(events, time.Now().UTC().Add(time.Minute))
+
+	drift := []logic.Change{}
+	if headErr == nil {
+		drift = logic.CheckDrift(head.State, task)
+	}
+
+	data := struct {
+		Task       db.Task            `json:"-"`
+		TaskID     uuid.UUID          `json:"task_id"`
+		At         time.Time          `json:"at"`
+		Exists     bool               `json:"exists"`
+		Error      string             `json:"error,omitempty"`
+		Replay     logic.ReplayResult `json:"replay"`
+		Fields     []logic.StateField `json:"-"`
+		HeadError  string             `json:"head_error,omitempty"`
+		Drift      []logic.Change     `json:"drift"`
+		Consistent bool               `json:"consistent"`
+	}{
+		Task:       task,
+		TaskID:     task.ID,
+		At:         at,
+		Exists:     replayErr == nil,
+		Replay:     replay,
+		Fields:     replay.State.Fields(),
+		Drift:      drift,
+		Consistent: headErr == nil && len(drift) == 0 && len(head.Conflicts) == 0,
+	}
+	if replayErr != nil {
+		data.Error = replayErr.Error()
+	}
+	if headErr != nil {
+		data.HeadError = headErr.Error()
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, data)
+		return
+	}
+	s.render(w, r, "task_at.html", data)
+}
@@ -0,0 +1,486 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// maxProfileName matches the length check on risk_profiles.name.
+const maxProfileName = 80
+
+// riskPage is shared by the GET view, profile previews and POST previews.
+type riskPage struct {
+	Event   db.Event
+	Profile logic.RiskProfile
+	// Attached is the event's profile; nil means the built-in defaults.
+	Attached *db.RiskProfile
+	// SharedWith lists the other events using the attached profile that the
+	// viewer belongs to; SharedElsewhere counts the rest.
+	SharedWith      []db.ListRiskProfileEventsRow
+	SharedElsewhere int
+	// Picked is the profile previewed with ?profile=, offered for attaching.
+	Picked   *db.ListRiskProfilesForPersonRow
+	Profiles []db.ListRiskProfilesForPersonRow
+	Name     string
+	CanEdit  bool
+	// CanSave is set when Save may overwrite the attached profile in place.
+	CanSave  bool
+	Draft    bool
+	Error    string
+	Ranking  []logic.RankChange
+	Movement int
+}
+
+// riskProfileName trims a submitted profile name and checks its length.
+func riskProfileName(raw string) (string, error) {
+	name := strings.TrimSpace(raw)
+	if name == "" {
+		return "", errors.New("give the profile a name")
+	}
+	if utf8.RuneCountInString(name) > maxProfileName {
+		return "", fmt.Errorf("profile names are limited to %d characters", maxProfileName)
+	}
+	return name, nil
+}
+
+// canSaveInPlace reports whether someone may overwrite a profile used by
+// events: built-in rows never change, and a shared profile only changes when
+// the caller owns every event it scores.
+func canSaveInPlace(p *db.RiskProfile, events []db.ListRiskProfileEventsRow) bool {
+	if p == nil || p.Builtin {
+		return false
+	}
+	for _, e := range events {
+		if e.UserRole.String != RoleOwner {
+			return false
+		}
+	}
+	return true
+}
+
+// eventRiskProfile loads the profile attached to an event along with the
+// events sharing it. Both are empty when the event uses the defaults.
+func (s *Server) eventRiskProfile(ctx context.Context, eventID, personID uuid.UUID) (*db.RiskProfile, []db.ListRiskProfileEventsRow, error) {
+	row, err := s.Q.GetEventRiskProfile(ctx, eventID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	events, err := s.Q.ListRiskProfileEvents(ctx, db.ListRiskProfileEventsParams{
+		RiskProfileID: uuid.NullUUID{UUID: row.ID, Valid: true},
+		PersonID:      personID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &row, events, nil
+}
+
+// visibleRiskProfile finds id among the profiles a person may pick from.
+func visibleRiskProfile(profiles []db.ListRiskProfilesForPersonRow, raw string) (*db.ListRiskProfilesForPersonRow, bool) {
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, false
+	}
+	for i := range profiles {
+		if profiles[i].ID == id {
+			return &profiles[i], true
+		}
+	}
+	return nil, false
+}
+
+// RISK PROFILE (GET)
+func (s *Server) handleEventRisk(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	role, ok := s.authorizeEvent(w, r, eventID, RoleViewer)
+	if !ok {
+		return
+	}
+
+	data, err := s.newRiskPage(r, eventID, role)
+	if err != nil {
+		http.Error(w, "Failed to load risk profile: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if raw := r.URL.Query().Get("profile"); raw != "" && data.CanEdit {
+		picked, ok := visibleRiskProfile(data.Profiles, raw)
+		if !ok {
+			http.Error(w, "Unknown risk profile", http.StatusBadRequest)
+			return
+		}
+		data.Picked, data.Draft, data.Name = picked, true, picked.Name
+		row, err := s.Q.GetRiskProfile(r.Context(), picked.ID)
+		if err != nil {
+			http.Error(w, "Failed to load risk profile: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		data.Profile = logic.RiskProfileFromDB(row)
+	}
+
+	s.renderRiskPage(w, r, data)
+}
+
+// RISK PROFILE: PREVIEW / SAVE / SAVE AS NEW / USE / RESET (POST)
+func (s *Server) handleUpdateRiskProfile(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	role, ok := s.authorizeEvent(w, r, eventID, RoleOwner)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	user, _ := currentUser(ctx)
+
+	back := "/events/" + eventID.String() + "/risk"
+	data, err := s.newRiskPage(r, eventID, role)
+	if err != nil {
+		http.Error(w, "Failed to load risk profile: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch r.FormValue("action") {
+	case "reset":
+		if err := s.Q.SetEventRiskProfile(ctx, db.SetEventRiskProfileParams{ID: eventID}); err != nil {
+			http.Error(w, "Reset failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.setFlash(r, "Risk scoring is back on the defaults.")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	case "use":
+		picked, ok := visibleRiskProfile(data.Profiles, r.FormValue("profile_id"))
+		if !ok {
+			http.Error(w, "Unknown risk profile", http.StatusBadRequest)
+			return
+		}
+		if err := s.Q.SetEventRiskProfile(ctx, db.SetEventRiskProfileParams{
+			ID:            eventID,
+			RiskProfileID: uuid.NullUUID{UUID: picked.ID, Valid: true},
+		}); err != nil {
+			http.Error(w, "Switching profiles failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.setFlash(r, "This event now uses the “"+picked.Name+"” profile.")
+		http.Redirect(w, r, back, http.StatusSeeOther)
+		return
+	}
+
+	data.Draft = true
+	data.Name = r.FormValue("name")
+	for _, f := range data.Profile.Fields() {
+		v, err := strconv.Atoi(r.FormValue(f.Key))
+		if err != nil {
+			data.Error = f.Label + " must be a whole number"
+			s.renderRiskPage(w, r, data)
+			return
+		}
+		data.Profile.Set(f.Key, v)
+	}
+	if err := data.Profile.Validate(); err != nil {
+		data.Error = err.Error()
+		s.renderRiskPage(w, r, data)
+		return
+	}
+
+	action := r.FormValue("action")
+	if action != "save" && action != "save_new" {
+		s.renderRiskPage(w, r, data)
+		return
+	}
+	name, err := riskProfileName(data.Name)
+	if err != nil {
+		data.Error = err.Error()
+		s.renderRiskPage(w, r, data)
+		return
+	}
+	if action == "save" && !data.CanSave {
+		data.Error = "this profile is built in or scores events you don't own, so save your changes as a new profile instead"
+		s.renderRiskPage(w, r, data)
+		return
+	}
+	if action == "save" {
+		_, err = s.Q.UpdateRiskProfile(ctx, updateRiskProfileParams(data.Attached.ID, name, data.Profile))
+		if errors.Is(err, sql.ErrNoRows) {
+			err = errors.New("the profile is built in")
+		}
+	} else {
+		err = s.Q.RunTx(ctx, s.DB, func(qtx *db.Queries) error {
+			created, err := qtx.CreateRiskProfile(ctx, createRiskProfileParams(name, user.ID, data.Profile))
+			if err != nil {
+				return err
+			}
+			return qtx.SetEventRiskProfile(ctx, db.SetEventRiskProfileParams{
+				ID:            eventID,
+				RiskProfileID: uuid.NullUUID{UUID: created.ID, Valid: true},
+			})
+		})
+	}
+	if err != nil {
+		http.Error(w, "Save failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.setFlash(r, "Risk profile “"+name+"” saved.")
+	http.Redirect(w, r, back, http.StatusSeeOther)
+}
+
+// newRiskPage fills in the event, its attached profile and, for owners, the
+// profiles they can pick from. Profile starts as the weights in force.
+func (s *Server) newRiskPage(r *http.Request, eventID uuid.UUID, role string) (riskPage, error) {
+	ctx := r.Context()
+	user, _ := currentUser(ctx)
+
+	event, err := s.Q.GetEvent(ctx, eventID)
+	if err != nil {
+		return riskPage{}, err
+	}
+	attached, events, err := s.eventRiskProfile(ctx, eventID, user.ID)
+	if err != nil {
+		return riskPage{}, err
+	}
+
+	data := riskPage{
+		Event:    event,
+		Profile:  logic.DefaultRiskProfile(),
+		Attached: attached,
+		CanEdit:  role == RoleOwner,
+	}
+	if attached != nil {
+		data.Profile = logic.RiskProfileFromDB(*attached)
+		data.Name = attached.Name
+		data.CanSave = data.CanEdit && canSaveInPlace(attached, events)
+		for _, e := range events {
+			switch {
+			case e.ID == eventID:
+			case e.UserRole.Valid:
+				data.SharedWith = append(data.SharedWith, e)
+			default:
+				data.SharedElsewhere++
+			}
+		}
+	}
+	if data.CanEdit {
+		data.Profiles, err = s.Q.ListRiskProfilesForPerson(ctx, uuid.NullUUID{UUID: user.ID, Valid: true})
+		if err != nil {
+			return riskPage{}, err
+		}
+	}
+	return data, nil
+}
+
+// renderRiskPage ranks the event's tasks under data.Profile against the
+// weights currently in force and renders the page.
+func (s *Server) renderRiskPage(w http.ResponseWriter, r *http.Request, data riskPage) {
+	tasks, err := s.Q.GetEventTasks(r.Context(), db.GetEventTasksParams{EventID: data.Event.ID, Column2: false})
+	if err != nil {
+		http.Error(w, "Failed to fetch tasks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deps, err := s.Q.ListEventDependencies(r.Context(), data.Event.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch dependencies: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	current := logic.DefaultRiskProfile()
+	if data.Attached != nil {
+		current = logic.RiskProfileFromDB(*data.Attached)
+	}
+	// Only rank a draft that passed validation; a bad one would just mislead.
+	if data.Error == "" {
+		signals := logic.DependencySignals(tasks, deps, data.Event.EventDate, time.Now())
+		data.Ranking = logic.PreviewProfile(tasks, signals, current, data.Profile)
+		for _, c := range data.Ranking {
+			if c.Movement != 0 {
+				data.Movement++
+			}
+		}
+	}
+	s.render(w, r, "risk_profile.html", data)
+}
+
+// createRiskProfileParams and updateRiskProfileParams copy a profile's knobs
+// into the query arguments.
+func createRiskProfileParams(name string, createdBy uuid.UUID, p logic.RiskProfile) db.CreateRiskProfileParams {
+	return db.CreateRiskProfileParams{
+		Name:                  name,
+		CreatedBy:             uuid.NullUUID{UUID: createdBy, Valid: true},
+		OverduePoints:         int32(p.OverduePoints),
+		DueSoonPoints:         int32(p.DueSoonPoints),
+		DueSoonDays:           int32(p.DueSoonDays),
+		StaleShortDays:        int32(p.StaleShortDays),
+		StaleShortPoints:      int32(p.StaleShortPoints),
+		StaleLongDays:         int32(p.StaleLongDays),
+		StaleLongPoints:       int32(p.StaleLongPoints),
+		BlockedPoints:         int32(p.BlockedPoints),
+		PriorityWeight:        int32(p.PriorityWeight),
+		BlocksWeight:          int32(p.BlocksWeight),
+		BlocksCap:             int32(p.BlocksCap),
+		OverdueUpstreamPoints: int32(p.OverdueUpstreamPoints),
+		CriticalStepPoints:    int32(p.CriticalStepPoints),
+		BehindSchedulePoints:  int32(p.BehindSchedulePoints),
+		HighThreshold:         int32(p.HighThreshold),
+		MedThreshold:          int32(p.MedThreshold),
+	}
+}
+
+func updateRiskProfileParams(id uuid.UUID, name string, p logic.RiskProfile) db.UpdateRiskProfileParams {
+	return db.UpdateRiskProfileParams{
+		ID:                    id,
+		Name:                  name,
+		OverduePoints:         int32(p.OverduePoints),
+		DueSoonPoints:         int32(p.DueSoonPoints),
+		DueSoonDays:           int32(p.DueSoonDays),
+		StaleShortDays:        int32(p.StaleShortDays),
+		StaleShortPoints:      int32(p.StaleShortPoints),
+		StaleLongDays:         int32(p.StaleLongDays),
+		StaleLongPoints:       int32(p.StaleLongPoints),
+		BlockedPoints:         int32(p.BlockedPoints),
+		PriorityWeight:        int32(p.PriorityWeight),
+		BlocksWeight:          int32(p.BlocksWeight),
+		BlocksCap:             int32(p.BlocksCap),
+		OverdueUpstreamPoints: int32(p.OverdueUpstreamPoints),
+		CriticalStepPoints:    int32(p.CriticalStepPoints),
+		BehindSchedulePoints:  int32(p.BehindSchedulePoints),
+		HighThreshold:         int32(p.HighThreshold),
+		MedThreshold:          int32(p.MedThreshold),
+	}
+}
+
+// yesterdayRiskScores loads the previous day's snapshot as task ID → score.
+func (s *Server) yesterdayRiskScores(ctx context.Context, eventID uuid.UUID) (map[uuid.UUID]int, error) {
+	yesterday := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	rows, err := s.Q.ListEventRiskSnapshots(ctx, db.ListEventRiskSnapshotsParams{
+		EventID:      eventID,
+		SnapshotDate: yesterday,
+	})
+	if err != nil {
+		return nil, err
+	}
+	scores := make(map[uuid.UUID]int, len(rows))
+	for _, row := range rows {
+		scores[row.TaskID] = int(row.Score)
+	}
+	return scores, nil
+}
+
+// riskHistoryPoint is one day of an event's summed risk, placed on the chart.
+type riskHistoryPoint struct {
+	Date       time.Time `json:"date"`
+	TotalScore int       `json:"total_score"`
+	HighTasks  int       `json:"high_tasks"`
+	Tasks      int       `json:"tasks"`
+	X          int       `json:"-"`
+	Y          int       `json:"-"`
+}
+
+const (
+	riskChartWidth  = 600
+	riskChartHeight = 160
+)
+
+// RISK HISTORY (GET)
+func (s *Server) handleEventRiskHistory(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleViewer); !ok {
+		return
+	}
+
+	days, _ := strconv.Atoi(r.URL.Query().Get("days"))
+	if days <= 0 || days > 365 {
+		days = 30
+	}
+
+	event, err := s.Q.GetEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	rows, err := s.Q.GetEventRiskHistory(r.Context(), db.GetEventRiskHistoryParams{
+		EventID:      eventID,
+		SnapshotDate: time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -days),
+	})
+	if err != nil {
+		http.Error(w, "Failed to load risk history: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	points := make([]riskHistoryPoint, 0, len(rows))
+	maxScore := 1
+	for _, row := range rows {
+		points = append(points, riskHistoryPoint{
+			Date:       row.SnapshotDate,
+			TotalScore: int(row.TotalScore),
+			HighTasks:  int(row.HighTasks),
+			Tasks:      int(row.Tasks),
+		})
+		maxScore = max(maxScore, int(row.TotalScore))
+	}
+
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, points)
+		return
+	}
+
+	// Scale into the SVG box; a single day sits in the middle.
+	var polyline []string
+	for i := range points {
+		x := riskChartWidth / 2
+		if len(points) > 1 {
+			x = i * riskChartWidth / (len(points) - 1)
+		}
+		points[i].X = x
+		points[i].Y = riskChartHeight - points[i].TotalScore*riskChartHeight/maxScore
+		polyline = append(polyline, strconv.Itoa(x)+","+strconv.Itoa(points[i].Y))
+	}
+
+	data := struct {
+		Event    db.Event
+		Days     int
+		Points   []riskHistoryPoint
+		Polyline string
+		MaxScore int
+		Width    int
+		Height   int
+	}{
+		Event:    event,
+		Days:     days,
+		Points:   points,
+		Polyline: strings.Join(polyline, " "),
+		MaxScore: maxScore,
+		Width:    riskChartWidth,
+		Height:   riskChartHeight,
+	}
+	s.render(w, r, "risk_history.html", data)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+func TestRiskProfileName(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		wantErr   bool
+	}{
+		{raw: "  Conference  ", want: "Conference"},
+		{raw: "   ", wantErr: true},
+		{raw: strings.Repeat("é", maxProfileName), want: strings.Repeat("é", maxProfileName)},
+		{raw: strings.Repeat("a", maxProfileName+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := riskProfileName(tt.raw)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("riskProfileName(%q) = %q, %v; want %q, error %v", tt.raw, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestCanSaveInPlace(t *testing.T) {
+	role := func(r string) db.ListRiskProfileEventsRow {
+		return db.ListRiskProfileEventsRow{ID: uuid.New(), UserRole: sql.NullString{String: r, Valid: r != ""}}
+	}
+	custom := &db.RiskProfile{ID: uuid.New(), Name: "Conference"}
+	builtin := &db.RiskProfile{ID: uuid.New(), Name: "Default", Builtin: true}
+
+	tests := []struct {
+		name    string
+		profile *db.RiskProfile
+		events  []db.ListRiskProfileEventsRow
+		want    bool
+	}{
+		{name: "defaults", profile: nil, want: false},
+		{name: "built in", profile: builtin, events: []db.ListRiskProfileEventsRow{role(RoleOwner)}, want: false},
+		{name: "own event only", profile: custom, events: []db.ListRiskProfileEventsRow{role(RoleOwner)}, want: true},
+		{name: "shared, all owned", profile: custom, events: []db.ListRiskProfileEventsRow{role(RoleOwner), role(RoleOwner)}, want: true},
+		{name: "shared with an event edited", profile: custom, events: []db.ListRiskProfileEventsRow{role(RoleOwner), role(RoleEditor)}, want: false},
+		{name: "shared with a stranger's event", profile: custom, events: []db.ListRiskProfileEventsRow{role(RoleOwner), role("")}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canSaveInPlace(tt.profile, tt.events); got != tt.want {
+				t.Errorf("canSaveInPlace = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisibleRiskProfile(t *testing.T) {
+	profiles := []db.ListRiskProfilesForPersonRow{
+		{ID: uuid.New(), Name: "Default", Builtin: true},
+		{ID: uuid.New(), Name: "Conference"},
+	}
+	if p, ok := visibleRiskProfile(profiles, profiles[1].ID.String()); !ok || p.Name != "Conference" {
+		t.Errorf("visible profile = %+v, %v; want Conference", p, ok)
+	}
+	for _, raw := range []string{uuid.NewString(), "default", ""} {
+		if p, ok := visibleRiskProfile(profiles, raw); ok {
+			t.Errorf("visibleRiskProfile(%q) = %+v, want not found", raw, p)
+		}
+	}
+}
@@ -1,31 +1,85 @@
 package server
 
+import "github.com/go-chi/chi/v5"
+
 func (s *Server) routes() {
-	// 1. Dashboard
-	s.Router.Get("/", s.handleDashboard)
+	s.Router.Use(s.loadCurrentUser)
+
+	// 0. Authentication (public)
+	s.Router.Get("/login", s.handleLogin)
+	s.Router.Post("/login", s.handleLogin)
+	s.Router.Get("/signup", s.handleSignup)
+	s.Router.Post("/signup", s.handleSignup)
+	s.Router.Post("/logout", s.handleLogout)
+
+	// 0b. JSON API (bearer token or session; see api.go)
+	s.Router.Get("/api/openapi.json", s.handleOpenAPI)
+	s.Router.Route("/api/v1", s.apiRoutes)
+
+	s.Router.Group(func(r chi.Router) {
+		r.Use(s.requireLogin)
+
+		// 1. Dashboard
+		r.Get("/", s.handleDashboard)
+		r.Get("/pulse", s.handlePulse)
+		r.Get("/search", s.handleSearch)
+		r.Get("/my-tasks", s.handleMyTasks)
+		r.Get("/notifications", s.handleNotifications)
+
+		// 1b. Settings
+		r.Get("/settings/notifications", s.handleNotificationSettings)
+		r.Post("/settings/notifications", s.handleUpdateNotificationSettings)
+		r.Post("/settings/notifications/test", s.handleTestNotification)
+		r.Get("/settings/tokens", s.handleAPITokens)
+		r.Post("/settings/tokens", s.handleCreateAPIToken)
+		r.Post("/settings/tokens/{id}/revoke", s.handleRevokeAPIToken)
+
+		// 2. Event Management
+		r.Get("/events/new", s.handleCreateEvent)
+		r.Post("/events/new", s.handleCreateEvent)
+		r.Get("/events/{id}", s.handleEventDetail)
+		r.Get("/events/{id}/edit", s.handleEditEvent)
+		r.Post("/events/{id}/update", s.handleUpdateEvent)
+		r.Get("/events/{id}/schedule", s.handleEventSchedule)
+		r.Get("/events/{id}/risk", s.handleEventRisk)
+		r.Post("/events/{id}/risk", s.handleUpdateRiskProfile)
+		r.Get("/events/{id}/risk/history", s.handleEventRiskHistory)
+		r.Get("/events/{id}/briefings", s.handleEventBriefings)
+		r.Post("/events/{id}/briefings", s.handleGenerateBriefing)
+
+		// 2b. Event Members (RBAC)
+		r.Get("/events/{id}/members", s.handleEventMembers)
+		r.Post("/events/{id}/members", s.handleAddMember)
+		r.Post("/events/{id}/members/{personID}/role", s.handleUpdateMemberRole)
+		r.Post("/events/{id}/members/{personID}/remove", s.handleRemoveMember)
+
+		// 3. Task Creation
+		r.Get("/tasks/new", s.handleCreateTask)
+		r.Post("/tasks/new", s.handleCreateTask)
 
-	// 2. Event Management
-	s.Router.Get("/events/new", s.handleCreateEvent)
-	s.Router.Post("/events/new", s.handleCreateEvent)
-	s.Router.Get("/events/{id}", s.handleEventDetail)
-	s.Router.Get("/events/{id}/edit", s.handleEditEvent)
-	s.Router.Post("/events/{id}/update", s.handleUpdateEvent)
+		// 4. Task Editing & Updates
+		r.Get("/tasks/{id}/edit", s.handleEditTask)
+		r.Post("/tasks/{id}/update", s.handleUpdateTask)
+		r.Post("/tasks/{id}/delete", s.handleDeleteTask)
+		r.Post("/tasks/{id}/subtasks/{index}/toggle", s.handleToggleSubtask)
 
-	// 3. Task Creation
-	s.Router.Get("/tasks/new", s.handleCreateTask)
-	s.Router.Post("/tasks/new", s.handleCreateTask)
+		// 4b. Dependencies
+		r.Post("/tasks/{id}/dependencies", s.handleAddDependency)
+		r.Post("/tasks/{id}/dependencies/{depID}/remove", s.handleRemoveDependency)
 
-	// 4. Task Editing & Updates
-	s.Router.Get("/tasks/{id}/edit", s.handleEditTask)
-	s.Router.Post("/tasks/{id}/update", s.handleUpdateTask)
-	s.Router.Post("/tasks/{id}/delete", s.handleDeleteTask)
+		// 5. Batch Operations
+		r.Post("/tasks/batch-delete", s.handleBatchDelete)
 
-	// 5. Batch Operations
-	s.Router.Post("/tasks/batch-delete", s.handleBatchDelete)
+		// 5b. Trash
+		r.Get("/events/{id}/trash", s.handleEventTrash)
+		r.Post("/events/{id}/trash/restore", s.handleBatchRestore)
+		r.Post("/tasks/{id}/restore", s.handleRestoreTask)
 
-	// 6. History
-	s.Router.Get("/tasks/{id}/events", s.handleTaskEvents)
+		// 6. History
+		r.Get("/tasks/{id}/events", s.handleTaskEvents)
+		r.Get("/tasks/{id}/at", s.handleTaskAt)
 
-	// Legacy redirect
-	s.Router.Get("/tasks", s.handleDashboard)
+		// Legacy redirect
+		r.Get("/tasks", s.handleDashboard)
+	})
 }
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// SCHEDULE (GET)
+func (s *Server) handleEventSchedule(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleViewer); !ok {
+		return
+	}
+
+	event, err := s.Q.GetEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	tasks, err := s.Q.GetEventTasks(r.Context(), db.GetEventTasksParams{EventID: eventID, Column2: false})
+	if err != nil {
+		http.Error(w, "Failed to fetch tasks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	deps, err := s.Q.ListEventDependencies(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch dependencies: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	schedule := logic.BuildSchedule(tasks, deps, event.EventDate, time.Now())
+	if wantsJSON(r) {
+		writeJSON(w, http.StatusOK, schedule)
+		return
+	}
+
+	data := struct {
+		Event    db.Event
+		Schedule logic.Schedule
+	}{
+		Event:    event,
+		Schedule: schedule,
+	}
+	s.render(w, r, "schedule.html", data)
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"context"
+	"html"
+	"html/template"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// searchLimit caps results; search is for finding a task, not browsing.
+const searchLimit = 50
+
+// maxSearchQuery keeps websearch_to_tsquery input sane.
+const maxSearchQuery = 200
+
+// SearchTasks wraps matches in these (chr(2)/chr(3)) rather than HTML, so the
+// rest of the snippet can be escaped before the marks go in.
+const (
+	searchMarkStart = "\x02"
+	searchMarkStop  = "\x03"
+)
+
+// highlight escapes a ts_headline snippet and turns its marks into <mark>.
+func highlight(snippet string) template.HTML {
+	out := html.EscapeString(snippet)
+	out = strings.ReplaceAll(out, searchMarkStart, "<mark>")
+	out = strings.ReplaceAll(out, searchMarkStop, "</mark>")
+	return template.HTML(out)
+}
+
+// searchHit is a SearchTasks row with its snippets ready to render.
+type searchHit struct {
+	db.SearchTasksRow
+	TitleHTML   template.HTML
+	SnippetHTML template.HTML
+}
+
+// searchTasks runs a ranked search over the caller's events. eventIDs narrows
+// it (empty = every event the person belongs to).
+func (s *Server) searchTasks(ctx context.Context, personID uuid.UUID, query string, eventIDs []uuid.UUID) ([]searchHit, error) {
+	if eventIDs == nil {
+		eventIDs = []uuid.UUID{}
+	}
+	rows, err := s.Q.SearchTasks(ctx, db.SearchTasksParams{
+		PersonID:  personID,
+		Query:     query,
+		EventIds:  eventIDs,
+		PageLimit: searchLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	hits := make([]searchHit, 0, len(rows))
+	for _, row := range rows {
+		hits = append(hits, searchHit{
+			SearchTasksRow: row,
+			TitleHTML:      highlight(row.TitleSnippet),
+			SnippetHTML:    highlight(row.BodySnippet),
+		})
+	}
+	return hits, nil
+}
+
+// SEARCH (GET)
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	eventFilter := r.URL.Query().Get("event")
+
+	events, err := s.Q.ListUserEvents(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var eventIDs []uuid.UUID
+	if eventFilter != "" {
+		id, err := uuid.Parse(eventFilter)
+		if err != nil {
+			http.Error(w, "Invalid event ID", http.StatusBadRequest)
+			return
+		}
+		eventIDs = []uuid.UUID{id}
+	}
+
+	var hits []searchHit
+	if query != "" {
+		if utf8.RuneCountInString(query) > maxSearchQuery {
+			http.Error(w, "Search is too long", http.StatusBadRequest)
+			return
+		}
+		hits, err = s.searchTasks(r.Context(), user.ID, query, eventIDs)
+		if err != nil {
+			http.Error(w, "Search failed: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	data := struct {
+		Query  string
+		Event  string
+		Events []db.ListUserEventsRow
+		Hits   []searchHit
+		Limit  int
+	}{
+		Query:  query,
+		Event:  eventFilter,
+		Events: events,
+		Hits:   hits,
+		Limit:  searchLimit,
+	}
+	s.render(w, r, "search.html", data)
+}
+
+type apiSearchHit struct {
+	TaskID    uuid.UUID `json:"task_id"`
+	EventID   uuid.UUID `json:"event_id"`
+	EventName string    `json:"event_name"`
+	Title     string    `json:"title"`
+	Status    string    `json:"status" enum:"backlog,in_progress,blocked,done"`
+	Rank      float32   `json:"rank"`
+	// The highlights are HTML-escaped, with matches wrapped in <mark>.
+	TitleHTML   string `json:"title_html"`
+	SnippetHTML string `json:"snippet_html"`
+}
+
+// API: SEARCH TASKS (GET)
+func (s *Server) handleAPISearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		apiInvalid(w, map[string]string{"q": "required"})
+		return
+	}
+	if utf8.RuneCountInString(query) > maxSearchQuery {
+		apiInvalid(w, map[string]string{"q": "too long"})
+		return
+	}
+
+	// A limited token searches only its events, like GET /events.
+	var eventIDs []uuid.UUID
+	if token, ok := tokenFromContext(r.Context()); ok {
+		eventIDs = token.EventIds
+	}
+	if raw := r.URL.Query().Get("event_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			apiInvalid(w, map[string]string{"event_id": "must be a UUID"})
+			return
+		}
+		if _, ok := s.apiAuthorizeEvent(w, r, id, RoleViewer); !ok {
+			return
+		}
+		eventIDs = []uuid.UUID{id}
+	}
+
+	user, _ := currentUser(r.Context())
+	hits, err := s.searchTasks(r.Context(), user.ID, query, eventIDs)
+	if err != nil {
+		apiFail(w, http.StatusInternalServerError, "Search failed: "+err.Error())
+		return
+	}
+	out := make([]apiSearchHit, 0, len(hits))
+	for _, h := range hits {
+		out = append(out, apiSearchHit{
+			TaskID:      h.ID,
+			EventID:     h.EventID,
+			EventName:   h.EventName,
+			Title:       h.Title,
+			Status:      h.Status,
+			Rank:        h.Rank,
+			TitleHTML:   string(h.TitleHTML),
+			SnippetHTML: string(h.SnippetHTML),
+		})
+	}
+	apiJSON(w, http.StatusOK, out)
+}
@@ -2,26 +2,91 @@ package server
 
 import (
 	"database/sql"
+	"encoding/json"
+	"html/template"
+	"net/http"
 
 	"github.com/alexedwards/scs/v2"
 	"github.com/go-chi/chi/v5"
+	"github.com/navyaalva/sbf-os/internal/ai"
 	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/notify"
 )
 
 type Server struct {
-	Router  *chi.Mux
-	DB      *sql.DB
-	Q       *db.Queries
-	Session *scs.SessionManager // <--- Added
+	Router   *chi.Mux
+	DB       *sql.DB
+	Q        *db.Queries
+	Session  *scs.SessionManager // <--- Added
+	Config   Config
+	Notifier *notify.Notifier
+	// AI is nil when no provider is configured; features fall back to
+	// their offline versions.
+	AI ai.LLMProvider
 }
 
-func NewServer(dbConn *sql.DB, session *scs.SessionManager) *Server {
+// Config holds deployment knobs read from the environment in main.
+type Config struct {
+	// TrashRetentionDays is how long soft-deleted tasks stay restorable.
+	TrashRetentionDays int
+	// Notify configures the email/webhook channels; unset channels are off.
+	Notify notify.Config
+	// AI is the language model provider built by ai.New, or nil for none.
+	AI ai.LLMProvider
+}
+
+func NewServer(dbConn *sql.DB, session *scs.SessionManager, cfg Config) *Server {
 	s := &Server{
 		Router:  chi.NewRouter(),
 		DB:      dbConn,
 		Q:       db.New(dbConn),
 		Session: session,
+		Config:  cfg,
+		AI:      cfg.AI,
 	}
+	s.Notifier = notify.New(s.Q, cfg.Notify)
 	s.routes()
 	return s
 }
+
+const sessionFlashKey = "flash"
+
+// setFlash queues a one-off message shown on the next rendered page.
+func (s *Server) setFlash(r *http.Request, msg string) {
+	s.Session.Put(r.Context(), sessionFlashKey, msg)
+}
+
+// render parses the base layout plus templates/<page> and executes "base".
+// Pages get `currentUser` and `flash` funcs so the layout can show who is
+// logged in and any pending message.
+func (s *Server) render(w http.ResponseWriter, r *http.Request, page string, data interface{}) {
+	user, loggedIn := currentUser(r.Context())
+	flashMsg := s.Session.PopString(r.Context(), sessionFlashKey)
+	funcs := template.FuncMap{
+		"flash": func() string { return flashMsg },
+		"currentUser": func() *db.Person {
+			if !loggedIn {
+				return nil
+			}
+			return &user
+		},
+	}
+
+	tmpl, err := template.New(page).Funcs(funcs).ParseFiles("templates/base.layout.html", "templates/"+page)
+	if err != nil {
+		http.Error(w, "Template parse error: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tmpl.ExecuteTemplate(w, "base", data)
+}
+
+// wantsJSON lets an HTML route double as a JSON endpoint via ?format=json.
+func wantsJSON(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "json"
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
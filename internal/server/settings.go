@@ -0,0 +1,149 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/notify"
+)
+
+// settingsPage is shared by the GET view and a POST that failed validation.
+type settingsPage struct {
+	Prefs      db.NotificationPref
+	Channels   []string
+	Deliveries []db.NotificationDelivery
+	Hours      []int32
+	Error      string
+}
+
+// NOTIFICATION SETTINGS (GET)
+func (s *Server) handleNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+	prefs, err := notify.Prefs(r.Context(), s.Q, user.ID)
+	if err != nil {
+		http.Error(w, "Failed to load settings: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.renderSettings(w, r, prefs, "")
+}
+
+// NOTIFICATION SETTINGS: SAVE (POST)
+func (s *Server) handleUpdateNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	user, _ := currentUser(r.Context())
+
+	prefs := db.NotificationPref{
+		PersonID:     user.ID,
+		EmailEnabled: r.FormValue("email_enabled") == "on",
+		Timezone:     strings.TrimSpace(r.FormValue("timezone")),
+	}
+	if prefs.Timezone == "" {
+		prefs.Timezone = "UTC"
+	}
+	if raw := strings.TrimSpace(r.FormValue("webhook_url")); raw != "" {
+		prefs.WebhookUrl = sql.NullString{String: raw, Valid: true}
+	}
+	if h, err := strconv.Atoi(r.FormValue("digest_hour")); err == nil {
+		prefs.DigestHour = sql.NullInt32{Int32: int32(h), Valid: true}
+	}
+	if ch := r.FormValue("digest_channel"); ch != "" {
+		prefs.DigestChannel = sql.NullString{String: ch, Valid: true}
+	}
+	start, startErr := strconv.Atoi(r.FormValue("quiet_start"))
+	end, endErr := strconv.Atoi(r.FormValue("quiet_end"))
+	if startErr == nil && endErr == nil {
+		prefs.QuietStart = sql.NullInt32{Int32: int32(start), Valid: true}
+		prefs.QuietEnd = sql.NullInt32{Int32: int32(end), Valid: true}
+	}
+
+	var formErr string
+	if _, err := time.LoadLocation(prefs.Timezone); err != nil {
+		formErr = "Unknown time zone " + prefs.Timezone
+	} else if err := s.checkWebhookURL(r.Context(), prefs.WebhookUrl); err != nil {
+		formErr = err.Error()
+	} else if (startErr == nil) != (endErr == nil) {
+		formErr = "Set both ends of the quiet hours, or neither"
+	} else if prefs.QuietStart.Valid && (start < 0 || start > 23 || end < 0 || end > 23) {
+		formErr = "Quiet hours must be between 0 and 23"
+	} else if prefs.DigestHour.Valid && (prefs.DigestHour.Int32 < 0 || prefs.DigestHour.Int32 > 23) {
+		formErr = "Digest hour must be between 0 and 23"
+	} else if ch := prefs.DigestChannel.String; ch != "" && ch != notify.ChannelEmail && ch != notify.ChannelWebhook {
+		formErr = "Unknown digest channel " + ch
+	}
+	if formErr != "" {
+		s.renderSettings(w, r, prefs, formErr)
+		return
+	}
+
+	if err := s.Q.UpsertNotificationPrefs(r.Context(), db.UpsertNotificationPrefsParams{
+		PersonID:      prefs.PersonID,
+		EmailEnabled:  prefs.EmailEnabled,
+		WebhookUrl:    prefs.WebhookUrl,
+		Timezone:      prefs.Timezone,
+		QuietStart:    prefs.QuietStart,
+		QuietEnd:      prefs.QuietEnd,
+		DigestHour:    prefs.DigestHour,
+		DigestChannel: prefs.DigestChannel,
+	}); err != nil {
+		http.Error(w, "Save failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	s.setFlash(r, "Notification settings saved.")
+	http.Redirect(w, r, "/settings/notifications", http.StatusSeeOther)
+}
+
+// NOTIFICATION SETTINGS: SEND TEST (POST)
+func (s *Server) handleTestNotification(w http.ResponseWriter, r *http.Request) {
+	user, _ := currentUser(r.Context())
+	key := "test:" + strconv.FormatInt(time.Now().UnixNano(), 10)
+	added, err := s.Notifier.Enqueue(r.Context(), user.ID, notify.TestMessage(user.Name), key)
+	if err != nil {
+		http.Error(w, "Failed to queue test: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if added == 0 {
+		s.setFlash(r, "Nothing to send: no channel is both switched on here and configured on the server.")
+	} else {
+		s.setFlash(r, "Test queued on "+strconv.Itoa(added)+" channel(s). It goes out within a minute, unless you're in quiet hours.")
+	}
+	http.Redirect(w, r, "/settings/notifications", http.StatusSeeOther)
+}
+
+// checkWebhookURL refuses URLs that resolve to internal addresses, since the
+// settings page lets anyone make the server POST to them.
+func (s *Server) checkWebhookURL(ctx context.Context, raw sql.NullString) error {
+	if !raw.Valid {
+		return nil
+	}
+	return notify.CheckWebhookURL(ctx, raw.String, s.Config.Notify.WebhookAllowPrivate)
+}
+
+func (s *Server) renderSettings(w http.ResponseWriter, r *http.Request, prefs db.NotificationPref, formErr string) {
+	deliveries, err := s.Q.ListPersonDeliveries(r.Context(), db.ListPersonDeliveriesParams{PersonID: prefs.PersonID, Limit: 20})
+	if err != nil {
+		http.Error(w, "Failed to load delivery log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hours := make([]int32, 24)
+	for i := range hours {
+		hours[i] = int32(i)
+	}
+	s.render(w, r, "notification_settings.html", settingsPage{
+		Prefs:      prefs,
+		Channels:   s.Notifier.Channels(),
+		Deliveries: deliveries,
+		Hours:      hours,
+		Error:      formErr,
+	})
+}
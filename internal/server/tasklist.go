@@ -0,0 +1,406 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+// Task list sort orders. Risk is computed in Go over a capped scan (see
+// riskScanLimit), the rest are keyset paginated in SQL; every order breaks
+// ties on task ID.
+const (
+	SortRisk     = "risk"
+	SortDue      = "due"
+	SortPriority = "priority"
+	SortUpdated  = "updated"
+)
+
+var taskSorts = []string{SortRisk, SortDue, SortPriority, SortUpdated}
+
+var riskLevels = []string{"high", "med", "low"}
+
+// openStatuses is every status but done, the default for task listings.
+var openStatuses = []string{"backlog", "in_progress", "blocked"}
+
+// Page sizes for task listings.
+const (
+	defaultTaskPage = 50
+	maxTaskPage     = 200
+)
+
+// riskScanLimit caps how many rows one request scores in Go. A risk sort
+// takes them soonest due first, where the risk is, and flags the page Partial
+// when the cap cut it short; a risk filter on another order just continues
+// from the last row scanned on the next page.
+const riskScanLimit = 500
+
+// taskQuery is a parsed task listing request, shared by Pulse, the event
+// page and the API. Zero values mean "no filter".
+type taskQuery struct {
+	Statuses   []string
+	Category   string
+	OwnerID    uuid.NullUUID
+	Unassigned bool
+	Tags       []string
+	DueFrom    sql.NullTime
+	DueTo      sql.NullTime
+	Risk       string
+	Text       string
+	Sort       string
+	Cursor     *taskCursor
+	Limit      int
+}
+
+// taskCursor is the last row of the previous page, in the page's sort order.
+type taskCursor struct {
+	Sort string
+	Key  string
+	ID   uuid.UUID
+}
+
+// listValues reads a multi-valued parameter that may also be comma-separated
+// (?status=backlog&status=blocked or ?status=backlog,blocked).
+func listValues(v url.Values, name string) []string {
+	var out []string
+	for _, raw := range v[name] {
+		for _, part := range strings.Split(raw, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+func oneOf(value string, options []string) bool {
+	for _, o := range options {
+		if o == value {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTaskQuery reads filters, sort, cursor and limit from a query string.
+// defaultStatuses and defaultSort apply when the request names none; nil
+// statuses means all of them. Problems are keyed by parameter name, ready
+// for apiInvalid.
+func parseTaskQuery(v url.Values, defaultStatuses []string, defaultSort string) (taskQuery, map[string]string) {
+	q := taskQuery{
+		Category: strings.TrimSpace(v.Get("category")),
+		Tags:     listValues(v, "tag"),
+		Risk:     v.Get("risk"),
+		Text:     strings.TrimSpace(v.Get("q")),
+		Sort:     v.Get("sort"),
+		Limit:    defaultTaskPage,
+	}
+	problems := map[string]string{}
+
+	q.Statuses = listValues(v, "status")
+	for _, st := range q.Statuses {
+		if !validStatus(st) {
+			problems["status"] = "must be one of " + strings.Join(taskStatuses, ", ")
+		}
+	}
+	if len(q.Statuses) == 0 {
+		q.Statuses = defaultStatuses
+	}
+
+	switch owner := v.Get("owner"); owner {
+	case "":
+	case "unassigned":
+		q.Unassigned = true
+	default:
+		id, err := uuid.Parse(owner)
+		if err != nil {
+			problems["owner"] = "must be a person ID or unassigned"
+		}
+		q.OwnerID = uuid.NullUUID{UUID: id, Valid: err == nil}
+	}
+
+	for _, name := range []string{"due_from", "due_to"} {
+		raw := v.Get(name)
+		if raw == "" {
+			continue
+		}
+		d, err := time.Parse(apiDate, raw)
+		if err != nil {
+			problems[name] = "must be a YYYY-MM-DD date"
+			continue
+		}
+		if name == "due_from" {
+			q.DueFrom = sql.NullTime{Time: d, Valid: true}
+		} else {
+			q.DueTo = sql.NullTime{Time: d, Valid: true}
+		}
+	}
+
+	if q.Risk != "" && !oneOf(q.Risk, riskLevels) {
+		problems["risk"] = "must be one of " + strings.Join(riskLevels, ", ")
+	}
+	if q.Sort == "" {
+		q.Sort = defaultSort
+	} else if !oneOf(q.Sort, taskSorts) {
+		problems["sort"] = "must be one of " + strings.Join(taskSorts, ", ")
+	}
+
+	if raw := v.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxTaskPage {
+			problems["limit"] = "must be between 1 and " + strconv.Itoa(maxTaskPage)
+		} else {
+			q.Limit = n
+		}
+	}
+
+	if raw := v.Get("cursor"); raw != "" {
+		c, ok := decodeTaskCursor(raw)
+		if !ok || c.Sort != q.Sort {
+			problems["cursor"] = "invalid, or from a different sort order"
+		} else {
+			q.Cursor = &c
+		}
+	}
+	return q, problems
+}
+
+// encode makes the cursor opaque to clients; it isn't signed, since it only
+// narrows a query the caller could already run.
+func (c taskCursor) encode() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.Sort + "|" + c.Key + "|" + c.ID.String()))
+}
+
+func decodeTaskCursor(raw string) (taskCursor, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return taskCursor{}, false
+	}
+	parts := strings.Split(string(b), "|")
+	if len(parts) != 3 || !oneOf(parts[0], taskSorts) {
+		return taskCursor{}, false
+	}
+	id, err := uuid.Parse(parts[2])
+	if err != nil {
+		return taskCursor{}, false
+	}
+	c := taskCursor{Sort: parts[0], Key: parts[1], ID: id}
+	if _, ok := c.params(); !ok {
+		return taskCursor{}, false
+	}
+	return c, true
+}
+
+// params turns the cursor into the ListTasks keyset arguments. Risk cursors
+// have none: risk is paged in Go.
+func (c taskCursor) params() (db.ListTasksParams, bool) {
+	p := db.ListTasksParams{AfterID: uuid.NullUUID{UUID: c.ID, Valid: true}}
+	switch c.Sort {
+	case SortDue:
+		d, err := time.Parse(apiDate, c.Key)
+		p.AfterDue = sql.NullTime{Time: d, Valid: true}
+		return p, err == nil
+	case SortPriority:
+		n, err := strconv.Atoi(c.Key)
+		p.AfterPriority = sql.NullInt32{Int32: int32(n), Valid: true}
+		return p, err == nil
+	case SortUpdated:
+		t, err := time.Parse(time.RFC3339Nano, c.Key)
+		p.AfterUpdated = sql.NullTime{Time: t, Valid: true}
+		return p, err == nil
+	case SortRisk:
+		_, err := strconv.Atoi(c.Key)
+		return db.ListTasksParams{}, err == nil
+	}
+	return p, false
+}
+
+// cursorFor is the cursor that resumes after st.
+func cursorFor(sortBy string, st logic.ScoredTask) taskCursor {
+	t := st.Task.(db.ListTasksRow)
+	c := taskCursor{Sort: sortBy, ID: t.ID}
+	switch sortBy {
+	case SortDue:
+		c.Key = "9999-12-31"
+		if t.DueDate.Valid {
+			c.Key = t.DueDate.Time.Format(apiDate)
+		}
+	case SortPriority:
+		c.Key = strconv.Itoa(int(t.Priority))
+	case SortUpdated:
+		touched := t.CreatedAt
+		if t.LastUpdateAt.Valid {
+			touched = t.LastUpdateAt.Time
+		}
+		c.Key = touched.Format(time.RFC3339Nano)
+	case SortRisk:
+		c.Key = strconv.Itoa(st.Score)
+	}
+	return c
+}
+
+// taskPage is one page of a task listing.
+type taskPage struct {
+	Tasks     []logic.ScoredTask
+	BlockedBy map[uuid.UUID][]string
+	// NextCursor is empty on the last page.
+	NextCursor string
+	// Partial is set when a risk sort hit riskScanLimit, so only the
+	// soonest-due matches were ranked.
+	Partial bool
+}
+
+// listTasks runs a task listing for a person, optionally within one event.
+// Every row is scored against its event's whole dependency graph and risk
+// profile, so a task scores the same on every page and on the event page.
+//
+// Sorting by risk or filtering on a risk level can't be pushed into SQL, so
+// those score up to riskScanLimit rows in Go; the other orders fetch one page
+// (plus one row, to know whether there's another) by keyset.
+func (s *Server) listTasks(ctx context.Context, personID uuid.UUID, eventID uuid.NullUUID, q taskQuery) (taskPage, error) {
+	params := db.ListTasksParams{}
+	if q.Cursor != nil && q.Sort != SortRisk {
+		params, _ = q.Cursor.params()
+	}
+	params.PersonID = personID
+	params.EventID = eventID
+	params.Statuses = q.Statuses
+	params.Category = sql.NullString{String: q.Category, Valid: q.Category != ""}
+	params.OwnerID = q.OwnerID
+	params.Unassigned = q.Unassigned
+	params.Tags = q.Tags
+	params.DueFrom = q.DueFrom
+	params.DueTo = q.DueTo
+	params.Query = q.Text
+	params.Sort = q.Sort
+	if params.Statuses == nil {
+		params.Statuses = []string{}
+	}
+	if params.Tags == nil {
+		params.Tags = []string{}
+	}
+	inGo := q.Sort == SortRisk || q.Risk != ""
+	if q.Sort == SortRisk {
+		params.Sort = SortDue
+	}
+	if inGo {
+		params.PageLimit = sql.NullInt32{Int32: riskScanLimit + 1, Valid: true}
+	} else {
+		params.PageLimit = sql.NullInt32{Int32: int32(q.Limit + 1), Valid: true}
+	}
+
+	rows, err := s.Q.ListTasks(ctx, params)
+	if err != nil {
+		return taskPage{}, err
+	}
+
+	page := taskPage{BlockedBy: map[uuid.UUID][]string{}}
+	capped := inGo && len(rows) > riskScanLimit
+	if capped {
+		rows = rows[:riskScanLimit]
+		page.Partial = q.Sort == SortRisk
+	}
+	signals := map[uuid.UUID]logic.DepSignals{}
+	profiles := map[uuid.UUID]logic.RiskProfile{}
+	for _, t := range rows {
+		if _, done := profiles[t.EventID]; done {
+			continue
+		}
+		open, err := s.Q.GetEventTasks(ctx, db.GetEventTasksParams{EventID: t.EventID})
+		if err != nil {
+			return taskPage{}, err
+		}
+		deps, err := s.Q.ListEventDependencies(ctx, t.EventID)
+		if err != nil {
+			return taskPage{}, err
+		}
+		profile, _, err := logic.LoadRiskProfile(ctx, s.Q, t.EventID)
+		if err != nil {
+			return taskPage{}, err
+		}
+		profiles[t.EventID] = profile
+		for id, sig := range logic.DependencySignals(open, deps, t.EventDate, time.Now()) {
+			signals[id] = sig
+		}
+		for id, titles := range logic.BlockedBy(deps) {
+			page.BlockedBy[id] = titles
+		}
+	}
+
+	scored := make([]logic.ScoredTask, 0, len(rows))
+	for _, t := range rows {
+		st := logic.ScoreListedTask(t, signals[t.ID], profiles[t.EventID])
+		if q.Risk != "" && st.RiskLevel != q.Risk {
+			continue
+		}
+		scored = append(scored, st)
+	}
+
+	if q.Sort == SortRisk {
+		sort.SliceStable(scored, func(i, j int) bool {
+			if scored[i].Score != scored[j].Score {
+				return scored[i].Score > scored[j].Score
+			}
+			return riskRowBefore(scored[i], scored[j])
+		})
+		if q.Cursor != nil {
+			score, _ := strconv.Atoi(q.Cursor.Key)
+			start := sort.Search(len(scored), func(i int) bool {
+				st := scored[i]
+				if st.Score != score {
+					return st.Score < score
+				}
+				id := st.Task.(db.ListTasksRow).ID
+				return bytes.Compare(id[:], q.Cursor.ID[:]) > 0
+			})
+			scored = scored[start:]
+		}
+	}
+
+	if len(scored) > q.Limit {
+		scored = scored[:q.Limit]
+		page.NextCursor = cursorFor(q.Sort, scored[q.Limit-1]).encode()
+	} else if capped && q.Sort != SortRisk {
+		// The filter left less than a page, but there are rows left to scan.
+		page.NextCursor = cursorFor(q.Sort, logic.ScoredTask{Task: rows[len(rows)-1]}).encode()
+	}
+	page.Tasks = scored
+	return page, nil
+}
+
+// riskRowBefore orders equal scores by task ID, matching the SQL tie-break.
+func riskRowBefore(a, b logic.ScoredTask) bool {
+	ida, idb := a.Task.(db.ListTasksRow).ID, b.Task.(db.ListTasksRow).ID
+	return bytes.Compare(ida[:], idb[:]) < 0
+}
+
+// nextPageURL is the current listing URL moved on to the next page.
+func nextPageURL(u *url.URL, cursor string) string {
+	if cursor == "" {
+		return ""
+	}
+	v := u.Query()
+	v.Set("cursor", cursor)
+	return u.Path + "?" + v.Encode()
+}
+
+// problemText flattens parse problems for the HTML pages.
+func problemText(problems map[string]string) string {
+	var parts []string
+	for name, msg := range problems {
+		parts = append(parts, name+" "+msg)
+	}
+	sort.Strings(parts)
+	return "Invalid filter: " + strings.Join(parts, "; ")
+}
@@ -0,0 +1,212 @@
+package server
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"net/url"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+func TestParseTaskQuery(t *testing.T) {
+	otherSort := taskCursor{Sort: SortPriority, Key: "3", ID: uuid.New()}.encode()
+	tests := []struct {
+		name         string
+		query        string
+		wantProblems []string
+		check        func(t *testing.T, q taskQuery)
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			check: func(t *testing.T, q taskQuery) {
+				if q.Sort != SortDue || q.Limit != defaultTaskPage || len(q.Statuses) != len(openStatuses) || q.Cursor != nil {
+					t.Errorf("got %+v, want the defaults", q)
+				}
+			},
+		},
+		{
+			name:  "lists, owner and dates",
+			query: "status=backlog,blocked&status=done&tag=food&owner=unassigned&due_from=2026-04-01&due_to=2026-04-30&limit=200",
+			check: func(t *testing.T, q taskQuery) {
+				if len(q.Statuses) != 3 || len(q.Tags) != 1 || !q.Unassigned || q.Limit != maxTaskPage {
+					t.Errorf("got %+v", q)
+				}
+				if !q.DueFrom.Valid || q.DueFrom.Time.Day() != 1 || !q.DueTo.Valid || q.DueTo.Time.Day() != 30 {
+					t.Errorf("due range = %v..%v", q.DueFrom, q.DueTo)
+				}
+			},
+		},
+		{name: "unknown status", query: "status=archived", wantProblems: []string{"status"}},
+		{name: "bad owner", query: "owner=sam", wantProblems: []string{"owner"}},
+		{name: "bad dates", query: "due_from=04/01/2026&due_to=2026-02-30", wantProblems: []string{"due_from", "due_to"}},
+		{name: "unknown risk and sort", query: "risk=extreme&sort=alpha", wantProblems: []string{"risk", "sort"}},
+		{name: "limit zero", query: "limit=0", wantProblems: []string{"limit"}},
+		{name: "limit too big", query: "limit=201", wantProblems: []string{"limit"}},
+		{name: "limit not a number", query: "limit=ten", wantProblems: []string{"limit"}},
+		{name: "garbage cursor", query: "cursor=!!!", wantProblems: []string{"cursor"}},
+		{name: "cursor from another sort", query: "sort=due&cursor=" + otherSort, wantProblems: []string{"cursor"}},
+		{
+			name:  "cursor for this sort",
+			query: "sort=priority&cursor=" + otherSort,
+			check: func(t *testing.T, q taskQuery) {
+				if q.Cursor == nil || q.Cursor.Key != "3" {
+					t.Errorf("cursor = %+v, want the priority cursor", q.Cursor)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q, problems := parseTaskQuery(v, openStatuses, SortDue)
+			var got []string
+			for name := range problems {
+				got = append(got, name)
+			}
+			sort.Strings(got)
+			if len(got) != len(tt.wantProblems) {
+				t.Fatalf("problems = %v, want %v", problems, tt.wantProblems)
+			}
+			for i := range got {
+				if got[i] != tt.wantProblems[i] {
+					t.Fatalf("problems = %v, want %v", problems, tt.wantProblems)
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, q)
+			}
+		})
+	}
+}
+
+func TestTaskCursorRoundTrip(t *testing.T) {
+	row := db.ListTasksRow{
+		ID:           uuid.New(),
+		Priority:     4,
+		DueDate:      sql.NullTime{Time: time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC), Valid: true},
+		CreatedAt:    time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC),
+		LastUpdateAt: sql.NullTime{Time: time.Date(2026, 3, 2, 10, 30, 0, 123456000, time.UTC), Valid: true},
+	}
+	st := logic.ScoredTask{Task: row, Score: 42}
+
+	tests := []struct {
+		sort    string
+		wantKey string
+		check   func(t *testing.T, p db.ListTasksParams)
+	}{
+		{
+			sort:    SortDue,
+			wantKey: "2026-04-11",
+			check: func(t *testing.T, p db.ListTasksParams) {
+				if !p.AfterDue.Valid || !p.AfterDue.Time.Equal(row.DueDate.Time) {
+					t.Errorf("AfterDue = %v", p.AfterDue)
+				}
+			},
+		},
+		{
+			sort:    SortPriority,
+			wantKey: "4",
+			check: func(t *testing.T, p db.ListTasksParams) {
+				if !p.AfterPriority.Valid || p.AfterPriority.Int32 != 4 {
+					t.Errorf("AfterPriority = %v", p.AfterPriority)
+				}
+			},
+		},
+		{
+			sort:    SortUpdated,
+			wantKey: "2026-03-02T10:30:00.123456Z",
+			check: func(t *testing.T, p db.ListTasksParams) {
+				if !p.AfterUpdated.Valid || !p.AfterUpdated.Time.Equal(row.LastUpdateAt.Time) {
+					t.Errorf("AfterUpdated = %v", p.AfterUpdated)
+				}
+			},
+		},
+		{
+			// Risk pages in Go, so there are no keyset arguments.
+			sort:    SortRisk,
+			wantKey: "42",
+			check: func(t *testing.T, p db.ListTasksParams) {
+				if p.AfterID.Valid {
+					t.Errorf("risk cursor set SQL keyset %+v", p)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			c := cursorFor(tt.sort, st)
+			if c.Key != tt.wantKey {
+				t.Errorf("key = %q, want %q", c.Key, tt.wantKey)
+			}
+			got, ok := decodeTaskCursor(c.encode())
+			if !ok || got != c {
+				t.Fatalf("decode(encode(%+v)) = %+v, %v", c, got, ok)
+			}
+			p, ok := got.params()
+			if !ok {
+				t.Fatalf("params() not ok for %+v", got)
+			}
+			if tt.sort != SortRisk && p.AfterID.UUID != row.ID {
+				t.Errorf("AfterID = %v, want %v", p.AfterID, row.ID)
+			}
+			tt.check(t, p)
+		})
+	}
+
+	// A task with no due date sorts last, after every real date.
+	undated := row
+	undated.DueDate = sql.NullTime{}
+	if c := cursorFor(SortDue, logic.ScoredTask{Task: undated}); c.Key != "9999-12-31" {
+		t.Errorf("undated due key = %q", c.Key)
+	}
+	// Never updated falls back to the creation time.
+	fresh := row
+	fresh.LastUpdateAt = sql.NullTime{}
+	if c := cursorFor(SortUpdated, logic.ScoredTask{Task: fresh}); c.Key != "2026-03-01T09:00:00Z" {
+		t.Errorf("never-updated key = %q", c.Key)
+	}
+}
+
+func TestDecodeTaskCursorRejects(t *testing.T) {
+	id := uuid.New().String()
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := map[string]string{
+		"not base64":       "%%%",
+		"tampered sort":    raw("risk|2026-04-11|" + id),
+		"too few parts":    raw("due|2026-04-11"),
+		"too many parts":   raw("due|2026-04-11|" + id + "|x"),
+		"unknown sort":     raw("alpha|b|" + id),
+		"bad id":           raw("due|2026-04-11|not-a-uuid"),
+		"bad due key":      raw("due|tomorrow|" + id),
+		"bad priority key": raw("priority|high|" + id),
+		"bad updated key":  raw("updated|2026-03-02|" + id),
+		"bad risk key":     raw("risk|lots|" + id),
+	}
+	for name, cursor := range tests {
+		t.Run(name, func(t *testing.T) {
+			if c, ok := decodeTaskCursor(cursor); ok {
+				t.Errorf("decodeTaskCursor(%q) = %+v, want rejected", cursor, c)
+			}
+		})
+	}
+}
+
+func TestRiskRowBefore(t *testing.T) {
+	low := uuid.MustParse("00000000-0000-0000-0000-000000000001")
+	high := uuid.MustParse("ffffffff-0000-0000-0000-000000000000")
+	a := logic.ScoredTask{Task: db.ListTasksRow{ID: low}, Score: 10}
+	b := logic.ScoredTask{Task: db.ListTasksRow{ID: high}, Score: 10}
+	if !riskRowBefore(a, b) || riskRowBefore(b, a) || riskRowBefore(a, a) {
+		t.Error("equal scores should order by ascending task ID")
+	}
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+// Token scopes, matching the CHECK constraint on api_tokens.scope. Read
+// tokens may only make GET requests; neither scope grants more than the
+// owner's role on an event.
+const (
+	TokenScopeRead      = "read"
+	TokenScopeReadWrite = "read_write"
+)
+
+// tokenExpiries are the lifetimes, in days, offered when minting a personal
+// token. 0 means it never expires.
+var tokenExpiries = []int{30, 90, 365, 0}
+
+// maxTokenName keeps token names readable in the settings table.
+const maxTokenName = 80
+
+// tokenFromContext returns the API token the request authenticated with.
+func tokenFromContext(ctx context.Context) (db.ApiToken, bool) {
+	t, ok := ctx.Value(apiTokenContextKey).(db.ApiToken)
+	return t, ok
+}
+
+// tokenAllowsEvent reports whether a token may touch an event. Tokens with
+// no event list cover every event their owner belongs to.
+func tokenAllowsEvent(t db.ApiToken, eventID uuid.UUID) bool {
+	if len(t.EventIds) == 0 {
+		return true
+	}
+	for _, id := range t.EventIds {
+		if id == eventID {
+			return true
+		}
+	}
+	return false
+}
+
+// mintAPIToken stores a new token (params.TokenHash is filled in) and
+// returns its plaintext, the only time it's available.
+func (s *Server) mintAPIToken(ctx context.Context, params db.CreateApiTokenParams) (string, db.ApiToken, error) {
+	token, hash, err := newAPIToken()
+	if err != nil {
+		return "", db.ApiToken{}, err
+	}
+	params.TokenHash = hash
+	if params.EventIds == nil {
+		params.EventIds = []uuid.UUID{}
+	}
+	row, err := s.Q.CreateApiToken(ctx, params)
+	return token, row, err
+}
+
+// tokenView is a token with the names of the events it's limited to.
+type tokenView struct {
+	db.ApiToken
+	EventNames []string
+}
+
+// tokensPage is shared by the GET view, a failed mint and a successful one.
+type tokensPage struct {
+	Tokens   []tokenView
+	Events   []db.ListUserEventsRow
+	Expiries []int
+	// NewToken is the plaintext of a token minted by this request.
+	NewToken string
+	NewName  string
+	Error    string
+}
+
+// API TOKENS (GET)
+func (s *Server) handleAPITokens(w http.ResponseWriter, r *http.Request) {
+	s.renderTokens(w, r, tokensPage{})
+}
+
+// API TOKENS: CREATE (POST)
+func (s *Server) handleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	user, _ := currentUser(r.Context())
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	scope := r.FormValue("scope")
+	days, daysErr := strconv.Atoi(r.FormValue("expires_days"))
+
+	events, err := s.Q.ListUserEvents(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	mine := make(map[uuid.UUID]bool, len(events))
+	for _, e := range events {
+		mine[e.ID] = true
+	}
+
+	var formErr string
+	var eventIDs []uuid.UUID
+	for _, raw := range r.Form["event_ids"] {
+		id, err := uuid.Parse(raw)
+		if err != nil || !mine[id] {
+			formErr = "You can only limit a token to events you belong to"
+			break
+		}
+		eventIDs = append(eventIDs, id)
+	}
+	validDays := false
+	for _, d := range tokenExpiries {
+		validDays = validDays || (daysErr == nil && d == days)
+	}
+
+	if name == "" {
+		formErr = "Give the token a name, so you know what to revoke later"
+	} else if utf8.RuneCountInString(name) > maxTokenName {
+		formErr = "Token names are limited to " + strconv.Itoa(maxTokenName) + " characters"
+	} else if scope != TokenScopeRead && scope != TokenScopeReadWrite {
+		formErr = "Unknown scope " + scope
+	} else if !validDays {
+		formErr = "Pick one of the offered expiry times"
+	}
+	if formErr != "" {
+		s.renderTokens(w, r, tokensPage{Error: formErr})
+		return
+	}
+
+	params := db.CreateApiTokenParams{
+		PersonID: user.ID,
+		Name:     name,
+		Scope:    scope,
+		EventIds: eventIDs,
+	}
+	if days > 0 {
+		params.ExpiresAt.Time, params.ExpiresAt.Valid = time.Now().AddDate(0, 0, days), true
+	}
+	token, _, err := s.mintAPIToken(r.Context(), params)
+	if err != nil {
+		http.Error(w, "Failed to create token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Rendered rather than redirected: the plaintext never touches the session.
+	s.renderTokens(w, r, tokensPage{NewToken: token, NewName: name})
+}
+
+// API TOKENS: REVOKE (POST)
+func (s *Server) handleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid token ID", http.StatusBadRequest)
+		return
+	}
+	user, _ := currentUser(r.Context())
+
+	n, err := s.Q.RevokeApiToken(r.Context(), db.RevokeApiTokenParams{ID: tokenID, PersonID: user.ID})
+	if err != nil {
+		http.Error(w, "Revoke failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		http.Error(w, "Token not found", http.StatusNotFound)
+		return
+	}
+
+	s.setFlash(r, "Token revoked. Scripts using it will get 401s from now on.")
+	http.Redirect(w, r, "/settings/tokens", http.StatusSeeOther)
+}
+
+func (s *Server) renderTokens(w http.ResponseWriter, r *http.Request, page tokensPage) {
+	user, _ := currentUser(r.Context())
+	tokens, err := s.Q.ListPersonApiTokens(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to load tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	events, err := s.Q.ListUserEvents(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, "Failed to fetch events: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := make(map[uuid.UUID]string, len(events))
+	for _, e := range events {
+		names[e.ID] = e.Name
+	}
+	for _, t := range tokens {
+		v := tokenView{ApiToken: t}
+		for _, id := range t.EventIds {
+			if name, ok := names[id]; ok {
+				v.EventNames = append(v.EventNames, name)
+			} else {
+				v.EventNames = append(v.EventNames, "an event you've left")
+			}
+		}
+		page.Tokens = append(page.Tokens, v)
+	}
+	page.Events = events
+	page.Expiries = tokenExpiries
+	s.render(w, r, "api_tokens.html", page)
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+)
+
+func TestTokenAllowsEvent(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	tests := []struct {
+		name   string
+		events []uuid.UUID
+		want   map[uuid.UUID]bool
+	}{
+		{name: "unlimited", events: nil, want: map[uuid.UUID]bool{a: true, b: true}},
+		{name: "empty list is unlimited", events: []uuid.UUID{}, want: map[uuid.UUID]bool{a: true, b: true}},
+		{name: "limited to A", events: []uuid.UUID{a}, want: map[uuid.UUID]bool{a: true, b: false}},
+		{name: "limited to both", events: []uuid.UUID{b, a}, want: map[uuid.UUID]bool{a: true, b: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := db.ApiToken{EventIds: tt.events}
+			for id, want := range tt.want {
+				if got := tokenAllowsEvent(token, id); got != want {
+					t.Errorf("tokenAllowsEvent(%v) = %v, want %v", id, got, want)
+				}
+			}
+		})
+	}
+}
+
+// A token limited to other events is refused before membership is looked
+// up, so this needs no database.
+func TestEventAccessLimitedToken(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	ctx := context.WithValue(context.Background(), userContextKey, db.Person{ID: uuid.New()})
+	ctx = context.WithValue(ctx, apiTokenContextKey, db.ApiToken{Scope: TokenScopeReadWrite, EventIds: []uuid.UUID{a}})
+
+	_, status, err := (&Server{}).eventAccess(ctx, b, RoleViewer)
+	if status != http.StatusForbidden || err == nil {
+		t.Errorf("eventAccess(B) = %d, %v; want 403", status, err)
+	}
+}
+
+// TestAPIEventLimitedToken needs a migrated Postgres database in
+// TEST_DATABASE_URL, like TestAPIMatchesOpenAPI.
+func TestAPIEventLimitedToken(t *testing.T) {
+	c, s := newAPIContract(t)
+	ctx := context.Background()
+	suffix := uuid.NewString()[:8]
+	owner := contractPerson(t, s, "Limited Owner "+suffix, "limited-"+suffix+"@example.com", "contract-test")
+
+	issued := c.call("issueToken", "/auth/token", apiTokenRequest{Email: owner.Email.String, Password: "contract-test"}, http.StatusCreated)
+	c.token, _ = issued["token"].(string)
+	eventA, _ := c.call("createEvent", "/events", apiEventCreate{Name: "Limited A " + suffix, EventDate: "2030-06-01"}, http.StatusCreated)["id"].(string)
+	eventB, _ := c.call("createEvent", "/events", apiEventCreate{Name: "Limited B " + suffix, EventDate: "2030-07-01"}, http.StatusCreated)["id"].(string)
+	for _, id := range []string{eventA, eventB} {
+		c.call("createTask", "/events/"+id+"/tasks", apiTaskCreate{Title: "Book the stage " + suffix}, http.StatusCreated)
+	}
+	taskB, _ := c.call("listTasks", "/events/"+eventB+"/tasks", nil, http.StatusOK)["id"].(string)
+
+	limited, _, err := s.mintAPIToken(ctx, db.CreateApiTokenParams{
+		PersonID: owner.ID,
+		Name:     "Only A",
+		Scope:    TokenScopeReadWrite,
+		EventIds: []uuid.UUID{uuid.MustParse(eventA)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.token = limited
+
+	c.call("getEvent", "/events/"+eventA, nil, http.StatusOK)
+	c.call("getEvent", "/events/"+eventB, nil, http.StatusForbidden)
+	c.call("listTasks", "/events/"+eventB+"/tasks", nil, http.StatusForbidden)
+	c.call("getTask", "/tasks/"+taskB, nil, http.StatusForbidden)
+	c.call("createTask", "/events/"+eventB+"/tasks", apiTaskCreate{Title: "Sneak in"}, http.StatusForbidden)
+	c.call("createEvent", "/events", apiEventCreate{Name: "Limited C " + suffix, EventDate: "2030-08-01"}, http.StatusForbidden)
+	c.call("searchTasks", "/search?q=stage&event_id="+eventB, nil, http.StatusForbidden)
+
+	for _, e := range c.list("listEvents", "/events") {
+		if e["id"] != eventA {
+			t.Errorf("GET /events listed %v, want only event A", e["id"])
+		}
+	}
+	hits := c.list("searchTasks", "/search?q=stage+"+suffix)
+	if len(hits) != 1 || hits[0]["event_id"] != eventA {
+		t.Errorf("search hits = %v, want only event A's task", hits)
+	}
+
+	c.call("revokeToken", "/auth/token", nil, http.StatusNoContent)
+	c.call("getEvent", "/events/"+eventA, nil, http.StatusUnauthorized)
+	c.call("listEvents", "/events", nil, http.StatusUnauthorized)
+}
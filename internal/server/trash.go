@@ -0,0 +1,162 @@
+package server
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/navyaalva/sbf-os/internal/db"
+	"github.com/navyaalva/sbf-os/internal/logic"
+)
+
+type TrashedTask struct {
+	db.ListDeletedEventTasksRow
+	DeletedBy string
+	PurgeOn   time.Time
+}
+
+// TRASH (GET)
+func (s *Server) handleEventTrash(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	role, ok := s.authorizeEvent(w, r, eventID, RoleViewer)
+	if !ok {
+		return
+	}
+
+	event, err := s.Q.GetEvent(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Event not found", http.StatusNotFound)
+		return
+	}
+	rows, err := s.Q.ListDeletedEventTasks(r.Context(), eventID)
+	if err != nil {
+		http.Error(w, "Failed to fetch trash: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var trashed []TrashedTask
+	for _, t := range rows {
+		deletedBy := "System"
+		if t.DeletedByName.Valid {
+			deletedBy = t.DeletedByName.String
+		}
+		trashed = append(trashed, TrashedTask{
+			ListDeletedEventTasksRow: t,
+			DeletedBy:                deletedBy,
+			PurgeOn:                  t.DeletedAt.Time.AddDate(0, 0, s.Config.TrashRetentionDays),
+		})
+	}
+
+	data := struct {
+		Event         db.Event
+		Tasks         []TrashedTask
+		CanEdit       bool
+		RetentionDays int
+	}{
+		Event:         event,
+		Tasks:         trashed,
+		CanEdit:       roleAtLeast(role, RoleEditor),
+		RetentionDays: s.Config.TrashRetentionDays,
+	}
+	s.render(w, r, "trash.html", data)
+}
+
+// restoreTasks un-deletes tasks of one event and writes a RESTORED event for each.
+// IDs that aren't in the trash (or belong elsewhere) are skipped.
+func (s *Server) restoreTasks(r *http.Request, eventID uuid.UUID, ids []uuid.UUID) (int, error) {
+	restored := 0
+	err := s.Q.RunTx(r.Context(), s.DB, func(qtx *db.Queries) error {
+		for _, id := range ids {
+			before, err := qtx.GetTaskIncludingDeleted(r.Context(), id)
+			if errors.Is(err, sql.ErrNoRows) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			if before.EventID != eventID || !before.DeletedAt.Valid {
+				continue
+			}
+
+			after, err := qtx.RestoreTask(r.Context(), id)
+			if err != nil {
+				return err
+			}
+			if err := recordTaskEvent(r.Context(), qtx, id, logic.EventRestored, logic.CalculateChanges(before, after)); err != nil {
+				return err
+			}
+			restored++
+		}
+		return nil
+	})
+	return restored, err
+}
+
+// RESTORE ONE (POST)
+func (s *Server) handleRestoreTask(w http.ResponseWriter, r *http.Request) {
+	taskID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	task, err := s.Q.GetTaskIncludingDeleted(r.Context(), taskID)
+	if err != nil {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, task.EventID, RoleEditor); !ok {
+		return
+	}
+
+	n, err := s.restoreTasks(r, task.EventID, []uuid.UUID{taskID})
+	if err != nil {
+		http.Error(w, "Restore failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if n == 0 {
+		s.setFlash(r, "That task is not in the trash.")
+	} else {
+		s.setFlash(r, fmt.Sprintf("Restored “%s”.", task.Title))
+	}
+	http.Redirect(w, r, "/events/"+task.EventID.String()+"/trash", http.StatusSeeOther)
+}
+
+// RESTORE MANY (POST)
+func (s *Server) handleBatchRestore(w http.ResponseWriter, r *http.Request) {
+	eventID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, "Invalid event ID", http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.authorizeEvent(w, r, eventID, RoleEditor); !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	var ids []uuid.UUID
+	for _, idStr := range r.Form["task_ids"] {
+		if uid, err := uuid.Parse(idStr); err == nil {
+			ids = append(ids, uid)
+		}
+	}
+
+	n, err := s.restoreTasks(r, eventID, ids)
+	if err != nil {
+		http.Error(w, "Restore failed: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.setFlash(r, fmt.Sprintf("Restored %d task(s).", n))
+	http.Redirect(w, r, "/events/"+eventID.String()+"/trash", http.StatusSeeOther)
+}
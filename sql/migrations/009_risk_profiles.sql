@@ -0,0 +1,34 @@
+-- +goose Up
+-- Per-event risk scoring weights. Events without a row use the built-in defaults.
+CREATE TABLE risk_profiles (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL UNIQUE REFERENCES events(id) ON DELETE CASCADE,
+
+    -- Own signals
+    overdue_points INT NOT NULL DEFAULT 50,
+    due_soon_points INT NOT NULL DEFAULT 30,
+    due_soon_days INT NOT NULL DEFAULT 3,
+    stale_short_days INT NOT NULL DEFAULT 7,
+    stale_short_points INT NOT NULL DEFAULT 10,
+    stale_long_days INT NOT NULL DEFAULT 14,
+    stale_long_points INT NOT NULL DEFAULT 30,
+    blocked_points INT NOT NULL DEFAULT 25,
+    priority_weight INT NOT NULL DEFAULT 5,
+
+    -- Dependency signals
+    blocks_weight INT NOT NULL DEFAULT 2,
+    blocks_cap INT NOT NULL DEFAULT 40,
+    overdue_upstream_points INT NOT NULL DEFAULT 20,
+    critical_step_points INT NOT NULL DEFAULT 5,
+    behind_schedule_points INT NOT NULL DEFAULT 25,
+
+    -- Levels
+    high_threshold INT NOT NULL DEFAULT 50,
+    med_threshold INT NOT NULL DEFAULT 25,
+
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    CONSTRAINT risk_thresholds_ordered CHECK (high_threshold > med_threshold)
+);
+
+-- +goose Down
+DROP TABLE risk_profiles;
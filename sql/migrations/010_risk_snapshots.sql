@@ -0,0 +1,17 @@
+-- +goose Up
+-- One row per open task per day, written by the risk-snapshot job.
+CREATE TABLE risk_snapshots (
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    score INT NOT NULL,
+    level TEXT NOT NULL,
+    breakdown JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, snapshot_date)
+);
+
+CREATE INDEX idx_risk_snapshots_event_date ON risk_snapshots(event_id, snapshot_date);
+
+-- +goose Down
+DROP TABLE risk_snapshots;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Raised by the alert scan when a risk rule fires on an open task. At most one
+-- open alert per task per rule; resolved rows stay as the ledger.
+CREATE TABLE task_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    rule TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMP,
+    resolution TEXT CHECK (resolution IN ('touched', 'cleared'))
+);
+
+CREATE UNIQUE INDEX idx_task_alerts_open ON task_alerts(task_id, rule) WHERE resolved_at IS NULL;
+CREATE INDEX idx_task_alerts_event ON task_alerts(event_id, created_at);
+
+-- +goose Down
+DROP TABLE task_alerts;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- Per-person delivery settings. No row means email on, no webhook, UTC, no quiet hours.
+CREATE TABLE notification_prefs (
+    person_id UUID PRIMARY KEY REFERENCES people(id) ON DELETE CASCADE,
+    email_enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    webhook_url TEXT,
+    timezone TEXT NOT NULL DEFAULT 'UTC',
+    quiet_start INT CHECK (quiet_start BETWEEN 0 AND 23),
+    quiet_end INT CHECK (quiet_end BETWEEN 0 AND 23),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- One row per message per channel. The dispatcher retries pending rows with
+-- backoff until they are sent or run out of attempts.
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    person_id UUID NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    dedupe_key TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (person_id, channel, dedupe_key)
+);
+
+CREATE INDEX idx_notification_deliveries_due ON notification_deliveries(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_notification_deliveries_person ON notification_deliveries(person_id, created_at);
+
+-- +goose Down
+DROP TABLE notification_deliveries;
+DROP TABLE notification_prefs;
@@ -0,0 +1,9 @@
+-- +goose Up
+-- Local hour for the daily digest (NULL = no digest) and the channel it goes
+-- out on (NULL = every channel the person has on).
+ALTER TABLE notification_prefs ADD COLUMN digest_hour INT DEFAULT 8 CHECK (digest_hour BETWEEN 0 AND 23);
+ALTER TABLE notification_prefs ADD COLUMN digest_channel TEXT;
+
+-- +goose Down
+ALTER TABLE notification_prefs DROP COLUMN digest_channel;
+ALTER TABLE notification_prefs DROP COLUMN digest_hour;
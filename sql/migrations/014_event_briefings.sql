@@ -0,0 +1,18 @@
+-- +goose Up
+-- Generated event briefings, kept so past reports can be reread. report holds
+-- logic.Briefing as JSON; source is the provider name, or 'rules' for the
+-- deterministic fallback.
+CREATE TABLE event_briefings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event_id UUID NOT NULL REFERENCES events(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES people(id) ON DELETE SET NULL,
+    source TEXT NOT NULL,
+    days INT NOT NULL,
+    report JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_event_briefings_event ON event_briefings(event_id, created_at);
+
+-- +goose Down
+DROP TABLE event_briefings;
@@ -0,0 +1,15 @@
+-- +goose Up
+-- Bearer tokens for the JSON API. Only a SHA-256 of each token is stored; the
+-- token itself is returned once, when it's issued.
+CREATE TABLE api_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    person_id UUID NOT NULL REFERENCES people(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_api_tokens_person ON api_tokens(person_id);
+
+-- +goose Down
+DROP TABLE api_tokens;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Personal tokens: named, read-only or read-write, optionally limited to some
+-- events (empty = every event the person belongs to) and optionally never
+-- expiring. Revoked tokens are kept so their last use stays visible.
+ALTER TABLE api_tokens ALTER COLUMN expires_at DROP NOT NULL;
+ALTER TABLE api_tokens ADD COLUMN name TEXT NOT NULL DEFAULT 'API login';
+ALTER TABLE api_tokens ADD COLUMN scope TEXT NOT NULL DEFAULT 'read_write' CHECK (scope IN ('read', 'read_write'));
+ALTER TABLE api_tokens ADD COLUMN event_ids UUID[] NOT NULL DEFAULT '{}';
+ALTER TABLE api_tokens ADD COLUMN last_used_at TIMESTAMP;
+ALTER TABLE api_tokens ADD COLUMN revoked_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE api_tokens DROP COLUMN revoked_at;
+ALTER TABLE api_tokens DROP COLUMN last_used_at;
+ALTER TABLE api_tokens DROP COLUMN event_ids;
+ALTER TABLE api_tokens DROP COLUMN scope;
+ALTER TABLE api_tokens DROP COLUMN name;
+DELETE FROM api_tokens WHERE expires_at IS NULL;
+ALTER TABLE api_tokens ALTER COLUMN expires_at SET NOT NULL;
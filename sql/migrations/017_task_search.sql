@@ -0,0 +1,82 @@
+-- +goose Up
+-- Full-text search over tasks. Kept in its own table so tasks.* stays the
+-- same shape for every query. document weights title (A), description (B),
+-- subtask titles (C) and update notes (D); body is the plain text behind
+-- B-D, for ts_headline snippets. Triggers on both tables keep it current.
+CREATE TABLE task_search (
+    task_id UUID PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    document TSVECTOR NOT NULL,
+    body TEXT NOT NULL
+);
+
+CREATE INDEX idx_task_search_document ON task_search USING GIN (document);
+
+-- +goose StatementBegin
+CREATE FUNCTION refresh_task_search(p_task_id UUID) RETURNS VOID AS $$
+    WITH parts AS (
+        SELECT
+            t.id,
+            t.title,
+            COALESCE(t.description, '') AS description,
+            COALESCE((
+                SELECT string_agg(s->>'title', ' · ')
+                FROM jsonb_array_elements(CASE WHEN jsonb_typeof(t.subtasks) = 'array' THEN t.subtasks ELSE '[]'::jsonb END) s
+            ), '') AS subtasks,
+            COALESCE((
+                SELECT string_agg(u.note, ' · ' ORDER BY u.created_at)
+                FROM task_updates u
+                WHERE u.task_id = t.id
+            ), '') AS notes
+        FROM tasks t
+        WHERE t.id = p_task_id
+    )
+    INSERT INTO task_search (task_id, document, body)
+    SELECT
+        id,
+        setweight(to_tsvector('english', title), 'A') ||
+        setweight(to_tsvector('english', description), 'B') ||
+        setweight(to_tsvector('english', subtasks), 'C') ||
+        setweight(to_tsvector('english', notes), 'D'),
+        concat_ws(' · ', NULLIF(description, ''), NULLIF(subtasks, ''), NULLIF(notes, ''))
+    FROM parts
+    ON CONFLICT (task_id) DO UPDATE SET document = EXCLUDED.document, body = EXCLUDED.body;
+$$ LANGUAGE sql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION tasks_search_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_task_search(NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION task_updates_search_trigger() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM refresh_task_search(OLD.task_id);
+    ELSE
+        PERFORM refresh_task_search(NEW.task_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER tasks_search AFTER INSERT OR UPDATE OF title, description, subtasks ON tasks
+    FOR EACH ROW EXECUTE FUNCTION tasks_search_trigger();
+
+CREATE TRIGGER task_updates_search AFTER INSERT OR UPDATE OR DELETE ON task_updates
+    FOR EACH ROW EXECUTE FUNCTION task_updates_search_trigger();
+
+SELECT refresh_task_search(id) FROM tasks;
+
+-- +goose Down
+DROP TRIGGER task_updates_search ON task_updates;
+DROP TRIGGER tasks_search ON tasks;
+DROP FUNCTION task_updates_search_trigger();
+DROP FUNCTION tasks_search_trigger();
+DROP FUNCTION refresh_task_search(UUID);
+DROP TABLE task_search;
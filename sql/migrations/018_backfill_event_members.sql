@@ -0,0 +1,30 @@
+-- +goose Up
+-- Events created before memberships existed have no event_members rows, so
+-- nobody can see them. Give each such event's task owners editor access and
+-- make whoever owns the most of its tasks (earliest task breaks ties) the
+-- event owner. Events without any owned task still need a manual grant; see
+-- "Recovering orphaned events" in the README.
+WITH orphaned AS (
+    SELECT e.id FROM events e
+    WHERE NOT EXISTS (SELECT 1 FROM event_members em WHERE em.event_id = e.id)
+),
+owners AS (
+    SELECT t.event_id, t.owner_id, COUNT(*) AS tasks, MIN(t.created_at) AS first_task
+    FROM tasks t
+    JOIN orphaned o ON o.id = t.event_id
+    WHERE t.owner_id IS NOT NULL
+    GROUP BY t.event_id, t.owner_id
+),
+ranked AS (
+    SELECT event_id, owner_id,
+        ROW_NUMBER() OVER (PARTITION BY event_id ORDER BY tasks DESC, first_task, owner_id) AS rn
+    FROM owners
+)
+INSERT INTO event_members (event_id, person_id, role)
+SELECT event_id, owner_id, CASE WHEN rn = 1 THEN 'owner' ELSE 'editor' END
+FROM ranked
+ON CONFLICT (event_id, person_id) DO NOTHING;
+
+-- +goose Down
+-- The backfilled rows are indistinguishable from real memberships, so they stay.
+SELECT 1;
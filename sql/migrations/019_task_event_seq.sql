@@ -0,0 +1,11 @@
+-- +goose Up
+-- Events written in one transaction share NOW(), and their UUIDs are random,
+-- so created_at alone can't order them. seq records insertion order; rows
+-- that predate it are numbered in table order.
+ALTER TABLE task_events ADD COLUMN seq BIGSERIAL;
+
+CREATE INDEX idx_task_events_task_order ON task_events(task_id, created_at, seq);
+
+-- +goose Down
+DROP INDEX idx_task_events_task_order;
+ALTER TABLE task_events DROP COLUMN seq;
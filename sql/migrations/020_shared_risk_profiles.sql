@@ -0,0 +1,70 @@
+-- +goose Up
+-- Risk profiles become named and shareable: an event points at one, so the
+-- same weights can serve several events. Events pointing at none use the
+-- built-in defaults. Built-in rows are the presets every owner can pick.
+ALTER TABLE risk_profiles
+    ADD COLUMN name TEXT,
+    ADD COLUMN builtin BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN created_by UUID REFERENCES people(id) ON DELETE SET NULL;
+
+ALTER TABLE events ADD COLUMN risk_profile_id UUID REFERENCES risk_profiles(id) ON DELETE SET NULL;
+CREATE INDEX idx_events_risk_profile ON events(risk_profile_id);
+
+-- Each per-event profile keeps serving its event, named after it and owned
+-- by its earliest owner.
+UPDATE events e SET risk_profile_id = rp.id
+FROM risk_profiles rp
+WHERE rp.event_id = e.id;
+
+UPDATE risk_profiles rp SET
+    name = LEFT(e.name, 80),
+    created_by = (
+        SELECT em.person_id FROM event_members em
+        WHERE em.event_id = e.id AND em.role = 'owner'
+        ORDER BY em.created_at, em.person_id
+        LIMIT 1
+    )
+FROM events e
+WHERE e.id = rp.event_id;
+
+ALTER TABLE risk_profiles DROP COLUMN event_id;
+ALTER TABLE risk_profiles ALTER COLUMN name SET NOT NULL;
+ALTER TABLE risk_profiles ADD CONSTRAINT risk_profile_name_length CHECK (char_length(name) BETWEEN 1 AND 80);
+
+INSERT INTO risk_profiles (name, builtin) VALUES ('Default', TRUE);
+-- product.txt §4.1: one staleness tier at +20, blocked at +20.
+INSERT INTO risk_profiles (name, builtin, stale_short_points, stale_long_days, stale_long_points, blocked_points)
+VALUES ('Product spec', TRUE, 20, 7, 20, 20);
+
+-- +goose Down
+-- Back to one row per event: every event gets its own copy of the profile it
+-- used, and profiles no event uses are dropped.
+ALTER TABLE risk_profiles ADD COLUMN event_id UUID REFERENCES events(id) ON DELETE CASCADE;
+
+INSERT INTO risk_profiles (
+    event_id, name,
+    overdue_points, due_soon_points, due_soon_days,
+    stale_short_days, stale_short_points, stale_long_days, stale_long_points,
+    blocked_points, priority_weight,
+    blocks_weight, blocks_cap, overdue_upstream_points, critical_step_points, behind_schedule_points,
+    high_threshold, med_threshold, updated_at
+)
+SELECT
+    e.id, rp.name,
+    rp.overdue_points, rp.due_soon_points, rp.due_soon_days,
+    rp.stale_short_days, rp.stale_short_points, rp.stale_long_days, rp.stale_long_points,
+    rp.blocked_points, rp.priority_weight,
+    rp.blocks_weight, rp.blocks_cap, rp.overdue_upstream_points, rp.critical_step_points, rp.behind_schedule_points,
+    rp.high_threshold, rp.med_threshold, rp.updated_at
+FROM events e
+JOIN risk_profiles rp ON rp.id = e.risk_profile_id;
+
+ALTER TABLE events DROP COLUMN risk_profile_id;
+DELETE FROM risk_profiles WHERE event_id IS NULL;
+
+ALTER TABLE risk_profiles
+    ALTER COLUMN event_id SET NOT NULL,
+    ADD CONSTRAINT risk_profiles_event_id_key UNIQUE (event_id),
+    DROP COLUMN name,
+    DROP COLUMN builtin,
+    DROP COLUMN created_by;
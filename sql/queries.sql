@@ -25,10 +25,11 @@ ORDER BY
     CASE WHEN t.status = 'done' THEN 1 ELSE 0 END,
     t.due_date ASC NULLS LAST;
 
--- name: SoftDeleteTask :exec
+-- name: SoftDeleteTask :one
 UPDATE tasks 
 SET deleted_at = NOW() 
-WHERE id = $1;
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
 
 -- name: CreateTask :one
 INSERT INTO tasks (
@@ -41,6 +42,13 @@ RETURNING *;
 -- name: GetTask :one
 SELECT * FROM tasks WHERE id = $1 AND deleted_at IS NULL;
 
+-- name: GetTaskForUpdate :one
+SELECT * FROM tasks WHERE id = $1 AND deleted_at IS NULL
+FOR UPDATE;
+
+-- name: GetTaskIncludingDeleted :one
+SELECT * FROM tasks WHERE id = $1;
+
 -- name: UpdateTask :one
 UPDATE tasks
 SET 
@@ -58,11 +66,17 @@ WHERE id = sqlc.arg(id)
 RETURNING *;
 
 -- name: CreateTaskEvent :exec
-INSERT INTO task_events (task_id, event_type, changes)
-VALUES ($1, $2, $3);
+INSERT INTO task_events (task_id, event_type, changes, actor_id)
+VALUES ($1, $2, $3, $4);
 
 -- name: GetTaskEvents :many
-SELECT * FROM task_events WHERE task_id = $1 ORDER BY created_at DESC;
+SELECT 
+    te.*, 
+    p.name as actor_name 
+FROM task_events te
+LEFT JOIN people p ON te.actor_id = p.id
+WHERE te.task_id = $1 
+ORDER BY te.created_at DESC, te.seq DESC;
 
 -- name: ListPeople :many
 SELECT * FROM people ORDER BY name ASC;
@@ -92,30 +106,73 @@ SET
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
--- name: BatchSoftDeleteTasks :exec
+-- name: BatchSoftDeleteTasks :many
 UPDATE tasks 
 SET deleted_at = NOW() 
-WHERE id = ANY($1::uuid[]);
+WHERE id = ANY($1::uuid[]) AND deleted_at IS NULL
+RETURNING *;
 
 -- name: GetTasksForFollowUp :many
-SELECT * FROM tasks 
-WHERE status != 'done' 
-AND deleted_at IS NULL
-AND due_date IS NOT NULL 
-AND assignee_text IS NOT NULL 
-AND assignee_text != '';
+SELECT t.* FROM tasks t
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+WHERE t.status != 'done' 
+AND t.deleted_at IS NULL
+AND t.due_date IS NOT NULL 
+AND t.assignee_text IS NOT NULL 
+AND t.assignee_text != '';
 
--- name: GetGlobalActiveTasks :many
+-- name: ListTasks :many
 SELECT 
     t.*, 
     p.name as owner_name,
-    e.name as event_name
+    e.name as event_name,
+    e.event_date,
+    em.role as user_role
 FROM tasks t
 LEFT JOIN people p ON t.owner_id = p.id
 JOIN events e ON t.event_id = e.id
-WHERE t.status != 'done' 
-AND t.deleted_at IS NULL
-ORDER BY t.priority DESC, t.due_date ASC;
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = sqlc.arg(person_id)
+WHERE t.deleted_at IS NULL
+AND (sqlc.narg(event_id)::uuid IS NULL OR t.event_id = sqlc.narg(event_id))
+AND (cardinality(sqlc.arg(statuses)::text[]) = 0 OR t.status = ANY(sqlc.arg(statuses)::text[]))
+AND (sqlc.narg(category)::text IS NULL OR t.category = sqlc.narg(category))
+AND (sqlc.narg(owner_id)::uuid IS NULL OR t.owner_id = sqlc.narg(owner_id))
+AND (sqlc.arg(unassigned)::boolean = FALSE OR t.owner_id IS NULL)
+AND (cardinality(sqlc.arg(tags)::text[]) = 0 OR t.tags @> sqlc.arg(tags)::text[])
+AND (sqlc.narg(due_from)::date IS NULL OR t.due_date >= sqlc.narg(due_from))
+AND (sqlc.narg(due_to)::date IS NULL OR t.due_date <= sqlc.narg(due_to))
+AND (sqlc.arg(query)::text = ''
+    OR t.title ILIKE '%' || replace(replace(replace(sqlc.arg(query), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\'
+    OR t.description ILIKE '%' || replace(replace(replace(sqlc.arg(query), '\', '\\'), '%', '\%'), '_', '\_') || '%' ESCAPE '\')
+AND (sqlc.narg(after_id)::uuid IS NULL
+    OR (sqlc.arg(sort)::text = 'due' AND (COALESCE(t.due_date, '9999-12-31'), t.id) > (sqlc.narg(after_due)::date, sqlc.narg(after_id)))
+    OR (sqlc.arg(sort) = 'priority' AND (t.priority < sqlc.narg(after_priority)::int
+        OR (t.priority = sqlc.narg(after_priority) AND t.id > sqlc.narg(after_id))))
+    OR (sqlc.arg(sort) = 'updated' AND (COALESCE(t.last_update_at, t.created_at) < sqlc.narg(after_updated)::timestamp
+        OR (COALESCE(t.last_update_at, t.created_at) = sqlc.narg(after_updated) AND t.id > sqlc.narg(after_id)))))
+ORDER BY
+    CASE WHEN sqlc.arg(sort) = 'due' THEN COALESCE(t.due_date, '9999-12-31') END ASC,
+    CASE WHEN sqlc.arg(sort) = 'priority' THEN t.priority END DESC,
+    CASE WHEN sqlc.arg(sort) = 'updated' THEN COALESCE(t.last_update_at, t.created_at) END DESC,
+    t.id ASC
+LIMIT sqlc.narg(page_limit);
+
+-- name: ListTaskCategories :many
+SELECT DISTINCT t.category
+FROM tasks t
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = sqlc.arg(person_id)
+WHERE t.deleted_at IS NULL
+AND (sqlc.narg(event_id)::uuid IS NULL OR t.event_id = sqlc.narg(event_id))
+ORDER BY t.category;
+
+-- name: ListTaskOwners :many
+SELECT DISTINCT p.id, p.name
+FROM tasks t
+JOIN people p ON t.owner_id = p.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = sqlc.arg(person_id)
+WHERE t.deleted_at IS NULL
+AND (sqlc.narg(event_id)::uuid IS NULL OR t.event_id = sqlc.narg(event_id))
+ORDER BY p.name;
 
 -- name: GetPersonByEmail :one
 SELECT * FROM people WHERE email = $1;
@@ -144,4 +201,409 @@ ORDER BY e.event_date ASC;
 
 -- name: GetEventMembership :one
 SELECT role FROM event_members 
-WHERE event_id = $1 AND person_id = $2;
\ No newline at end of file
+WHERE event_id = $1 AND person_id = $2;
+
+-- name: ListEventMembers :many
+SELECT 
+    em.event_id, em.person_id, em.role, em.created_at,
+    p.name, p.email
+FROM event_members em
+JOIN people p ON em.person_id = p.id
+WHERE em.event_id = $1
+ORDER BY 
+    CASE em.role WHEN 'owner' THEN 0 WHEN 'editor' THEN 1 ELSE 2 END,
+    p.name ASC;
+
+-- name: UpdateEventMemberRole :exec
+UPDATE event_members 
+SET role = $3 
+WHERE event_id = $1 AND person_id = $2;
+
+-- name: RemoveEventMember :exec
+DELETE FROM event_members 
+WHERE event_id = $1 AND person_id = $2;
+
+-- name: LockEventOwners :many
+SELECT person_id FROM event_members
+WHERE event_id = $1 AND role = 'owner'
+FOR UPDATE;
+
+-- name: ListTaskEventIDs :many
+SELECT DISTINCT event_id FROM tasks 
+WHERE id = ANY($1::uuid[]);
+
+-- name: ListDeletedEventTasks :many
+SELECT 
+    t.*, 
+    p.name as deleted_by_name
+FROM tasks t
+LEFT JOIN LATERAL (
+    SELECT te.actor_id FROM task_events te
+    WHERE te.task_id = t.id AND te.event_type IN ('DELETED', 'BATCH_DELETED')
+    ORDER BY te.created_at DESC
+    LIMIT 1
+) del ON TRUE
+LEFT JOIN people p ON del.actor_id = p.id
+WHERE t.event_id = $1 
+AND t.deleted_at IS NOT NULL
+ORDER BY t.deleted_at DESC;
+
+-- name: RestoreTask :one
+UPDATE tasks 
+SET deleted_at = NULL 
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeDeletedTasks :execrows
+DELETE FROM tasks 
+WHERE deleted_at IS NOT NULL 
+AND deleted_at < NOW() - make_interval(days => $1::int);
+
+-- name: LockEvent :exec
+SELECT id FROM events WHERE id = $1 FOR UPDATE;
+
+-- name: AddTaskDependency :execrows
+INSERT INTO task_dependencies (task_id, dependency_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveTaskDependency :execrows
+DELETE FROM task_dependencies 
+WHERE task_id = $1 AND dependency_id = $2;
+
+-- name: ListTaskDependencies :many
+SELECT t.id, t.title, t.status, t.due_date
+FROM task_dependencies d
+JOIN tasks t ON d.dependency_id = t.id
+WHERE d.task_id = $1 
+AND t.deleted_at IS NULL
+ORDER BY t.title ASC;
+
+-- name: ListTaskDependents :many
+SELECT t.id, t.title, t.status, t.due_date
+FROM task_dependencies d
+JOIN tasks t ON d.task_id = t.id
+WHERE d.dependency_id = $1 
+AND t.deleted_at IS NULL
+ORDER BY t.title ASC;
+
+-- name: ListEventDependencies :many
+SELECT 
+    d.task_id, 
+    d.dependency_id,
+    dep.title as dependency_title,
+    dep.status as dependency_status
+FROM task_dependencies d
+JOIN tasks t ON d.task_id = t.id
+JOIN tasks dep ON d.dependency_id = dep.id
+WHERE t.event_id = $1 
+AND t.deleted_at IS NULL
+AND dep.deleted_at IS NULL;
+
+-- name: ListEventDependencyEdges :many
+SELECT 
+    d.task_id, 
+    d.dependency_id,
+    dep.title as dependency_title,
+    dep.status as dependency_status
+FROM task_dependencies d
+JOIN tasks t ON d.task_id = t.id
+JOIN tasks dep ON d.dependency_id = dep.id
+WHERE t.event_id = $1;
+
+-- name: GetEventRiskProfile :one
+SELECT rp.* FROM risk_profiles rp
+JOIN events e ON e.risk_profile_id = rp.id
+WHERE e.id = $1;
+
+-- name: GetRiskProfile :one
+SELECT * FROM risk_profiles WHERE id = $1;
+
+-- name: ListRiskProfilesForPerson :many
+SELECT rp.*, COUNT(e.id) as event_count
+FROM risk_profiles rp
+LEFT JOIN events e ON e.risk_profile_id = rp.id
+WHERE rp.builtin
+OR rp.created_by = $1
+OR rp.id IN (
+    SELECT ev.risk_profile_id FROM events ev
+    JOIN event_members em ON em.event_id = ev.id
+    WHERE em.person_id = $1 AND em.role = 'owner'
+)
+GROUP BY rp.id
+ORDER BY rp.builtin DESC, rp.name;
+
+-- name: ListRiskProfileEvents :many
+SELECT e.id, e.name, em.role as user_role
+FROM events e
+LEFT JOIN event_members em ON em.event_id = e.id AND em.person_id = $2
+WHERE e.risk_profile_id = $1
+ORDER BY e.event_date;
+
+-- name: CreateRiskProfile :one
+INSERT INTO risk_profiles (
+    name, created_by,
+    overdue_points, due_soon_points, due_soon_days,
+    stale_short_days, stale_short_points, stale_long_days, stale_long_points,
+    blocked_points, priority_weight,
+    blocks_weight, blocks_cap, overdue_upstream_points, critical_step_points, behind_schedule_points,
+    high_threshold, med_threshold
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+RETURNING *;
+
+-- name: UpdateRiskProfile :one
+UPDATE risk_profiles SET
+    name = $2,
+    overdue_points = $3,
+    due_soon_points = $4,
+    due_soon_days = $5,
+    stale_short_days = $6,
+    stale_short_points = $7,
+    stale_long_days = $8,
+    stale_long_points = $9,
+    blocked_points = $10,
+    priority_weight = $11,
+    blocks_weight = $12,
+    blocks_cap = $13,
+    overdue_upstream_points = $14,
+    critical_step_points = $15,
+    behind_schedule_points = $16,
+    high_threshold = $17,
+    med_threshold = $18,
+    updated_at = NOW()
+WHERE id = $1 AND NOT builtin
+RETURNING *;
+
+-- name: SetEventRiskProfile :exec
+UPDATE events SET risk_profile_id = $2 WHERE id = $1;
+
+-- name: UpsertRiskSnapshot :exec
+INSERT INTO risk_snapshots (task_id, event_id, snapshot_date, score, level, breakdown)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (task_id, snapshot_date) DO UPDATE SET
+    score = EXCLUDED.score,
+    level = EXCLUDED.level,
+    breakdown = EXCLUDED.breakdown;
+
+-- name: ListEventRiskSnapshots :many
+SELECT task_id, score, level FROM risk_snapshots 
+WHERE event_id = $1 AND snapshot_date = $2;
+
+-- name: GetEventRiskHistory :many
+SELECT 
+    snapshot_date,
+    SUM(score)::int as total_score,
+    COUNT(*) FILTER (WHERE level = 'high') as high_tasks,
+    COUNT(*) as tasks
+FROM risk_snapshots
+WHERE event_id = $1 
+AND snapshot_date >= $2
+GROUP BY snapshot_date
+ORDER BY snapshot_date ASC;
+
+-- name: ListMyTasks :many
+SELECT 
+    t.*, 
+    e.name as event_name,
+    em.role as user_role
+FROM tasks t
+JOIN events e ON t.event_id = e.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+WHERE t.deleted_at IS NULL
+AND t.status != 'done'
+AND (t.owner_id = $1 OR ($2::text != '' AND LOWER(t.assignee_text) = LOWER($2::text)))
+ORDER BY t.due_date ASC NULLS LAST, t.priority DESC;
+
+-- name: OpenTaskAlert :execrows
+INSERT INTO task_alerts (task_id, event_id, rule, message)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (task_id, rule) WHERE resolved_at IS NULL DO NOTHING;
+
+-- name: ListOpenTaskAlerts :many
+SELECT id, task_id, rule FROM task_alerts
+WHERE resolved_at IS NULL;
+
+-- name: ResolveTaskAlert :exec
+UPDATE task_alerts
+SET resolved_at = NOW(), resolution = $2
+WHERE id = $1 AND resolved_at IS NULL;
+
+-- name: ResolveTouchedTaskAlerts :exec
+UPDATE task_alerts
+SET resolved_at = NOW(), resolution = 'touched'
+WHERE task_id = $1 AND rule = ANY($2::text[]) AND resolved_at IS NULL;
+
+-- name: ListPersonAlerts :many
+SELECT 
+    a.id, a.task_id, a.event_id, a.rule, a.message, a.created_at, a.resolved_at, a.resolution,
+    t.title as task_title,
+    e.name as event_name
+FROM task_alerts a
+JOIN tasks t ON a.task_id = t.id
+JOIN events e ON a.event_id = e.id
+JOIN event_members em ON a.event_id = em.event_id AND em.person_id = $1
+WHERE (t.owner_id = $1 OR em.role = 'owner')
+AND (a.resolved_at IS NULL OR a.resolved_at > NOW() - INTERVAL '7 days')
+ORDER BY (a.resolved_at IS NULL) DESC, a.created_at DESC
+LIMIT 100;
+
+-- name: GetNotificationPrefs :one
+SELECT * FROM notification_prefs
+WHERE person_id = $1;
+
+-- name: UpsertNotificationPrefs :exec
+INSERT INTO notification_prefs (person_id, email_enabled, webhook_url, timezone, quiet_start, quiet_end, digest_hour, digest_channel)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (person_id) DO UPDATE SET
+    email_enabled = EXCLUDED.email_enabled,
+    webhook_url = EXCLUDED.webhook_url,
+    timezone = EXCLUDED.timezone,
+    quiet_start = EXCLUDED.quiet_start,
+    quiet_end = EXCLUDED.quiet_end,
+    digest_hour = EXCLUDED.digest_hour,
+    digest_channel = EXCLUDED.digest_channel,
+    updated_at = NOW();
+
+-- name: EnqueueDelivery :execrows
+INSERT INTO notification_deliveries (person_id, channel, kind, dedupe_key, payload)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (person_id, channel, dedupe_key) DO NOTHING;
+
+-- name: ListDueDeliveries :many
+SELECT 
+    d.id, d.person_id, d.channel, d.kind, d.dedupe_key, d.payload, d.status, d.attempts, d.last_error, d.next_attempt_at, d.sent_at, d.created_at,
+    p.name as person_name,
+    p.email as person_email,
+    np.webhook_url,
+    np.timezone,
+    np.quiet_start,
+    np.quiet_end
+FROM notification_deliveries d
+JOIN people p ON d.person_id = p.id
+LEFT JOIN notification_prefs np ON d.person_id = np.person_id
+WHERE d.status = 'pending'
+AND d.next_attempt_at <= NOW()
+ORDER BY d.next_attempt_at ASC
+LIMIT $1;
+
+-- name: MarkDeliverySent :exec
+UPDATE notification_deliveries
+SET status = 'sent', attempts = attempts + 1, last_error = NULL, sent_at = NOW()
+WHERE id = $1;
+
+-- name: MarkDeliveryFailed :exec
+UPDATE notification_deliveries
+SET status = $2, attempts = attempts + 1, last_error = $3, next_attempt_at = $4
+WHERE id = $1;
+
+-- name: DeferDelivery :exec
+UPDATE notification_deliveries
+SET next_attempt_at = $2
+WHERE id = $1;
+
+-- name: ListPersonDeliveries :many
+SELECT * FROM notification_deliveries
+WHERE person_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: ListOwnedTaskChanges :many
+SELECT 
+    te.task_id, te.event_type, te.changes, te.created_at,
+    t.title as task_title,
+    e.name as event_name,
+    p.name as actor_name
+FROM task_events te
+JOIN tasks t ON te.task_id = t.id
+JOIN events e ON t.event_id = e.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = $1
+LEFT JOIN people p ON te.actor_id = p.id
+WHERE t.owner_id = $1
+AND t.deleted_at IS NULL
+AND te.created_at >= $2
+AND (te.actor_id IS NULL OR te.actor_id != $1)
+ORDER BY te.created_at ASC, te.seq ASC;
+
+-- name: HasDelivery :one
+SELECT EXISTS (
+    SELECT 1 FROM notification_deliveries
+    WHERE person_id = $1 AND dedupe_key = $2
+);
+
+-- name: ListEventTaskChanges :many
+SELECT 
+    te.task_id, te.event_type, te.changes, te.created_at,
+    t.title as task_title,
+    p.name as actor_name
+FROM task_events te
+JOIN tasks t ON te.task_id = t.id
+LEFT JOIN people p ON te.actor_id = p.id
+WHERE t.event_id = $1
+AND te.created_at >= $2
+ORDER BY te.created_at ASC, te.seq ASC;
+
+-- name: CreateEventBriefing :one
+INSERT INTO event_briefings (event_id, created_by, source, days, report)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: ListEventBriefings :many
+SELECT 
+    b.id, b.source, b.days, b.created_at,
+    p.name as created_by_name
+FROM event_briefings b
+LEFT JOIN people p ON b.created_by = p.id
+WHERE b.event_id = $1
+ORDER BY b.created_at DESC
+LIMIT 20;
+
+-- name: GetEventBriefing :one
+SELECT 
+    b.id, b.event_id, b.created_by, b.source, b.days, b.report, b.created_at,
+    p.name as created_by_name
+FROM event_briefings b
+LEFT JOIN people p ON b.created_by = p.id
+WHERE b.id = $1;
+
+-- name: CreateApiToken :one
+INSERT INTO api_tokens (person_id, token_hash, name, scope, event_ids, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetApiTokenByHash :one
+SELECT * FROM api_tokens
+WHERE token_hash = $1
+AND revoked_at IS NULL
+AND (expires_at IS NULL OR expires_at > NOW());
+
+-- name: TouchApiToken :exec
+UPDATE api_tokens SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
+
+-- name: ListPersonApiTokens :many
+SELECT * FROM api_tokens
+WHERE person_id = $1
+ORDER BY revoked_at IS NOT NULL, created_at DESC;
+
+-- name: RevokeApiToken :execrows
+UPDATE api_tokens SET revoked_at = NOW()
+WHERE id = $1 AND person_id = $2 AND revoked_at IS NULL;
+
+-- name: SearchTasks :many
+SELECT
+    t.id, t.title, t.status, t.priority, t.category, t.due_date, t.event_id,
+    e.name as event_name,
+    ts_rank(s.document, query)::real as rank,
+    ts_headline('english', t.title, query, 'HighlightAll=true, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text as title_snippet,
+    ts_headline('english', s.body, query, 'MaxFragments=2, MaxWords=20, MinWords=8, StartSel=' || chr(2) || ', StopSel=' || chr(3))::text as body_snippet
+FROM task_search s
+JOIN tasks t ON t.id = s.task_id
+JOIN events e ON t.event_id = e.id
+JOIN event_members em ON t.event_id = em.event_id AND em.person_id = sqlc.arg(person_id),
+    websearch_to_tsquery('english', sqlc.arg(query)) query
+WHERE s.document @@ query
+AND t.deleted_at IS NULL
+AND (cardinality(sqlc.arg(event_ids)::uuid[]) = 0 OR t.event_id = ANY(sqlc.arg(event_ids)::uuid[]))
+ORDER BY rank DESC, t.id
+LIMIT sqlc.arg(page_limit);